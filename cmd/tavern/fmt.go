@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	yamlpkg "github.com/systemquest/tavern-go/pkg/yaml"
+)
+
+var fmtCheck bool
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt <test-file-or-dir>...",
+	Short: "Rewrite test files into the canonical layout",
+	Long: `Rewrite test files into the canonical layout: keys in a fixed order, two-space
+indentation and strings quoted only when needed. Comments, anchors and custom
+tags are kept. Directories are searched for *.tavern.yaml files.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runFmt,
+}
+
+func init() {
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "List files that are not formatted and fail instead of rewriting them")
+	rootCmd.AddCommand(fmtCmd)
+}
+
+func runFmt(cmd *cobra.Command, args []string) error {
+	filenames, err := testFiles(args)
+	if err != nil {
+		return err
+	}
+
+	var unformatted []string
+	for _, filename := range filenames {
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		formatted, err := yamlpkg.Format(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filename, err)
+		}
+		if bytes.Equal(data, formatted) {
+			continue
+		}
+
+		unformatted = append(unformatted, filename)
+		if fmtCheck {
+			fmt.Fprintln(cmd.OutOrStdout(), filename)
+			continue
+		}
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filename, formatted, info.Mode().Perm()); err != nil {
+			return err
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "formatted %s\n", filename)
+	}
+
+	if fmtCheck && len(unformatted) > 0 {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true // main prints the error
+		return fmt.Errorf("%d file(s) need formatting, run tavern fmt", len(unformatted))
+	}
+	return nil
+}
+
+// testFiles expands directories into the *.tavern.yaml files they contain
+func testFiles(args []string) ([]string, error) {
+	var filenames []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			filenames = append(filenames, arg)
+			continue
+		}
+		err = filepath.WalkDir(arg, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(d.Name(), ".tavern.yaml") {
+				filenames = append(filenames, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return filenames, nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/systemquest/tavern-go/pkg/generate"
+	"github.com/systemquest/tavern-go/pkg/openapi"
+)
+
+var (
+	genOutputDir string
+	genForce     bool
+)
+
+var genCmd = &cobra.Command{
+	Use:   "gen",
+	Short: "Generate test files from other formats",
+}
+
+var genOpenAPICmd = &cobra.Command{
+	Use:   "openapi <spec-file>",
+	Short: "Generate one test file per operation of an OpenAPI 3 document",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runGenOpenAPI,
+}
+
+func init() {
+	genCmd.PersistentFlags().StringVarP(&genOutputDir, "output", "o", ".", "Directory to write test files to")
+	genCmd.PersistentFlags().BoolVar(&genForce, "force", false, "Overwrite existing test files")
+	genCmd.AddCommand(genOpenAPICmd)
+	rootCmd.AddCommand(genCmd)
+}
+
+func runGenOpenAPI(cmd *cobra.Command, args []string) error {
+	spec, err := openapi.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	files, err := generate.FromOpenAPI(spec)
+	if err != nil {
+		return err
+	}
+
+	return writeGeneratedFiles(cmd, files)
+}
+
+// writeGeneratedFiles writes generated files to the output directory
+// Existing files are kept unless --force is set, so hand edits aren't lost
+func writeGeneratedFiles(cmd *cobra.Command, files []generate.File) error {
+	if err := os.MkdirAll(genOutputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	written := 0
+	for _, file := range files {
+		path := filepath.Join(genOutputDir, file.Name)
+		if _, err := os.Stat(path); err == nil && !genForce {
+			fmt.Fprintf(cmd.OutOrStdout(), "skipped %s (exists, use --force to overwrite)\n", path)
+			continue
+		}
+		if err := os.WriteFile(path, file.Content, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Fprintf(cmd.OutOrStdout(), "wrote %s\n", path)
+		written++
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "✓ Generated %d test file(s)\n", written)
+	return nil
+}
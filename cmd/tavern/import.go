@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/systemquest/tavern-go/pkg/generate"
+	"github.com/systemquest/tavern-go/pkg/postman"
+)
+
+var (
+	importEnvironment   string
+	importRejectDynamic bool
+)
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Convert test suites from other tools into tavern test files",
+}
+
+var importPostmanCmd = &cobra.Command{
+	Use:   "postman <collection.json>",
+	Short: "Convert a Postman collection into one test file per folder",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runImportPostman,
+}
+
+func init() {
+	importCmd.PersistentFlags().StringVarP(&genOutputDir, "output", "o", ".", "Directory to write test files to")
+	importCmd.PersistentFlags().BoolVar(&genForce, "force", false, "Overwrite existing test files")
+	importPostmanCmd.Flags().StringVarP(&importEnvironment, "environment", "e", "", "Postman environment export whose variables become an include")
+	importPostmanCmd.Flags().BoolVar(&importRejectDynamic, "reject-dynamic", false, "Fail on Postman dynamic variables such as {{$guid}} instead of leaving TODO comments")
+	importCmd.AddCommand(importPostmanCmd)
+	rootCmd.AddCommand(importCmd)
+}
+
+func runImportPostman(cmd *cobra.Command, args []string) error {
+	collection, err := postman.LoadCollection(args[0])
+	if err != nil {
+		return err
+	}
+
+	var env *postman.Environment
+	if importEnvironment != "" {
+		if env, err = postman.LoadEnvironment(importEnvironment); err != nil {
+			return err
+		}
+	}
+
+	if importRejectDynamic {
+		if uses := generate.PostmanDynamicVariables(collection, env); len(uses) > 0 {
+			return fmt.Errorf("Postman dynamic variables have no tavern equivalent; replace them with variables before importing:\n  - %s",
+				strings.Join(uses, "\n  - "))
+		}
+	}
+
+	files, err := generate.FromPostman(collection, env)
+	if err != nil {
+		return err
+	}
+
+	return writeGeneratedFiles(cmd, files)
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/systemquest/tavern-go/pkg/lint"
+	"github.com/systemquest/tavern-go/pkg/util"
+	yamlpkg "github.com/systemquest/tavern-go/pkg/yaml"
+)
+
+var (
+	lintGlobalCfgs []string
+	lintVars       []string
+	lintFormat     string
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint <test-file>...",
+	Short: "Statically check test files for undefined variables, missing includes and other mistakes",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runLint,
+}
+
+func init() {
+	lintCmd.Flags().StringSliceVarP(&lintGlobalCfgs, "global-cfg", "c", []string{}, "Global configuration files providing variables")
+	lintCmd.Flags().StringArrayVar(&lintVars, "var", []string{}, "Treat a variable as defined (name or name=value); repeatable")
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "Output format: text or json")
+	rootCmd.AddCommand(lintCmd)
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	if lintFormat != "text" && lintFormat != "json" {
+		return fmt.Errorf("unknown format '%s' (expected text or json)", lintFormat)
+	}
+
+	variables, err := globalVariableNames(lintGlobalCfgs, lintVars)
+	if err != nil {
+		return err
+	}
+
+	linter, err := lint.NewLinter(lint.Options{Variables: variables})
+	if err != nil {
+		return err
+	}
+
+	issues, err := linter.LintFiles(args)
+	if err != nil {
+		return err
+	}
+
+	if lintFormat == "json" {
+		if issues == nil {
+			issues = []lint.Issue{}
+		}
+		data, err := json.MarshalIndent(issues, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	} else {
+		for _, issue := range issues {
+			fmt.Fprintln(cmd.OutOrStdout(), issue.String())
+		}
+	}
+
+	if lint.HasErrors(issues) {
+		cmd.SilenceUsage = true
+		cmd.SilenceErrors = true // main prints the error
+		return fmt.Errorf("lint found %d issue(s)", len(issues))
+	}
+	return nil
+}
+
+// globalVariableNames returns the variable names provided by global configs and --var
+// Variables from every environment are included, since no environment is selected
+func globalVariableNames(globalCfgs, vars []string) ([]string, error) {
+	loader := yamlpkg.NewLoader(".")
+	merged := make(map[string]interface{})
+	for _, filename := range globalCfgs {
+		config, err := loader.LoadGlobalConfig(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load global config from %s: %w", filename, err)
+		}
+		merged = util.DeepMerge(merged, config)
+	}
+
+	var names []string
+	addConfig := func(config map[string]interface{}) {
+		if vars, ok := config["variables"].(map[string]interface{}); ok {
+			for name := range vars {
+				names = append(names, name)
+			}
+		}
+		if _, ok := config["base_url"]; ok {
+			names = append(names, "base_url")
+		}
+	}
+	addConfig(merged)
+	if environments, ok := merged["environments"].(map[string]interface{}); ok {
+		for _, env := range environments {
+			if envConfig, ok := env.(map[string]interface{}); ok {
+				addConfig(envConfig)
+			}
+		}
+	}
+
+	for _, v := range vars {
+		name := v
+		if key, _, err := util.ParseKeyValue(v); err == nil {
+			name = key
+		}
+		names = append(names, name)
+	}
+
+	return names, nil
+}
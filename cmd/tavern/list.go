@@ -0,0 +1,49 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/systemquest/tavern-go/pkg/collect"
+)
+
+var listFormat string
+
+var listCmd = &cobra.Command{
+	Use:   "list <test-file-or-dir>...",
+	Short: "List tests, their marks and stages with source positions, without running them",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runList,
+}
+
+func init() {
+	listCmd.Flags().StringVar(&listFormat, "format", "text", "Output format: text or json")
+	rootCmd.AddCommand(listCmd)
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	if listFormat != "text" && listFormat != "json" {
+		return fmt.Errorf("unknown format '%s' (expected text or json)", listFormat)
+	}
+
+	filenames, err := testFiles(args)
+	if err != nil {
+		return err
+	}
+	tests, err := collect.Files(filenames)
+	if err != nil {
+		return err
+	}
+
+	if listFormat == "json" {
+		data, err := json.MarshalIndent(tests, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return nil
+	}
+	collect.WriteText(cmd.OutOrStdout(), tests)
+	return nil
+}
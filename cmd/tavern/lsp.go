@@ -0,0 +1,39 @@
+package main
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/systemquest/tavern-go/pkg/lsp"
+)
+
+var (
+	lspGlobalCfgs []string
+	lspVars       []string
+)
+
+var lspCmd = &cobra.Command{
+	Use:   "lsp",
+	Short: "Run a Language Server Protocol server over stdio for editor completion and diagnostics",
+	Args:  cobra.NoArgs,
+	RunE:  runLSP,
+}
+
+func init() {
+	lspCmd.Flags().StringSliceVarP(&lspGlobalCfgs, "global-cfg", "c", []string{}, "Global configuration files providing variables")
+	lspCmd.Flags().StringArrayVar(&lspVars, "var", []string{}, "Treat a variable as defined (name or name=value); repeatable")
+	rootCmd.AddCommand(lspCmd)
+}
+
+func runLSP(cmd *cobra.Command, args []string) error {
+	variables, err := globalVariableNames(lspGlobalCfgs, lspVars)
+	if err != nil {
+		return err
+	}
+
+	server, err := lsp.NewServer(lsp.Options{Variables: variables})
+	if err != nil {
+		return err
+	}
+	return server.Run(os.Stdin, os.Stdout)
+}
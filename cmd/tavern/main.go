@@ -1,12 +1,15 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/systemquest/tavern-go/pkg/core"
+	"github.com/systemquest/tavern-go/pkg/coverage"
 	_ "github.com/systemquest/tavern-go/pkg/testutils" // Register extension functions
+	"github.com/systemquest/tavern-go/pkg/util"
 	"github.com/systemquest/tavern-go/pkg/version"
 )
 
@@ -17,11 +20,22 @@ var (
 	debug      bool
 	validate   bool
 	skipXfail  bool // Skip tests marked with _xfail (aligned with tavern-py commit 369a4bb)
+	reportFile string
+	noColor    bool
+	envName    string   // Named environment from the global config
+	varFlags   []string // --var key=value overrides
+	varsFiles  []string
+	envFiles   []string
+	openAPIDoc string // OpenAPI document to validate responses against
+
+	coverageFormat string  // Coverage report format: text or json
+	coverageOutput string  // Coverage report file, stdout if empty
+	coverageMin    float64 // Minimum operation coverage percentage
 )
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error: %v\n", util.MaskSecrets(err.Error()))
 		os.Exit(1)
 	}
 }
@@ -49,17 +63,32 @@ func init() {
 	rootCmd.Flags().BoolVarP(&debug, "debug", "d", false, "Debug mode")
 	rootCmd.Flags().BoolVar(&validate, "validate", false, "Validate test files without running")
 	rootCmd.Flags().BoolVar(&skipXfail, "skip-xfail", false, "Skip tests marked with _xfail (aligned with tavern-py commit 369a4bb)")
+	rootCmd.Flags().BoolVar(&noColor, "no-color", false, "Disable colored output")
+	rootCmd.Flags().StringVar(&envName, "env", "", "Select a named environment from the global config's environments section")
+	rootCmd.Flags().StringArrayVar(&varFlags, "var", []string{}, "Set a variable (key=value), overriding global configs and includes; repeatable")
+	rootCmd.Flags().StringArrayVar(&varsFiles, "vars-file", []string{}, "YAML file of variable overrides; --var takes precedence")
+	rootCmd.Flags().StringArrayVar(&envFiles, "env-file", []string{}, "Load a .env file into tavern.env_vars (real environment variables take precedence)")
+	rootCmd.Flags().StringVar(&openAPIDoc, "openapi", "", "Validate responses against this OpenAPI 3 document")
+	rootCmd.Flags().StringVar(&coverageFormat, "coverage", "", "Report OpenAPI coverage after the run: text or json (requires --openapi)")
+	rootCmd.Flags().StringVar(&coverageOutput, "coverage-output", "", "Write the coverage report to this file instead of stdout")
+	rootCmd.Flags().Float64Var(&coverageMin, "coverage-min", 0, "Fail the run if less than this percentage of operations is covered")
+	rootCmd.Flags().StringVar(&reportFile, "report", "", "Write structured JSON results (including HTTP timings) to this file")
 }
 
 func runTests(cmd *cobra.Command, args []string) error {
 	testFile := args[0]
 
+	if noColor {
+		util.SetColor(false)
+	}
+
 	// Create runner config
 	config := &core.Config{
-		BaseDir:   ".",
-		Verbose:   verbose,
-		Debug:     debug,
-		SkipXfail: skipXfail,
+		BaseDir:     ".",
+		Verbose:     verbose,
+		Debug:       debug,
+		SkipXfail:   skipXfail,
+		Environment: envName,
 	}
 
 	// Create runner
@@ -68,6 +97,10 @@ func runTests(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create runner: %w", err)
 	}
 
+	if envName != "" && len(globalCfgs) == 0 {
+		return fmt.Errorf("--env requires a global config (-c) that defines environments")
+	}
+
 	// Load global config if specified
 	if len(globalCfgs) > 0 {
 		if err := runner.LoadGlobalConfigs(globalCfgs); err != nil {
@@ -75,6 +108,24 @@ func runTests(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	// Load variable overrides: --vars-file first, then --var on top
+	if err := loadOverrides(runner); err != nil {
+		return err
+	}
+
+	if (coverageFormat != "" || coverageMin > 0) && openAPIDoc == "" {
+		return fmt.Errorf("--coverage and --coverage-min require an OpenAPI document (--openapi)")
+	}
+	if coverageFormat != "" && coverageFormat != "text" && coverageFormat != "json" {
+		return fmt.Errorf("unknown coverage format %q (expected text or json)", coverageFormat)
+	}
+
+	if openAPIDoc != "" {
+		if err := runner.LoadOpenAPI(openAPIDoc); err != nil {
+			return err
+		}
+	}
+
 	// Validate only mode
 	if validate {
 		if err := runner.ValidateFile(testFile); err != nil {
@@ -85,10 +136,100 @@ func runTests(cmd *cobra.Command, args []string) error {
 	}
 
 	// Run tests
-	if err := runner.RunFile(testFile); err != nil {
-		return fmt.Errorf("tests failed: %w", err)
+	runErr := runner.RunFile(testFile)
+
+	// Write the report even when tests fail - that is when it is most useful
+	if reportFile != "" {
+		if err := writeReport(reportFile, runner.Results()); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+	}
+
+	// Coverage is reported for failing runs too, but the threshold only applies to passing ones
+	var coverageReport *coverage.Report
+	if coverageFormat != "" || coverageMin > 0 {
+		coverageReport, err = runner.Coverage()
+		if err != nil {
+			return err
+		}
+		if err := writeCoverage(coverageReport); err != nil {
+			return fmt.Errorf("failed to write coverage report: %w", err)
+		}
+	}
+
+	if runErr != nil {
+		return fmt.Errorf("tests failed: %w", runErr)
+	}
+
+	if coverageReport != nil && coverageReport.Summary.Operations.Percent < coverageMin {
+		return fmt.Errorf("operation coverage %.1f%% is below the minimum of %.1f%%",
+			coverageReport.Summary.Operations.Percent, coverageMin)
 	}
 
 	fmt.Println("✓ All tests passed")
 	return nil
 }
+
+// loadOverrides applies --env-file, --vars-file and --var to the runner
+// Precedence (lowest to highest): global configs, includes, --vars-file, --var
+func loadOverrides(runner *core.Runner) error {
+	for _, filename := range envFiles {
+		if err := runner.LoadEnvFile(filename); err != nil {
+			return fmt.Errorf("failed to load env file: %w", err)
+		}
+	}
+
+	for _, filename := range varsFiles {
+		if err := runner.LoadVarsFile(filename); err != nil {
+			return err
+		}
+	}
+
+	for _, assignment := range varFlags {
+		key, value, err := util.ParseKeyValue(assignment)
+		if err != nil {
+			return err
+		}
+		runner.SetOverride(key, value)
+	}
+
+	return nil
+}
+
+// writeReport writes the test results as indented JSON
+func writeReport(filename string, results []*core.TestResult) error {
+	data, err := json.MarshalIndent(map[string]interface{}{
+		"tests": results,
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, 0644)
+}
+
+// writeCoverage writes the coverage report in the --coverage format
+func writeCoverage(report *coverage.Report) error {
+	if coverageFormat == "" {
+		return nil
+	}
+
+	out := os.Stdout
+	if coverageOutput != "" {
+		f, err := os.Create(coverageOutput)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	if coverageFormat == "json" {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(append(data, '\n'))
+		return err
+	}
+	return report.WriteText(out)
+}
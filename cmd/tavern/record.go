@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/systemquest/tavern-go/pkg/generate"
+	"github.com/systemquest/tavern-go/pkg/record"
+)
+
+var (
+	recordListen   string
+	recordUpstream string
+	recordOutput   string
+	recordName     string
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record traffic through a reverse proxy and write it as a test",
+	Long: `Record starts a reverse proxy to the upstream server. Every request/response
+pair sent through it becomes a stage. Values from JSON responses that are reused
+in later requests are saved and referenced as variables.
+
+Stop recording with Ctrl+C; the test file is written on exit.`,
+	Args: cobra.NoArgs,
+	RunE: runRecord,
+}
+
+func init() {
+	recordCmd.Flags().StringVar(&recordListen, "listen", ":8089", "Address for the proxy to listen on")
+	recordCmd.Flags().StringVar(&recordUpstream, "upstream", "", "Base URL of the server to record (required)")
+	recordCmd.Flags().StringVarP(&recordOutput, "output", "o", "recorded.tavern.yaml", "Test file to write")
+	recordCmd.Flags().StringVar(&recordName, "name", "Recorded session", "test_name of the written test")
+	_ = recordCmd.MarkFlagRequired("upstream")
+	rootCmd.AddCommand(recordCmd)
+}
+
+func runRecord(cmd *cobra.Command, args []string) error {
+	recorder, err := record.NewRecorder(recordUpstream)
+	if err != nil {
+		return err
+	}
+	out := cmd.OutOrStdout()
+	recorder.OnExchange = func(ex record.Exchange) {
+		fmt.Fprintf(out, "%s %s -> %d\n", ex.Method, ex.Path, ex.Status)
+	}
+
+	server := &http.Server{Addr: recordListen, Handler: recorder}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+	fmt.Fprintf(out, "Recording %s on %s (Ctrl+C to stop)\n", recordUpstream, recordListen)
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("proxy failed: %w", err)
+		}
+	case <-ctx.Done():
+		_ = server.Shutdown(context.Background())
+	}
+
+	exchanges := recorder.Exchanges()
+	if len(exchanges) == 0 {
+		fmt.Fprintln(out, "No requests recorded, nothing written")
+		return nil
+	}
+
+	content, err := generate.FromExchanges(recordName, recordUpstream, exchanges)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(recordOutput, content, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", recordOutput, err)
+	}
+	fmt.Fprintf(out, "✓ Wrote %d stage(s) to %s\n", len(exchanges), recordOutput)
+	return nil
+}
@@ -0,0 +1,110 @@
+// Package collect lists the tests and stages of test files without running them
+package collect
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	yamlpkg "github.com/systemquest/tavern-go/pkg/yaml"
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// Test is a collected test
+type Test struct {
+	Name   string   `json:"name"`
+	File   string   `json:"file"`
+	Line   int      `json:"line"` // Line of test_name
+	Marks  []string `json:"marks"`
+	Xfail  string   `json:"xfail,omitempty"`
+	Stages []Stage  `json:"stages"`
+}
+
+// Stage is a collected stage
+type Stage struct {
+	Name string `json:"name"`
+	Line int    `json:"line"`
+	Skip bool   `json:"skip,omitempty"`
+	Only bool   `json:"only,omitempty"`
+}
+
+// Files collects the tests of the given files in order. A document that is not
+// a valid test is an error, since it would fail when run.
+func Files(filenames []string) ([]Test, error) {
+	tests := []Test{}
+	for _, filename := range filenames {
+		documents, err := yamlpkg.NewLoader(".").LoadDocuments(filename)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+
+		for _, doc := range documents {
+			if doc.Err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", filename, doc.Node.Line, doc.Err)
+			}
+
+			test := Test{
+				Name:   doc.Test.TestName,
+				File:   filename,
+				Line:   keyLine(doc.Node, "test_name"),
+				Marks:  doc.Test.Marks,
+				Xfail:  doc.Test.Xfail,
+				Stages: make([]Stage, len(doc.Test.Stages)),
+			}
+			if test.Marks == nil {
+				test.Marks = []string{}
+			}
+
+			// Stage nodes and decoded stages correspond one to one
+			stageNodes := yamlpkg.MappingValue(doc.Node, "stages")
+			for i, stage := range doc.Test.Stages {
+				test.Stages[i] = Stage{Name: stage.Name, Skip: stage.Skip, Only: stage.Only}
+				if stageNodes != nil && i < len(stageNodes.Content) {
+					test.Stages[i].Line = stageNodes.Content[i].Line
+				}
+			}
+			tests = append(tests, test)
+		}
+	}
+	return tests, nil
+}
+
+// WriteText writes one line per test and stage, prefixed with file:line
+func WriteText(w io.Writer, tests []Test) {
+	stages := 0
+	for _, test := range tests {
+		line := fmt.Sprintf("%s:%d: %s", test.File, test.Line, test.Name)
+		if len(test.Marks) > 0 {
+			line += " [" + strings.Join(test.Marks, ", ") + "]"
+		}
+		if test.Xfail != "" {
+			line += " (xfail: " + test.Xfail + ")"
+		}
+		fmt.Fprintln(w, line)
+
+		for _, stage := range test.Stages {
+			line := fmt.Sprintf("%s:%d:   %s", test.File, stage.Line, stage.Name)
+			if stage.Skip {
+				line += " (skip)"
+			}
+			if stage.Only {
+				line += " (only)"
+			}
+			fmt.Fprintln(w, line)
+		}
+		stages += len(test.Stages)
+	}
+	fmt.Fprintf(w, "\n%d test(s), %d stage(s)\n", len(tests), stages)
+}
+
+// keyLine returns the line of a key in a mapping node, or of the node if the key is missing
+func keyLine(node *goyaml.Node, key string) int {
+	if node.Kind == goyaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i].Line
+			}
+		}
+	}
+	return node.Line
+}
@@ -0,0 +1,85 @@
+package collect
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testFile = `---
+test_name: Login
+marks:
+  - smoke
+  - auth
+
+stages:
+  - &login
+    name: login
+    request:
+      url: http://localhost/login
+    response:
+      status_code: 200
+
+  - name: profile
+    skip: true
+    request:
+      url: http://localhost/me
+---
+test_name: Reuse
+_xfail: run
+stages:
+  - *login
+`
+
+// TestFiles tests that tests, marks and stages are collected with their lines
+func TestFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test_login.tavern.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(testFile), 0644))
+
+	tests, err := Files([]string{path})
+	require.NoError(t, err)
+
+	assert.Equal(t, []Test{
+		{
+			Name:  "Login",
+			File:  path,
+			Line:  2,
+			Marks: []string{"smoke", "auth"},
+			Stages: []Stage{
+				{Name: "login", Line: 8},
+				{Name: "profile", Line: 15, Skip: true},
+			},
+		},
+		{
+			Name:   "Reuse",
+			File:   path,
+			Line:   20,
+			Marks:  []string{},
+			Xfail:  "run",
+			Stages: []Stage{{Name: "login", Line: 23}},
+		},
+	}, tests)
+
+	var buf bytes.Buffer
+	WriteText(&buf, tests)
+	assert.Equal(t, path+":2: Login [smoke, auth]\n"+
+		path+":8:   login\n"+
+		path+":15:   profile (skip)\n"+
+		path+":20: Reuse (xfail: run)\n"+
+		path+":23:   login\n"+
+		"\n2 test(s), 3 stage(s)\n", buf.String())
+}
+
+// TestFiles_InvalidTest tests that a document that doesn't decode as a test is an error
+func TestFiles_InvalidTest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test_invalid.tavern.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("test_name: Invalid\nstages: not-a-list\n"), 0644))
+
+	_, err := Files([]string{path})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), path+":1:")
+}
@@ -0,0 +1,174 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunner_CommandStages tests command stages between REST stages, passing saved variables both ways
+func TestRunner_CommandStages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/items":
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"id": "item-1"}`))
+		case r.URL.Path == "/items/item-1/exports/e-42":
+			_, _ = w.Write([]byte(`{"status": "done"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	testPath := filepath.Join(tmpDir, "test_command.tavern.yaml")
+	require.NoError(t, os.WriteFile(testPath, []byte(`
+test_name: CLI and API
+stages:
+  - name: create item
+    request:
+      url: "{host}/items"
+      method: POST
+    response:
+      status_code: 201
+      save:
+        body:
+          item_id: id
+
+  - name: export with the CLI
+    command:
+      argv: [sh, -c, 'echo "exporting $ITEM"; read format; echo "export=e-42 format=$format"; echo done >&2']
+      stdin: "json\n"
+      env:
+        ITEM: "{item_id}"
+      timeout: 5
+    command_response:
+      stdout:
+        contains: "exporting {item_id}"
+        matches: "export=e-[0-9]+ format=json"
+      stderr:
+        equals: done
+      save:
+        stdout:
+          export_id: "export=(\\S+)"
+
+  - name: export finished
+    request:
+      url: "{host}/items/{item_id}/exports/{export_id}"
+    response:
+      status_code: 200
+      body:
+        status: done
+
+  - name: usage error
+    command:
+      argv: [sh, -c, "exit 2"]
+    command_response:
+      exit_code: [1, 2]
+`), 0644))
+
+	runner, err := NewRunner(&Config{BaseDir: tmpDir})
+	require.NoError(t, err)
+	runner.SetVariable("host", server.URL)
+
+	require.NoError(t, runner.RunFile(testPath))
+}
+
+// TestRunner_CommandJSON tests json assertions on stdout with tags and strict mode, and saving by path
+func TestRunner_CommandJSON(t *testing.T) {
+	tmpDir := t.TempDir()
+	testPath := filepath.Join(tmpDir, "test_command_json.tavern.yaml")
+	require.NoError(t, os.WriteFile(testPath, []byte(`
+test_name: CLI JSON output
+strict:
+  - stdout
+stages:
+  - name: status
+    command:
+      argv: [cat, status.json]
+      dir: "{dir}"
+    command_response:
+      stdout:
+        json:
+          name: "{service}"
+          replicas: !anyint
+          load: !approx 0.5
+          pods:
+            - id: !anystr
+      save:
+        json:
+          pod: pods.0.id
+
+  - name: logs
+    command:
+      argv: [echo, "{pod}"]
+    command_response:
+      stdout:
+        equals: api-1
+`), 0644))
+
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "status.json"),
+		[]byte(`{"name": "api", "replicas": 3, "load": 0.5000001, "pods": [{"id": "api-1"}]}`), 0644))
+
+	runner, err := NewRunner(&Config{BaseDir: tmpDir})
+	require.NoError(t, err)
+	runner.SetVariable("service", "api")
+	runner.SetVariable("dir", tmpDir)
+	require.NoError(t, runner.RunFile(testPath))
+
+	// Strict stdout rejects keys that aren't expected
+	content, err := os.ReadFile(testPath)
+	require.NoError(t, err)
+	strictPath := filepath.Join(tmpDir, "test_command_strict.tavern.yaml")
+	require.NoError(t, os.WriteFile(strictPath, []byte(strings.Replace(string(content), "          load: !approx 0.5\n", "", 1)), 0644))
+	err = runner.RunFile(strictPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "load")
+}
+
+// TestRunner_CommandFailures tests failing commands and invalid command stages
+func TestRunner_CommandFailures(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := map[string]struct {
+		stages string
+		err    string
+	}{
+		"exit code": {`
+  - name: fail
+    command:
+      argv: [sh, -c, "echo boom >&2; exit 1"]
+`, "exit code mismatch: expected 0, got 1: boom"},
+		"timeout": {`
+  - name: slow
+    command:
+      argv: [sleep, "5"]
+      timeout: 0.2
+`, "sleep timed out after 200ms"},
+		"no argv": {`
+  - name: empty
+    command:
+      argv: []
+`, "stages.0.command.argv"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testPath := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(name, " ", "_")+".tavern.yaml")
+			require.NoError(t, os.WriteFile(testPath, []byte("test_name: "+name+"\nstages:"+tt.stages), 0644))
+
+			runner, err := NewRunner(&Config{BaseDir: tmpDir})
+			require.NoError(t, err)
+			err = runner.RunFile(testPath)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
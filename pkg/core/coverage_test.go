@@ -0,0 +1,72 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+)
+
+// TestRunner_Coverage tests that coverage is built from the request_vars of each stage
+func TestRunner_Coverage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	specFile := filepath.Join(t.TempDir(), "openapi.yaml")
+	require.NoError(t, os.WriteFile(specFile, []byte(`
+openapi: 3.0.3
+info: {title: Test, version: "1"}
+paths:
+  /ok:
+    get:
+      parameters:
+        - {name: q, in: query, schema: {type: string}}
+      responses:
+        "200": {description: ok}
+  /missing:
+    get:
+      responses:
+        "200": {description: ok}
+        "404": {description: not found}
+`), 0644))
+
+	runner, err := NewRunner(&Config{})
+	require.NoError(t, err)
+
+	_, err = runner.Coverage()
+	assert.ErrorContains(t, err, "--openapi")
+
+	require.NoError(t, runner.LoadOpenAPI(specFile))
+	require.NoError(t, runner.RunTest(&schema.TestSpec{
+		TestName: "coverage",
+		Stages: []schema.Stage{
+			{
+				Name:     "ok",
+				Request:  &schema.RequestSpec{URL: server.URL + "/ok", Params: map[string]string{"q": "x"}},
+				Response: &schema.ResponseSpec{},
+			},
+			{
+				Name:     "missing",
+				Request:  &schema.RequestSpec{URL: server.URL + "/missing"},
+				Response: &schema.ResponseSpec{StatusCode: &schema.StatusCode{Single: 404}},
+			},
+		},
+	}))
+
+	report, err := runner.Coverage()
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Summary.Operations.Covered)
+	assert.Equal(t, 2, report.Summary.Statuses.Covered)
+	assert.Equal(t, 3, report.Summary.Statuses.Total)
+	assert.Equal(t, 1, report.Summary.Parameters.Covered)
+	assert.Empty(t, report.Unmatched)
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/request"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// selectEnvironment deep merges the named entry of the global config's
+// environments section over the rest of the config
+// The environments section itself is always removed from the result
+func selectEnvironment(config map[string]interface{}, name string) (map[string]interface{}, error) {
+	environments, hasEnvironments := config["environments"]
+
+	base := make(map[string]interface{}, len(config))
+	for k, v := range config {
+		if k != "environments" {
+			base[k] = v
+		}
+	}
+
+	if name == "" {
+		return base, nil
+	}
+
+	envMap, ok := environments.(map[string]interface{})
+	if !hasEnvironments || !ok {
+		return nil, fmt.Errorf("environment '%s' selected but no environments are defined in the global config", name)
+	}
+
+	env, ok := envMap[name]
+	if !ok {
+		names := make([]string, 0, len(envMap))
+		for n := range envMap {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unknown environment '%s' (available: %s)", name, strings.Join(names, ", "))
+	}
+
+	if env == nil {
+		return base, nil
+	}
+	overlay, ok := env.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("environment '%s' must be a mapping", name)
+	}
+
+	return util.DeepMerge(base, overlay), nil
+}
+
+// decodeSection converts a section of the global config into a typed spec
+func decodeSection(section interface{}, out interface{}) error {
+	data, err := json.Marshal(section)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// configureHTTPClient applies the global config's base_url, tls and auth settings
+// Test variables are used to format base_url and auth values
+func (r *Runner) configureHTTPClient(client *http.Client, testConfig *request.Config) error {
+	if tlsSection, ok := r.config.GlobalConfig["tls"]; ok && tlsSection != nil {
+		var tlsSpec schema.TLSSpec
+		if err := decodeSection(tlsSection, &tlsSpec); err != nil {
+			return fmt.Errorf("invalid tls config: %w", err)
+		}
+		tlsConfig, err := request.NewTLSConfig(&tlsSpec)
+		if err != nil {
+			return err
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		client.Transport = transport
+	}
+
+	// The {base_url} variable is used so --var base_url=... also moves relative URLs
+	if baseURL, ok := r.config.GlobalConfig["base_url"]; ok && baseURL != nil {
+		formatted, err := util.FormatKeys(testConfig.Variables["base_url"], testConfig.Variables)
+		if err != nil {
+			return fmt.Errorf("failed to format base_url: %w", err)
+		}
+		testConfig.BaseURL = fmt.Sprintf("%v", formatted)
+	}
+
+	if authSection, ok := r.config.GlobalConfig["auth"]; ok && authSection != nil {
+		formatted, err := util.FormatKeys(authSection, testConfig.Variables)
+		if err != nil {
+			return fmt.Errorf("failed to format auth: %w", err)
+		}
+		var auth schema.AuthSpec
+		if err := decodeSection(formatted, &auth); err != nil {
+			return fmt.Errorf("invalid auth config: %w", err)
+		}
+		testConfig.DefaultAuth = &auth
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package core
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+)
+
+const environmentsConfig = `
+base_url: http://default.invalid
+variables:
+  user: default
+  timeout: 30
+environments:
+  dev:
+    variables:
+      user: dev
+  staging:
+    base_url: http://staging.invalid
+    variables:
+      user: staging
+`
+
+// TestSelectEnvironment tests that the selected environment is deep merged over the base config
+func TestSelectEnvironment(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "global.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(environmentsConfig), 0644))
+
+	runner, err := NewRunner(&Config{Environment: "staging"})
+	require.NoError(t, err)
+	require.NoError(t, runner.LoadGlobalConfigs([]string{configPath}))
+
+	assert.Equal(t, "staging", runner.config.Variables["user"])
+	assert.Equal(t, 30, runner.config.Variables["timeout"], "Base variables should be kept")
+	assert.Equal(t, "http://staging.invalid", runner.config.Variables["base_url"])
+	assert.NotContains(t, runner.config.GlobalConfig, "environments")
+
+	// Without --env the base config is used as-is
+	runner, err = NewRunner(&Config{})
+	require.NoError(t, err)
+	require.NoError(t, runner.LoadGlobalConfigs([]string{configPath}))
+	assert.Equal(t, "default", runner.config.Variables["user"])
+	assert.Equal(t, "http://default.invalid", runner.config.Variables["base_url"])
+}
+
+// TestSelectEnvironment_Unknown tests that an unknown environment lists the available ones
+func TestSelectEnvironment_Unknown(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "global.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(environmentsConfig), 0644))
+
+	runner, err := NewRunner(&Config{Environment: "prod"})
+	require.NoError(t, err)
+
+	err = runner.LoadGlobalConfigs([]string{configPath})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unknown environment 'prod' (available: dev, staging)")
+}
+
+// TestEnvironment_BaseURLTLSAndAuth tests that an environment's base_url, tls and auth
+// settings are applied to requests
+func TestEnvironment_BaseURLTLSAndAuth(t *testing.T) {
+	var gotPath, gotAuth string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	caPath := filepath.Join(tmpDir, "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caPath, caPEM, 0644))
+
+	t.Setenv("TAVERN_TEST_TOKEN", "secret-token")
+	configPath := filepath.Join(tmpDir, "global.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+environments:
+  local:
+    base_url: `+server.URL+`/api
+    tls:
+      ca_cert: `+caPath+`
+    auth:
+      type: bearer
+      token: "{tavern.env_vars.TAVERN_TEST_TOKEN}"
+`), 0644))
+
+	runner, err := NewRunner(&Config{Environment: "local"})
+	require.NoError(t, err)
+	require.NoError(t, runner.LoadGlobalConfigs([]string{configPath}))
+
+	testSpec := &schema.TestSpec{
+		TestName: "Environment",
+		Stages: []schema.Stage{
+			{
+				Name:     "relative url",
+				Request:  &schema.RequestSpec{URL: "/users"},
+				Response: &schema.ResponseSpec{StatusCode: &schema.StatusCode{Single: 200}},
+			},
+		},
+	}
+
+	require.NoError(t, runner.RunTest(testSpec))
+	assert.Equal(t, "/api/users", gotPath)
+	assert.Equal(t, "Bearer secret-token", gotAuth)
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunner_EventStream tests a stage reading server-sent events from an endpoint that never closes the stream
+func TestRunner_EventStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/complete" {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		flusher := w.(http.Flusher)
+		for i, token := range []string{"Hel", "lo"} {
+			fmt.Fprintf(w, "event: token\nid: %d\ndata: {\"text\": %q}\n\n", i+1, token)
+			flusher.Flush()
+		}
+		fmt.Fprint(w, "event: done\ndata: {\"completion_id\": \"c-1\"}\n\n")
+		flusher.Flush()
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	testPath := filepath.Join(tmpDir, "test_events.tavern.yaml")
+	require.NoError(t, os.WriteFile(testPath, []byte(`
+test_name: Streamed completion
+stages:
+  - name: complete
+    request:
+      url: "{host}/complete"
+      method: POST
+      json:
+        prompt: hi
+    response:
+      status_code: 200
+      events:
+        until:
+          event: done
+          save:
+            data:
+              completion_id: completion_id
+        expect:
+          - event: token
+            data:
+              text: Hel
+  - name: saved
+    request:
+      url: "{host}/completions/{completion_id}"
+    response:
+      status_code: 200
+`), 0644))
+
+	runner, err := NewRunner(&Config{BaseDir: tmpDir})
+	require.NoError(t, err)
+	runner.SetVariable("host", server.URL)
+
+	start := time.Now()
+	require.NoError(t, runner.RunFile(testPath))
+	assert.Less(t, time.Since(start), 5*time.Second)
+}
@@ -0,0 +1,104 @@
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunner_ExternalValues tests that !file and !env values are resolved when the stage runs
+func TestRunner_ExternalValues(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"host": r.Header.Get("X-Host")})
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "user.json"), []byte(`{"name": "alice", "age": 30}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "raw.bin"), []byte("raw {not formatted}\x00"), 0644))
+
+	testPath := filepath.Join(tmpDir, "test_external.tavern.yaml")
+	require.NoError(t, os.WriteFile(testPath, []byte(`
+test_name: External values
+stages:
+  - name: json from file
+    request:
+      url: `+server.URL+`
+      method: POST
+      json:
+        user: !file {path: user.json, format: json}
+      headers:
+        X-Host: !env TAVERN_EXTERNAL_HOST
+    response:
+      status_code: 200
+      body:
+        host: !env TAVERN_EXTERNAL_HOST
+  - name: raw data from file
+    request:
+      url: `+server.URL+`
+      method: POST
+      data: !file raw.bin
+    response:
+      status_code: 200
+`), 0644))
+
+	runner, err := NewRunner(&Config{BaseDir: tmpDir})
+	require.NoError(t, err)
+	t.Setenv("TAVERN_EXTERNAL_HOST", "example.test")
+
+	require.NoError(t, runner.RunFile(testPath))
+	require.Len(t, bodies, 2)
+	assert.JSONEq(t, `{"user": {"name": "alice", "age": 30}}`, bodies[0])
+	assert.Equal(t, "raw {not formatted}\x00", bodies[1])
+}
+
+// TestRunner_ExternalGlobalVariables tests that !env and !file values in global
+// config variables are resolved, also when used inside a longer string
+func TestRunner_ExternalGlobalVariables(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"auth": r.Header.Get("Authorization")})
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "client.txt"), []byte("cli"), 0644))
+	globalPath := filepath.Join(tmpDir, "global.yaml")
+	require.NoError(t, os.WriteFile(globalPath, []byte(`
+variables:
+  token: !env {name: TAVERN_GLOBAL_TOKEN, default: deftok}
+  client: !file client.txt
+`), 0644))
+
+	testPath := filepath.Join(tmpDir, "test_global_external.tavern.yaml")
+	require.NoError(t, os.WriteFile(testPath, []byte(`
+test_name: External global variables
+stages:
+  - name: token in header
+    request:
+      url: `+server.URL+`
+      headers:
+        Authorization: "Bearer {token} ({client})"
+    response:
+      status_code: 200
+      body:
+        auth: "Bearer deftok (cli)"
+`), 0644))
+
+	runner, err := NewRunner(&Config{BaseDir: tmpDir})
+	require.NoError(t, err)
+	require.NoError(t, runner.LoadGlobalConfig(globalPath))
+
+	require.NoError(t, runner.RunFile(testPath))
+}
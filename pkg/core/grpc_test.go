@@ -0,0 +1,75 @@
+package core
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunner_GRPCStage tests that grpc_request stages are dispatched and their status checked.
+// Nothing listens on the address, so calls end with UNAVAILABLE.
+func TestRunner_GRPCStage(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	importPath, err := filepath.Abs("../request/testdata")
+	require.NoError(t, err)
+
+	tmpDir := t.TempDir()
+	writeTest := func(name, status string) string {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte(`
+test_name: gRPC `+status+`
+stages:
+  - name: say hello
+    grpc_request:
+      host: "{grpc_host}"
+      service: tavern.test.Greeter/SayHello
+      proto_files: [greeter.proto]
+      import_paths: [`+importPath+`]
+      body:
+        name: alice
+      timeout: 5
+    grpc_response:
+      status: `+status+`
+`), 0644))
+		return path
+	}
+
+	runner, err := NewRunner(&Config{BaseDir: tmpDir})
+	require.NoError(t, err)
+	runner.SetVariable("grpc_host", addr)
+
+	require.NoError(t, runner.RunFile(writeTest("test_unavailable.tavern.yaml", "UNAVAILABLE")))
+
+	err = runner.RunFile(writeTest("test_ok.tavern.yaml", "OK"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status: expected OK, got UNAVAILABLE")
+}
+
+// TestRunner_GRPCStageSchema tests that a stage needs exactly one protocol's request and response
+func TestRunner_GRPCStageSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	testPath := filepath.Join(tmpDir, "test_grpc_schema.tavern.yaml")
+	require.NoError(t, os.WriteFile(testPath, []byte(`
+test_name: gRPC without response
+stages:
+  - name: say hello
+    grpc_request:
+      host: localhost:50051
+      service: tavern.test.Greeter/SayHello
+`), 0644))
+
+	runner, err := NewRunner(&Config{BaseDir: tmpDir})
+	require.NoError(t, err)
+
+	err = runner.RunFile(testPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "grpc_response is required")
+}
@@ -0,0 +1,151 @@
+package core
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/mochi-mqtt/server/v2/packets"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startDevice starts an embedded MQTT broker with a simulated device that
+// answers {"command": "ping"} on devices/d1/commands with a status message, and
+// an HTTP API whose POST /devices/d1/reboot makes the device report "rebooting".
+// It returns the broker and API URLs.
+func startDevice(t *testing.T) (string, string) {
+	t.Helper()
+	broker := mochi.New(&mochi.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	require.NoError(t, broker.AddHook(new(auth.AllowHook), nil))
+	tcp := listeners.NewTCP(listeners.Config{ID: "tcp", Address: "127.0.0.1:0"})
+	require.NoError(t, broker.AddListener(tcp))
+	go func() { _ = broker.Serve() }()
+	t.Cleanup(func() { _ = broker.Close() })
+
+	status := func(state string) {
+		payload, _ := json.Marshal(map[string]interface{}{"state": state, "uptime": 42})
+		go func() { _ = broker.Publish("devices/d1/status", payload, false, 1) }()
+	}
+	require.NoError(t, broker.Subscribe("devices/d1/commands", 1, func(_ *mochi.Client, _ packets.Subscription, pk packets.Packet) {
+		var command map[string]interface{}
+		if json.Unmarshal(pk.Payload, &command) == nil && command["command"] == "ping" {
+			status("online")
+		}
+	}))
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost && r.URL.Path == "/devices/d1/reboot" {
+			status("rebooting")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	t.Cleanup(api.Close)
+
+	return "tcp://" + tcp.Address(), api.URL
+}
+
+// TestRunner_MQTTStages tests publishing and waiting for messages, including after a REST request
+func TestRunner_MQTTStages(t *testing.T) {
+	tmpDir := t.TempDir()
+	testPath := filepath.Join(tmpDir, "test_mqtt.tavern.yaml")
+	require.NoError(t, os.WriteFile(testPath, []byte(`
+test_name: Device
+mqtt:
+  broker: "{broker}"
+  client_id: tavern-device-test
+stages:
+  - name: ping
+    mqtt_publish:
+      topic: devices/{device}/commands
+      json:
+        command: ping
+      qos: 1
+    mqtt_response:
+      topic: devices/+/status
+      json:
+        state: online
+      save:
+        json:
+          uptime: uptime
+
+  - name: reboot
+    request:
+      url: "{host}/devices/{device}/reboot"
+      method: POST
+    response:
+      status_code: 202
+    mqtt_response:
+      topic: devices/{device}/status
+      json:
+        state: rebooting
+        uptime: !int "{uptime}"
+`), 0644))
+
+	brokerURL, host := startDevice(t)
+	runner, err := NewRunner(&Config{BaseDir: tmpDir})
+	require.NoError(t, err)
+	runner.SetVariable("broker", brokerURL)
+	runner.SetVariable("host", host)
+	runner.SetVariable("device", "d1")
+
+	require.NoError(t, runner.RunFile(testPath))
+}
+
+// TestRunner_MQTTFailures tests stages without an mqtt block and messages that never arrive
+func TestRunner_MQTTFailures(t *testing.T) {
+	brokerURL, _ := startDevice(t)
+	tmpDir := t.TempDir()
+
+	tests := map[string]struct {
+		test string
+		err  string
+	}{
+		"no mqtt block": {`
+stages:
+  - name: ping
+    mqtt_publish:
+      topic: devices/d1/commands
+      payload: ping
+`, "mqtt_publish requires an mqtt block"},
+		"no message": {`
+mqtt:
+  broker: "` + brokerURL + `"
+stages:
+  - name: ping
+    mqtt_publish:
+      topic: devices/d1/commands
+      json: {command: ping}
+    mqtt_response:
+      topic: devices/d1/status
+      json: {state: offline}
+      timeout: 0.3
+`, "topic devices/d1/status: no matching message within 300ms (1 received)"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testPath := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(name, " ", "_")+".tavern.yaml")
+			require.NoError(t, os.WriteFile(testPath, []byte("test_name: "+name+tt.test), 0644))
+
+			runner, err := NewRunner(&Config{BaseDir: tmpDir})
+			require.NoError(t, err)
+			err = runner.RunFile(testPath)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
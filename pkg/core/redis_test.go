@@ -0,0 +1,185 @@
+package core
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/resp/resptest"
+)
+
+// startCache starts a RESP server and an API whose POST /carts caches the
+// cart as a hash and a JSON string, and counts carts. It returns the API URL
+// and the cache host:port.
+func startCache(t *testing.T) (string, string) {
+	t.Helper()
+	cache, err := resptest.NewServer()
+	require.NoError(t, err)
+	t.Cleanup(cache.Close)
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var cart struct {
+			User string `json:"user"`
+		}
+		if r.Method != http.MethodPost || json.NewDecoder(r.Body).Decode(&cart) != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		id := cart.User + "-1"
+		cache.Do("HSET", "cart:"+id, "user", cart.User, "items", "0")
+		cache.Do("SET", "cart:"+id+":json", `{"id": "`+id+`", "user": "`+cart.User+`"}`)
+		cache.Do("RPUSH", "carts", id)
+		cache.Do("INCR", "stats:carts")
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": id})
+	}))
+	t.Cleanup(api.Close)
+
+	return api.URL, cache.Addr
+}
+
+// TestRunner_RedisStages tests checking and saving cache entries after an API call
+func TestRunner_RedisStages(t *testing.T) {
+	tmpDir := t.TempDir()
+	testPath := filepath.Join(tmpDir, "test_redis.tavern.yaml")
+	require.NoError(t, os.WriteFile(testPath, []byte(`
+test_name: Cart cache
+stages:
+  - name: create cart
+    request:
+      url: "{api}/carts"
+      method: POST
+      json:
+        user: alice
+    response:
+      status_code: 201
+      save:
+        body:
+          cart_id: id
+
+  - name: cart hash
+    redis:
+      host: "{cache}"
+      command: [HGETALL, "cart:{cart_id}"]
+      reply:
+        user: alice
+        items: "0"
+
+  - name: cart json
+    redis:
+      host: "{cache}"
+      command: [GET, "cart:{cart_id}:json"]
+      json:
+        id: "{cart_id}"
+        user: alice
+      save:
+        json:
+          cart_user: user
+
+  - name: cart list
+    redis:
+      host: "{cache}"
+      command: [LRANGE, carts, 0, -1]
+      reply: ["{cart_id}"]
+      save:
+        reply:
+          first_cart: "0"
+
+  - name: counter
+    redis:
+      host: "{cache}"
+      command: [INCR, "stats:carts"]
+      reply: !anyint
+      save:
+        reply:
+          count: ""
+
+  - name: use saved values
+    redis:
+      host: "{cache}"
+      command: [SET, "last:{cart_user}", "{first_cart}/{count}"]
+      reply: OK
+
+  - name: check saved values
+    redis:
+      host: "{cache}"
+      command: [GET, last:alice]
+      reply: alice-1/2
+
+  - name: missing key
+    redis:
+      host: "{cache}"
+      command: [GET, "cart:nobody-1"]
+      null: true
+
+  - name: wrong type
+    redis:
+      host: "{cache}"
+      command: [GET, "cart:{cart_id}"]
+      error: WRONGTYPE
+`), 0644))
+
+	api, cache := startCache(t)
+	runner, err := NewRunner(&Config{BaseDir: tmpDir})
+	require.NoError(t, err)
+	runner.SetVariable("api", api)
+	runner.SetVariable("cache", cache)
+
+	require.NoError(t, runner.RunFile(testPath))
+}
+
+// TestRunner_RedisFailures tests replies that don't match and invalid redis stages
+func TestRunner_RedisFailures(t *testing.T) {
+	_, cache := startCache(t)
+	tmpDir := t.TempDir()
+
+	tests := map[string]struct {
+		stages string
+		err    string
+	}{
+		"reply": {`
+  - name: missing
+    redis:
+      host: "` + cache + `"
+      command: [GET, missing]
+      reply: value
+`, "reply: expected 'value' (type: string), got '<nil>' (type: <nil>)"},
+		"error reply": {`
+  - name: unknown
+    redis:
+      host: "` + cache + `"
+      command: [NOPE]
+`, "reply: unexpected error reply 'ERR unknown command 'NOPE''"},
+		"connection": {`
+  - name: closed
+    redis:
+      host: 127.0.0.1:1
+      command: [PING]
+`, "failed to connect to 127.0.0.1:1"},
+		"no command": {`
+  - name: empty
+    redis:
+      host: "` + cache + `"
+`, "stages.0.redis"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testPath := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(name, " ", "_")+".tavern.yaml")
+			require.NoError(t, os.WriteFile(testPath, []byte("test_name: "+name+"\nstages:"+tt.stages), 0644))
+
+			runner, err := NewRunner(&Config{BaseDir: tmpDir})
+			require.NoError(t, err)
+			err = runner.RunFile(testPath)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/systemquest/tavern-go/pkg/coverage"
+	"github.com/systemquest/tavern-go/pkg/request"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// TestResult records the outcome of a single test
+type TestResult struct {
+	Name     string         `json:"name"`
+	Passed   bool           `json:"passed"`
+	Skipped  bool           `json:"skipped,omitempty"`
+	Duration time.Duration  `json:"-"`
+	Error    string         `json:"error,omitempty"`
+	Stages   []*StageResult `json:"stages"`
+}
+
+// StageResult records the outcome of a single stage
+type StageResult struct {
+	Name     string          `json:"name"`
+	Skipped  bool            `json:"skipped,omitempty"`
+	Passed   bool            `json:"passed"`
+	Duration time.Duration   `json:"-"`
+	Timing   *request.Timing `json:"timing,omitempty"` // HTTP timing breakdown (REST stages only)
+	Error    string          `json:"error,omitempty"`
+
+	// StatusCode is the HTTP response status (REST stages only)
+	StatusCode int `json:"status_code,omitempty"`
+	// RequestVars are the tavern.request_vars of the stage, used for coverage reports
+	// They may hold secrets, so they are not written to reports
+	RequestVars map[string]interface{} `json:"-"`
+
+	// Failures holds the structured assertion failures when the stage failed validation
+	Failures []util.AssertionFailure `json:"failures,omitempty"`
+}
+
+// MarshalJSON adds the duration in milliseconds
+func (r *TestResult) MarshalJSON() ([]byte, error) {
+	type alias TestResult
+	return json.Marshal(struct {
+		*alias
+		DurationMs float64 `json:"duration_ms"`
+	}{
+		alias:      (*alias)(r),
+		DurationMs: float64(r.Duration) / float64(time.Millisecond),
+	})
+}
+
+// MarshalJSON adds the duration in milliseconds
+func (r *StageResult) MarshalJSON() ([]byte, error) {
+	type alias StageResult
+	return json.Marshal(struct {
+		*alias
+		DurationMs float64 `json:"duration_ms"`
+	}{
+		alias:      (*alias)(r),
+		DurationMs: float64(r.Duration) / float64(time.Millisecond),
+	})
+}
+
+// Results returns the results of all tests run so far
+func (r *Runner) Results() []*TestResult {
+	return r.results
+}
+
+// Coverage reports which operations of the --openapi document the tests run so far exercised
+func (r *Runner) Coverage() (*coverage.Report, error) {
+	if r.config.OpenAPI == nil {
+		return nil, fmt.Errorf("coverage requires an OpenAPI document (--openapi)")
+	}
+
+	var calls []coverage.Call
+	for _, test := range r.results {
+		for _, stage := range test.Stages {
+			if stage.RequestVars == nil {
+				continue
+			}
+			calls = append(calls, coverage.CallFromRequestVars(stage.RequestVars, stage.StatusCode))
+		}
+	}
+	return coverage.Build(r.config.OpenAPI, calls), nil
+}
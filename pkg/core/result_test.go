@@ -0,0 +1,112 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// TestRunner_Results tests that test and stage results are recorded with HTTP timings
+func TestRunner_Results(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"key": "value"})
+	}))
+	defer server.Close()
+
+	testSpec := &schema.TestSpec{
+		TestName: "Results are recorded",
+		Stages: []schema.Stage{
+			{
+				Name:     "skipped stage",
+				Skip:     true,
+				Request:  &schema.RequestSpec{URL: server.URL},
+				Response: &schema.ResponseSpec{},
+			},
+			{
+				Name:     "passing stage",
+				Request:  &schema.RequestSpec{URL: server.URL},
+				Response: &schema.ResponseSpec{StatusCode: &schema.StatusCode{Single: 200}},
+			},
+			{
+				Name:     "failing stage",
+				Request:  &schema.RequestSpec{URL: server.URL + "/fail"},
+				Response: &schema.ResponseSpec{StatusCode: &schema.StatusCode{Single: 200}},
+			},
+		},
+	}
+
+	runner, err := NewRunner(&Config{})
+	require.NoError(t, err)
+
+	err = runner.RunTest(testSpec)
+	require.Error(t, err)
+
+	results := runner.Results()
+	require.Len(t, results, 1)
+	result := results[0]
+	assert.Equal(t, "Results are recorded", result.Name)
+	assert.False(t, result.Passed)
+	assert.Contains(t, result.Error, "status code mismatch")
+	require.Len(t, result.Stages, 3)
+
+	assert.True(t, result.Stages[0].Skipped)
+	assert.Nil(t, result.Stages[0].Timing)
+
+	assert.True(t, result.Stages[1].Passed)
+	require.NotNil(t, result.Stages[1].Timing)
+	assert.Greater(t, result.Stages[1].Timing.Total, time.Duration(0))
+
+	assert.False(t, result.Stages[2].Passed)
+	assert.Contains(t, result.Stages[2].Error, "status code mismatch")
+	require.Len(t, result.Stages[2].Failures, 1)
+	assert.Equal(t, util.BlockStatus, result.Stages[2].Failures[0].Block)
+	assert.Equal(t, 500, result.Stages[2].Failures[0].Actual)
+
+	// Results serialise with millisecond durations
+	data, err := json.Marshal(results)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"duration_ms"`)
+	assert.Contains(t, string(data), `"ttfb_ms"`)
+	assert.Contains(t, string(data), `"failures"`)
+}
+
+// TestRunner_TimingOnlyInVerboseOutput tests that the timing breakdown is only logged with -v
+func TestRunner_TimingOnlyInVerboseOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	testSpec := &schema.TestSpec{
+		TestName: "Timing",
+		Stages: []schema.Stage{{
+			Name:     "timed stage",
+			Request:  &schema.RequestSpec{URL: server.URL},
+			Response: &schema.ResponseSpec{StatusCode: &schema.StatusCode{Single: 200}},
+		}},
+	}
+
+	for _, verbose := range []bool{false, true} {
+		runner, err := NewRunner(&Config{Verbose: verbose})
+		require.NoError(t, err)
+		var output bytes.Buffer
+		runner.logger.SetOutput(&output)
+		runner.logger.SetLevel(logrus.InfoLevel) // As if a caller raised the level
+
+		require.NoError(t, runner.RunTest(testSpec))
+		assert.Equal(t, verbose, strings.Contains(output.String(), "Timing for stage 'timed stage'"), "verbose=%v", verbose)
+	}
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/cookiejar"
@@ -9,6 +10,7 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/systemquest/tavern-go/pkg/openapi"
 	"github.com/systemquest/tavern-go/pkg/request"
 	"github.com/systemquest/tavern-go/pkg/response"
 	"github.com/systemquest/tavern-go/pkg/schema"
@@ -22,6 +24,7 @@ type Runner struct {
 	loader    *yamlpkg.Loader
 	validator *schema.Validator
 	logger    *logrus.Logger
+	results   []*TestResult
 }
 
 // Config holds runner configuration
@@ -31,7 +34,17 @@ type Config struct {
 	Variables    map[string]interface{}
 	Verbose      bool
 	Debug        bool
-	SkipXfail    bool // Skip tests marked with _xfail (aligned with tavern-py commit 369a4bb)
+	SkipXfail    bool   // Skip tests marked with _xfail (aligned with tavern-py commit 369a4bb)
+	Environment  string // Named environment selected from the global config's environments section
+
+	// Overrides are command-line variables (--vars-file, --var), applied after includes
+	Overrides map[string]interface{}
+	// EnvFile holds values loaded from --env-file, exposed via tavern.env_vars
+	// Variables set in the real environment take precedence
+	EnvFile map[string]string
+
+	// OpenAPI is the contract REST responses are validated against (--openapi)
+	OpenAPI *openapi.Spec
 }
 
 // NewRunner creates a new test runner
@@ -51,6 +64,10 @@ func NewRunner(config *Config) (*Runner, error) {
 		config.Variables = make(map[string]interface{})
 	}
 
+	if config.Overrides == nil {
+		config.Overrides = make(map[string]interface{})
+	}
+
 	// Create logger
 	logger := logrus.New()
 	if config.Debug {
@@ -64,6 +81,10 @@ func NewRunner(config *Config) (*Runner, error) {
 		logrus.SetLevel(logrus.WarnLevel) // Also set global level for RestValidator
 	}
 
+	// Mask secret values in everything the runner and validators log
+	util.InstallSecretMasking(logger)
+	util.InstallSecretMasking(logrus.StandardLogger())
+
 	// Create schema validator
 	validator, err := schema.NewValidator()
 	if err != nil {
@@ -98,6 +119,7 @@ func (r *Runner) RunFile(filename string) error {
 		// Skip tests with _xfail when SkipXfail is enabled (aligned with tavern-py commit 369a4bb)
 		if r.config.SkipXfail && test.Xfail != "" {
 			r.logger.Infof("_xfail does not work with tavern-go CLI when --skip-xfail is set, skipping test '%s'", test.TestName)
+			r.results = append(r.results, &TestResult{Name: test.TestName, Skipped: true})
 			continue
 		}
 
@@ -107,9 +129,12 @@ func (r *Runner) RunFile(filename string) error {
 		// Validate test schema
 		schemaErr := r.validator.Validate(test)
 		if schemaErr != nil {
+			schemaResult := &TestResult{Name: test.TestName, Error: schemaErr.Error()}
+			r.results = append(r.results, schemaResult)
 			if xfail == "verify" {
 				r.logger.Infof("Test '%s': xfailing during schema verification", test.TestName)
 				r.logger.Infof("Test passed (expected schema failure): %s", test.TestName)
+				schemaResult.Passed = true
 				continue
 			}
 			r.logger.Errorf("Schema validation failed for test '%s': %v", test.TestName, schemaErr)
@@ -121,10 +146,12 @@ func (r *Runner) RunFile(filename string) error {
 
 		// Run test
 		runErr := r.RunTest(test)
+		testResult := r.results[len(r.results)-1]
 		if runErr != nil {
 			if xfail == "run" {
 				r.logger.Infof("Test '%s': xfailing during test execution", test.TestName)
 				r.logger.Infof("Test passed (expected runtime failure): %s", test.TestName)
+				testResult.Passed = true
 				continue
 			}
 			r.logger.Errorf("Test failed: %s: %v", test.TestName, runErr)
@@ -137,6 +164,8 @@ func (r *Runner) RunFile(filename string) error {
 		// If xfail was set but test passed, that's an error
 		if xfail != "" {
 			err := util.NewTestFailError("Expected test to fail but it passed", nil)
+			testResult.Passed = false
+			testResult.Error = util.MaskSecrets(err.Error())
 			r.logger.Errorf("Test '%s': expected failure but test passed (xfail=%s)", test.TestName, xfail)
 			if firstError == nil {
 				firstError = err
@@ -150,8 +179,24 @@ func (r *Runner) RunFile(filename string) error {
 	return firstError
 }
 
-// RunTest runs a single test
+// RunTest runs a single test and records its result
 func (r *Runner) RunTest(test *schema.TestSpec) error {
+	result := &TestResult{Name: test.TestName}
+	r.results = append(r.results, result)
+
+	start := time.Now()
+	err := r.runTest(test, result)
+	result.Duration = time.Since(start)
+	result.Passed = err == nil
+	if err != nil {
+		result.Error = util.MaskSecrets(util.StripANSI(err.Error()))
+	}
+
+	return err
+}
+
+// runTest runs the stages of a single test, recording stage results into result
+func (r *Runner) runTest(test *schema.TestSpec, result *TestResult) error {
 	r.logger.Infof("Running test: %s", test.TestName)
 
 	// Create shared HTTP client for session persistence (aligned with tavern-py's requests.Session)
@@ -184,24 +229,40 @@ func (r *Runner) RunTest(test *schema.TestSpec) error {
 		PersistentCookies: sharedPersistentCookies, // Share persistent cookies tracking
 	}
 
+	// A websocket connection can stay open across stages; close it when the test ends
+	defer func() {
+		if testConfig.WebSocket != nil {
+			_ = testConfig.WebSocket.Close()
+		}
+	}()
+
 	// Inject tavern magic variables (aligned with tavern-py commit 1b55d6e)
 	// Provides access to environment variables via {tavern.env_vars.VAR_NAME}
 	testConfig.Variables["tavern"] = map[string]interface{}{
-		"env_vars": getEnvVarsMap(),
+		"env_vars": getEnvVarsMap(r.config.EnvFile),
 	}
 
-	// Merge global variables
+	// Merge global variables, then global config variables
+	globalVars := make(map[string]interface{}, len(r.config.Variables))
 	for k, v := range r.config.Variables {
-		testConfig.Variables[k] = v
+		globalVars[k] = v
 	}
-
-	// Merge global config variables
-	if globalVars, ok := r.config.GlobalConfig["variables"].(map[string]interface{}); ok {
-		for k, v := range globalVars {
-			testConfig.Variables[k] = v
+	if configVars, ok := r.config.GlobalConfig["variables"].(map[string]interface{}); ok {
+		for k, v := range configVars {
+			globalVars[k] = v
 		}
 	}
 
+	// Resolve !file, !env and !cmd values before any stage runs, since
+	// formatting only resolves a string that is a whole marker
+	resolvedVars, err := util.ResolveExternal(globalVars)
+	if err != nil {
+		return fmt.Errorf("failed to resolve global variables: %w", err)
+	}
+	for k, v := range resolvedVars.(map[string]interface{}) {
+		testConfig.Variables[k] = v
+	}
+
 	// Process includes
 	// Format variables before merging to allow env vars in included files (aligned with tavern-py commit 8ea5f2d)
 	for _, include := range test.Includes {
@@ -219,11 +280,46 @@ func (r *Runner) RunTest(test *schema.TestSpec) error {
 		}
 	}
 
+	// Apply command-line overrides last so they win over global configs and includes
+	if len(r.config.Overrides) > 0 {
+		formattedOverrides, err := util.FormatKeys(r.config.Overrides, testConfig.Variables)
+		if err != nil {
+			return fmt.Errorf("failed to format variable overrides: %w", err)
+		}
+		if formattedMap, ok := formattedOverrides.(map[string]interface{}); ok {
+			for k, v := range formattedMap {
+				testConfig.Variables[k] = v
+			}
+		}
+	}
+
+	// Register secret values before anything is logged
+	r.registerSecrets(testConfig.Variables)
+
+	// Apply base_url, tls and auth from the global config (and selected environment)
+	if err := r.configureHTTPClient(sharedHTTPClient, testConfig); err != nil {
+		return err
+	}
+
+	// Connect to the MQTT broker for the whole test (aligned with tavern-py's "mqtt" in test_spec)
+	if test.MQTT != nil {
+		client, err := request.ConnectMQTT(testConfig, *test.MQTT)
+		if err != nil {
+			return fmt.Errorf("test '%s': %w", test.TestName, err)
+		}
+		testConfig.MQTT = client
+		defer client.Close()
+	}
+
 	// Run each stage
 	for i, stage := range test.Stages {
+		stageResult := &StageResult{Name: stage.Name}
+		result.Stages = append(result.Stages, stageResult)
+
 		// Check skip keyword (aligned with tavern-py commit cfdf901)
 		if stage.Skip {
 			r.logger.Infof("Skipping stage %d/%d: %s", i+1, len(test.Stages), stage.Name)
+			stageResult.Skipped = true
 			continue
 		}
 
@@ -232,72 +328,21 @@ func (r *Runner) RunTest(test *schema.TestSpec) error {
 		// Delay before stage execution
 		delay(&stage, "before")
 
-		// Protocol detection - check stage-level keys (aligned with tavern-py)
-		// tavern-py checks: if "request" in stage / elif "mqtt_publish" in stage
-		if stage.Request != nil {
-			// REST/HTTP protocol
-			if stage.Response == nil {
-				return fmt.Errorf("stage '%s': REST request requires response specification", stage.Name)
-			}
-
-			executor := request.NewRestClient(testConfig)
-			resp, err := executor.Execute(*stage.Request)
-			if err != nil {
-				return fmt.Errorf("stage '%s' request failed: %w", stage.Name, err)
-			}
-
-			// Inject request_vars into tavern namespace (aligned with tavern-py commit 35e52d9)
-			// Enables access to request parameters in response validation: {tavern.request_vars.json.field}
-			if tavernVars, ok := testConfig.Variables["tavern"].(map[string]interface{}); ok {
-				tavernVars["request_vars"] = executor.RequestVars
-			}
-
-			// Determine strict configuration (aligned with tavern-py commit 3838566)
-			// Priority: stage.response.strict > test.strict > config.strict (global)
-			var stageStrict *schema.Strict
-			if stage.Response.Strict != nil {
-				// Stage-level strict overrides all
-				stageStrict = stage.Response.Strict
-				r.logger.Debugf("Using stage-level strict configuration")
-			} else if test.Strict != nil {
-				// Test-level strict
-				stageStrict = test.Strict
-				r.logger.Debugf("Using test-level strict configuration")
-			} else {
-				// Use global/default (legacy behavior)
-				stageStrict = schema.NewStrictLegacy()
-				r.logger.Debugf("Using legacy strict behavior (no strict configured)")
-			}
-
-			validatorConfig := &response.Config{
-				Variables: testConfig.Variables,
-				Strict:    stageStrict,
-			}
-			validator := response.NewRestValidator(stage.Name, *stage.Response, validatorConfig)
-			saved, err := validator.Verify(resp)
-			if err != nil {
-				return fmt.Errorf("stage '%s' validation failed: %w", stage.Name, err)
-			}
-
-			// Clean up request_vars after validation (aligned with tavern-py commit 35e52d9)
-			if tavernVars, ok := testConfig.Variables["tavern"].(map[string]interface{}); ok {
-				delete(tavernVars, "request_vars")
-			}
-
-			// Save variables for next stages
-			for k, v := range saved {
-				r.logger.Debugf("Saved variable: %s = %v", k, v)
-				testConfig.Variables[k] = v
+		stageStart := time.Now()
+		err := r.runStage(test, &stage, testConfig, stageResult)
+		stageResult.Duration = time.Since(stageStart)
+		if err != nil {
+			stageResult.Error = util.MaskSecrets(util.StripANSI(err.Error()))
+			var failErr *util.TestFailError
+			if errors.As(err, &failErr) {
+				stageResult.Failures = maskFailures(failErr.Failures)
 			}
-		} else {
-			// Future protocols can be added here with elif-style checks:
-			// } else if stage.MQTTPublish != nil {
-			//     // MQTT protocol
-			// } else if stage.Command != nil {
-			//     // Shell/CLI protocol
-			// } else {
-			return fmt.Errorf("stage '%s': unable to detect protocol (no request field found)", stage.Name)
+			return err
 		}
+		stageResult.Passed = true
+
+		// Saved values may be listed in secrets
+		r.registerSecrets(testConfig.Variables)
 
 		r.logger.Infof("Stage passed: %s", stage.Name)
 
@@ -314,6 +359,340 @@ func (r *Runner) RunTest(test *schema.TestSpec) error {
 	return nil
 }
 
+// runStage executes a single stage, dispatching on the protocol keys present in the stage
+func (r *Runner) runStage(test *schema.TestSpec, stage *schema.Stage, testConfig *request.Config, stageResult *StageResult) error {
+	// Protocol detection - check stage-level keys (aligned with tavern-py)
+	// tavern-py checks: if "request" in stage / elif "mqtt_publish" in stage
+	if stage.Request != nil {
+		// REST/HTTP protocol
+		return r.runRESTStage(test, stage, testConfig, stageResult)
+	} else if stage.GRPCRequest != nil {
+		// gRPC protocol
+		return r.runGRPCStage(test, stage, testConfig)
+	} else if stage.WebSocket != nil {
+		// WebSocket protocol
+		return r.runWebSocketStage(test, stage, testConfig)
+	} else if stage.MQTTPublish != nil {
+		// MQTT protocol
+		return r.runMQTTStage(test, stage, testConfig)
+	} else if stage.Command != nil {
+		// Shell/CLI protocol
+		return r.runCommandStage(test, stage, testConfig)
+	} else if stage.TCP != nil {
+		// Raw TCP protocol
+		return r.runTCPStage(test, stage, testConfig)
+	} else if stage.Redis != nil {
+		// Redis (RESP) protocol
+		return r.runRedisStage(test, stage, testConfig)
+	}
+
+	return fmt.Errorf("stage '%s': unable to detect protocol (no request field found)", stage.Name)
+}
+
+// runRESTStage executes a REST/HTTP stage and saves variables for the following stages
+func (r *Runner) runRESTStage(test *schema.TestSpec, stage *schema.Stage, testConfig *request.Config, stageResult *StageResult) error {
+	if stage.Response == nil {
+		return fmt.Errorf("stage '%s': REST request requires response specification", stage.Name)
+	}
+
+	// A message caused by the request can arrive before the response
+	if err := r.subscribeMQTT(stage, testConfig); err != nil {
+		return err
+	}
+
+	executor := request.NewRestClient(testConfig)
+	if stage.Response.Events != nil {
+		executor.ReadTimeout = response.EventsTimeout(stage.Response.Events)
+	}
+	resp, err := executor.Execute(*stage.Request)
+	if err != nil {
+		return fmt.Errorf("stage '%s' request failed: %w", stage.Name, err)
+	}
+	stageResult.Timing = executor.Timing
+	stageResult.StatusCode = resp.StatusCode
+	stageResult.RequestVars = executor.RequestVars
+
+	// Inject request_vars into tavern namespace (aligned with tavern-py commit 35e52d9)
+	// Enables access to request parameters in response validation: {tavern.request_vars.json.field}
+	if tavernVars, ok := testConfig.Variables["tavern"].(map[string]interface{}); ok {
+		tavernVars["request_vars"] = executor.RequestVars
+	}
+
+	validatorConfig := &response.Config{
+		Variables: testConfig.Variables,
+		Strict:    r.stageStrict(test, stage.Response.Strict),
+		OpenAPI:   r.config.OpenAPI,
+		GraphQL:   stage.Request.GraphQL != nil,
+	}
+	validator := response.NewRestValidator(stage.Name, *stage.Response, validatorConfig)
+	saved, err := validator.Verify(resp)
+
+	// The body has been fully read by now, so the timing breakdown is complete.
+	// It is only logged in verbose output.
+	if executor.Timing != nil && (r.config.Verbose || r.config.Debug) {
+		r.logger.Infof("Timing for stage '%s': %s", stage.Name, executor.Timing)
+	}
+
+	if err != nil {
+		return fmt.Errorf("stage '%s' validation failed: %w", stage.Name, err)
+	}
+
+	// Clean up request_vars after validation (aligned with tavern-py commit 35e52d9)
+	if tavernVars, ok := testConfig.Variables["tavern"].(map[string]interface{}); ok {
+		delete(tavernVars, "request_vars")
+	}
+
+	// Save variables for next stages
+	for k, v := range saved {
+		r.logger.Debugf("Saved variable: %s = %v", k, v)
+		testConfig.Variables[k] = v
+	}
+
+	return r.verifyMQTT(test, stage, testConfig)
+}
+
+// runGRPCStage executes a unary gRPC call and saves variables for the following stages
+func (r *Runner) runGRPCStage(test *schema.TestSpec, stage *schema.Stage, testConfig *request.Config) error {
+	if stage.GRPCResponse == nil {
+		return fmt.Errorf("stage '%s': grpc_request requires grpc_response specification", stage.Name)
+	}
+
+	client := request.NewGRPCClient(testConfig)
+	resp, err := client.Execute(*stage.GRPCRequest)
+	if err != nil {
+		return fmt.Errorf("stage '%s' request failed: %w", stage.Name, err)
+	}
+	r.logger.Debugf("gRPC call for stage '%s' returned %s in %s", stage.Name, resp.Status.Code(), resp.Duration)
+
+	validatorConfig := &response.Config{
+		Variables: testConfig.Variables,
+		Strict:    r.stageStrict(test, stage.GRPCResponse.Strict),
+	}
+	saved, err := response.NewGRPCValidator(stage.Name, *stage.GRPCResponse, validatorConfig).Verify(resp)
+	if err != nil {
+		return fmt.Errorf("stage '%s' validation failed: %w", stage.Name, err)
+	}
+
+	for k, v := range saved {
+		r.logger.Debugf("Saved variable: %s = %v", k, v)
+		testConfig.Variables[k] = v
+	}
+
+	return nil
+}
+
+// runWebSocketStage opens, uses or closes the test's websocket connection and
+// saves variables from received messages for the following stages
+func (r *Runner) runWebSocketStage(test *schema.TestSpec, stage *schema.Stage, testConfig *request.Config) error {
+	spec := stage.WebSocket
+
+	if spec.Connect != nil {
+		if testConfig.WebSocket != nil {
+			_ = testConfig.WebSocket.Close()
+			testConfig.WebSocket = nil
+		}
+		client, err := request.DialWebSocket(testConfig, *spec.Connect)
+		if err != nil {
+			return fmt.Errorf("stage '%s' request failed: %w", stage.Name, err)
+		}
+		testConfig.WebSocket = client
+	}
+
+	client := testConfig.WebSocket
+	if client == nil {
+		return fmt.Errorf("stage '%s': no open websocket connection (add connect to this or an earlier stage)", stage.Name)
+	}
+
+	for i, msg := range spec.Send {
+		if err := client.Send(msg); err != nil {
+			return fmt.Errorf("stage '%s' failed to send message %d: %w", stage.Name, i, err)
+		}
+	}
+
+	if len(spec.Expect) > 0 {
+		validatorConfig := &response.Config{
+			Variables: testConfig.Variables,
+			Strict:    r.stageStrict(test, spec.Strict),
+		}
+		saved, err := response.NewWebSocketValidator(stage.Name, *spec, validatorConfig).Verify(client)
+		if err != nil {
+			return fmt.Errorf("stage '%s' validation failed: %w", stage.Name, err)
+		}
+
+		for k, v := range saved {
+			r.logger.Debugf("Saved variable: %s = %v", k, v)
+			testConfig.Variables[k] = v
+		}
+	}
+
+	if spec.Close {
+		testConfig.WebSocket = nil
+		if err := client.Close(); err != nil {
+			r.logger.Debugf("Closing websocket for stage '%s': %v", stage.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// runCommandStage runs a command, checks its exit code and output, and saves
+// variables for the following stages. Without command_response the command
+// must exit with 0.
+func (r *Runner) runCommandStage(test *schema.TestSpec, stage *schema.Stage, testConfig *request.Config) error {
+	client := request.NewShellClient(testConfig)
+	resp, err := client.Execute(*stage.Command)
+	if err != nil {
+		return fmt.Errorf("stage '%s' request failed: %w", stage.Name, err)
+	}
+	r.logger.Debugf("Command for stage '%s' exited with %d in %s", stage.Name, resp.ExitCode, resp.Duration)
+
+	var spec schema.CommandResponseSpec
+	if stage.CommandResponse != nil {
+		spec = *stage.CommandResponse
+	}
+	validatorConfig := &response.Config{
+		Variables: testConfig.Variables,
+		Strict:    r.stageStrict(test, spec.Strict),
+	}
+	saved, err := response.NewShellValidator(stage.Name, spec, validatorConfig).Verify(resp)
+	if err != nil {
+		return fmt.Errorf("stage '%s' validation failed: %w", stage.Name, err)
+	}
+
+	for k, v := range saved {
+		r.logger.Debugf("Saved variable: %s = %v", k, v)
+		testConfig.Variables[k] = v
+	}
+
+	return nil
+}
+
+// runTCPStage sends data on a new TCP connection, checks the reply and saves
+// variables for the following stages
+func (r *Runner) runTCPStage(test *schema.TestSpec, stage *schema.Stage, testConfig *request.Config) error {
+	client := request.NewTCPClient(testConfig)
+	resp, err := client.Execute(*stage.TCP)
+	if err != nil {
+		return fmt.Errorf("stage '%s' request failed: %w", stage.Name, err)
+	}
+	r.logger.Debugf("TCP reply for stage '%s' (%d bytes) in %s: %q", stage.Name, len(resp.Data), resp.Duration, resp.Reply)
+
+	validatorConfig := &response.Config{
+		Variables: testConfig.Variables,
+		Strict:    r.stageStrict(test, nil),
+	}
+	saved, err := response.NewTCPValidator(stage.Name, *stage.TCP, validatorConfig).Verify(resp)
+	if err != nil {
+		return fmt.Errorf("stage '%s' validation failed: %w", stage.Name, err)
+	}
+
+	for k, v := range saved {
+		r.logger.Debugf("Saved variable: %s = %v", k, v)
+		testConfig.Variables[k] = v
+	}
+
+	return nil
+}
+
+// runRedisStage sends a Redis command, checks the reply and saves variables
+// for the following stages
+func (r *Runner) runRedisStage(test *schema.TestSpec, stage *schema.Stage, testConfig *request.Config) error {
+	client := request.NewRedisClient(testConfig)
+	resp, err := client.Execute(*stage.Redis)
+	if err != nil {
+		return fmt.Errorf("stage '%s' request failed: %w", stage.Name, err)
+	}
+	r.logger.Debugf("Redis reply for stage '%s' in %s: %#v", stage.Name, resp.Duration, resp.Reply)
+
+	validatorConfig := &response.Config{
+		Variables: testConfig.Variables,
+		Strict:    r.stageStrict(test, nil),
+	}
+	saved, err := response.NewRedisValidator(stage.Name, *stage.Redis, validatorConfig).Verify(resp)
+	if err != nil {
+		return fmt.Errorf("stage '%s' validation failed: %w", stage.Name, err)
+	}
+
+	for k, v := range saved {
+		r.logger.Debugf("Saved variable: %s = %v", k, v)
+		testConfig.Variables[k] = v
+	}
+
+	return nil
+}
+
+// runMQTTStage publishes a message and waits for the stage's mqtt_response, if any
+func (r *Runner) runMQTTStage(test *schema.TestSpec, stage *schema.Stage, testConfig *request.Config) error {
+	if testConfig.MQTT == nil {
+		return fmt.Errorf("stage '%s': mqtt_publish requires an mqtt block in the test", stage.Name)
+	}
+	if err := r.subscribeMQTT(stage, testConfig); err != nil {
+		return err
+	}
+
+	if err := testConfig.MQTT.Publish(*stage.MQTTPublish); err != nil {
+		return fmt.Errorf("stage '%s' request failed: %w", stage.Name, err)
+	}
+
+	return r.verifyMQTT(test, stage, testConfig)
+}
+
+// subscribeMQTT subscribes to the topic of a stage's mqtt_response before the
+// stage publishes or sends its request
+func (r *Runner) subscribeMQTT(stage *schema.Stage, testConfig *request.Config) error {
+	if stage.MQTTResponse == nil {
+		return nil
+	}
+	if testConfig.MQTT == nil {
+		return fmt.Errorf("stage '%s': mqtt_response requires an mqtt block in the test", stage.Name)
+	}
+	if err := testConfig.MQTT.Subscribe(stage.MQTTResponse.Topic, stage.MQTTResponse.QoS); err != nil {
+		return fmt.Errorf("stage '%s': %w", stage.Name, err)
+	}
+	return nil
+}
+
+// verifyMQTT waits for the message of a stage's mqtt_response and saves variables from it
+func (r *Runner) verifyMQTT(test *schema.TestSpec, stage *schema.Stage, testConfig *request.Config) error {
+	if stage.MQTTResponse == nil {
+		return nil
+	}
+
+	validatorConfig := &response.Config{
+		Variables: testConfig.Variables,
+		Strict:    r.stageStrict(test, stage.MQTTResponse.Strict),
+	}
+	saved, err := response.NewMQTTValidator(stage.Name, *stage.MQTTResponse, validatorConfig).Verify(testConfig.MQTT)
+	if err != nil {
+		return fmt.Errorf("stage '%s' validation failed: %w", stage.Name, err)
+	}
+
+	for k, v := range saved {
+		r.logger.Debugf("Saved variable: %s = %v", k, v)
+		testConfig.Variables[k] = v
+	}
+
+	return nil
+}
+
+// stageStrict determines the strict configuration of a stage (aligned with tavern-py commit 3838566)
+// Priority: stage response strict > test.strict > config.strict (global)
+func (r *Runner) stageStrict(test *schema.TestSpec, responseStrict *schema.Strict) *schema.Strict {
+	if responseStrict != nil {
+		// Stage-level strict overrides all
+		r.logger.Debugf("Using stage-level strict configuration")
+		return responseStrict
+	}
+	if test.Strict != nil {
+		// Test-level strict
+		r.logger.Debugf("Using test-level strict configuration")
+		return test.Strict
+	}
+	// Use global/default (legacy behavior)
+	r.logger.Debugf("Using legacy strict behavior (no strict configured)")
+	return schema.NewStrictLegacy()
+}
+
 // LoadGlobalConfig loads a global configuration file
 func (r *Runner) LoadGlobalConfig(filename string) error {
 	r.logger.Infof("Loading global config from %s", filename)
@@ -323,15 +702,14 @@ func (r *Runner) LoadGlobalConfig(filename string) error {
 		return fmt.Errorf("failed to load global config: %w", err)
 	}
 
-	r.config.GlobalConfig = config
-
-	// Merge variables
-	if vars, ok := config["variables"].(map[string]interface{}); ok {
-		for k, v := range vars {
-			r.config.Variables[k] = v
-		}
+	config, err = selectEnvironment(config, r.config.Environment)
+	if err != nil {
+		return err
 	}
 
+	r.config.GlobalConfig = config
+	r.mergeGlobalVariables()
+
 	return nil
 }
 
@@ -360,15 +738,81 @@ func (r *Runner) LoadGlobalConfigs(filenames []string) error {
 		mergedConfig = util.DeepMerge(mergedConfig, config)
 	}
 
+	// Overlay the selected environment once all files are merged,
+	// so an environment can be declared in one file and refined in another
+	mergedConfig, err := selectEnvironment(mergedConfig, r.config.Environment)
+	if err != nil {
+		return err
+	}
+
 	r.config.GlobalConfig = mergedConfig
+	r.mergeGlobalVariables()
+
+	return nil
+}
 
-	// Merge variables from the final merged config
-	if vars, ok := mergedConfig["variables"].(map[string]interface{}); ok {
+// mergeGlobalVariables copies the global config's variables into the runner config
+// A base_url setting is also exposed as the {base_url} variable
+func (r *Runner) mergeGlobalVariables() {
+	if vars, ok := r.config.GlobalConfig["variables"].(map[string]interface{}); ok {
 		for k, v := range vars {
 			r.config.Variables[k] = v
 		}
 	}
 
+	if baseURL, ok := r.config.GlobalConfig["base_url"]; ok && baseURL != nil {
+		r.config.Variables["base_url"] = baseURL
+	}
+}
+
+// SetOverride sets a command-line variable that takes precedence over global configs and includes
+func (r *Runner) SetOverride(key string, value interface{}) {
+	r.config.Overrides[key] = value
+}
+
+// LoadVarsFile loads a YAML mapping of variable overrides
+// Values from later calls (and SetOverride) overwrite earlier ones
+func (r *Runner) LoadVarsFile(filename string) error {
+	r.logger.Infof("Loading variables from %s", filename)
+
+	vars, err := r.loader.LoadGlobalConfig(filename)
+	if err != nil {
+		return fmt.Errorf("failed to load vars file %s: %w", filename, err)
+	}
+
+	for k, v := range vars {
+		r.config.Overrides[k] = v
+	}
+	return nil
+}
+
+// LoadEnvFile loads a .env file whose values are exposed via tavern.env_vars
+func (r *Runner) LoadEnvFile(filename string) error {
+	r.logger.Infof("Loading environment from %s", filename)
+
+	vars, err := util.LoadDotEnv(filename)
+	if err != nil {
+		return err
+	}
+
+	if r.config.EnvFile == nil {
+		r.config.EnvFile = make(map[string]string)
+	}
+	for k, v := range vars {
+		r.config.EnvFile[k] = v
+	}
+	return nil
+}
+
+// LoadOpenAPI loads an OpenAPI 3 document to validate REST responses against
+func (r *Runner) LoadOpenAPI(filename string) error {
+	r.logger.Infof("Loading OpenAPI document from %s", filename)
+
+	spec, err := openapi.Load(filename)
+	if err != nil {
+		return err
+	}
+	r.config.OpenAPI = spec
 	return nil
 }
 
@@ -409,13 +853,24 @@ func (r *Runner) ValidateFile(filename string) error {
 
 // getEnvVarsMap returns all environment variables as a map
 // Aligned with tavern-py commit 1b55d6e: provides access to os.environ
-func getEnvVarsMap() map[string]interface{} {
+// Values from envFile are used as defaults for variables not set in the environment
+func getEnvVarsMap(envFile map[string]string) map[string]interface{} {
 	envMap := make(map[string]interface{})
+	for k, v := range envFile {
+		envMap[k] = v
+	}
 	for _, env := range os.Environ() {
 		parts := strings.SplitN(env, "=", 2)
 		if len(parts) == 2 {
 			envMap[parts[0]] = parts[1]
 		}
 	}
+
+	// Variables named *_SECRET are masked in logs, errors and reports
+	for k, v := range envMap {
+		if strings.HasSuffix(k, util.SecretEnvSuffix) {
+			util.AddSecret(v.(string))
+		}
+	}
 	return envMap
 }
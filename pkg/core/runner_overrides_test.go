@@ -0,0 +1,107 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+)
+
+// TestRunner_VariableOverridePrecedence tests that --vars-file and --var win over
+// global configs and includes, and that --var wins over --vars-file
+func TestRunner_VariableOverridePrecedence(t *testing.T) {
+	var gotPaths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPaths = append(gotPaths, r.URL.Path+"?"+r.URL.RawQuery)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	tmpDir := t.TempDir()
+	globalPath := filepath.Join(tmpDir, "global.yaml")
+	require.NoError(t, os.WriteFile(globalPath, []byte(`
+variables:
+  host: http://global.invalid
+  user: global
+  token: global
+`), 0644))
+
+	varsPath := filepath.Join(tmpDir, "vars.yaml")
+	require.NoError(t, os.WriteFile(varsPath, []byte(`
+user: from-vars-file
+token: from-vars-file
+`), 0644))
+
+	runner, err := NewRunner(&Config{BaseDir: tmpDir})
+	require.NoError(t, err)
+	require.NoError(t, runner.LoadGlobalConfigs([]string{globalPath}))
+	require.NoError(t, runner.LoadVarsFile(varsPath))
+	runner.SetOverride("host", server.URL)
+	runner.SetOverride("token", "from-var")
+
+	testSpec := &schema.TestSpec{
+		TestName: "Overrides",
+		Includes: []schema.Include{
+			{Name: "include", Variables: map[string]interface{}{"user": "include", "path": "items"}},
+		},
+		Stages: []schema.Stage{
+			{
+				Name:     "request",
+				Request:  &schema.RequestSpec{URL: "{host}/{path}?user={user}&token={token}"},
+				Response: &schema.ResponseSpec{StatusCode: &schema.StatusCode{Single: 200}},
+			},
+		},
+	}
+
+	require.NoError(t, runner.RunTest(testSpec))
+	require.Len(t, gotPaths, 1)
+	assert.Equal(t, "/items?user=from-vars-file&token=from-var", gotPaths[0])
+}
+
+// TestRunner_EnvFile tests that .env values are exposed via tavern.env_vars
+// and that real environment variables take precedence
+func TestRunner_EnvFile(t *testing.T) {
+	var gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.RawQuery
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	t.Setenv("TAVERN_TEST_FROM_ENV", "real")
+
+	envPath := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(envPath, []byte(`
+TAVERN_TEST_FROM_ENV=file
+TAVERN_TEST_ONLY_FILE="from file"
+`), 0644))
+
+	runner, err := NewRunner(&Config{})
+	require.NoError(t, err)
+	require.NoError(t, runner.LoadEnvFile(envPath))
+
+	testSpec := &schema.TestSpec{
+		TestName: "Env file",
+		Stages: []schema.Stage{
+			{
+				Name: "request",
+				Request: &schema.RequestSpec{
+					URL: server.URL,
+					Params: map[string]string{
+						"env":  "{tavern.env_vars.TAVERN_TEST_FROM_ENV}",
+						"file": "{tavern.env_vars.TAVERN_TEST_ONLY_FILE}",
+					},
+				},
+				Response: &schema.ResponseSpec{StatusCode: &schema.StatusCode{Single: 200}},
+			},
+		},
+	}
+
+	require.NoError(t, runner.RunTest(testSpec))
+	assert.Equal(t, "env=real&file=from+file", gotQuery)
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// registerSecrets registers secret variable values for masking
+// Values tagged with !secret have their marker stripped, and variables named
+// in the global config's secrets list are registered by name
+func (r *Runner) registerSecrets(variables map[string]interface{}) {
+	for k, v := range variables {
+		variables[k] = stripSecretMarkers(v)
+	}
+
+	names, _ := r.config.GlobalConfig["secrets"].([]interface{})
+	for _, name := range names {
+		nameStr, ok := name.(string)
+		if !ok {
+			continue
+		}
+		if value, ok := variables[nameStr]; ok {
+			registerSecretValue(value)
+		}
+	}
+}
+
+// stripSecretMarkers removes !secret markers from a nested value, registering the secrets
+func stripSecretMarkers(val interface{}) interface{} {
+	switch v := val.(type) {
+	case string:
+		if strings.HasPrefix(v, util.SecretMarker) {
+			secret := strings.TrimPrefix(v, util.SecretMarker)
+			util.AddSecret(secret)
+			return secret
+		}
+		return v
+	case map[string]interface{}:
+		for k, item := range v {
+			v[k] = stripSecretMarkers(item)
+		}
+		return v
+	case []interface{}:
+		for i, item := range v {
+			v[i] = stripSecretMarkers(item)
+		}
+		return v
+	default:
+		return val
+	}
+}
+
+// registerSecretValue registers every scalar in a (possibly nested) value as a secret
+func registerSecretValue(val interface{}) {
+	switch v := val.(type) {
+	case nil:
+	case map[string]interface{}:
+		for _, item := range v {
+			registerSecretValue(item)
+		}
+	case []interface{}:
+		for _, item := range v {
+			registerSecretValue(item)
+		}
+	default:
+		util.AddSecret(fmt.Sprintf("%v", v))
+	}
+}
+
+// maskFailures masks secrets in the expected and actual values of assertion failures
+func maskFailures(failures []util.AssertionFailure) []util.AssertionFailure {
+	masked := make([]util.AssertionFailure, len(failures))
+	for i, f := range failures {
+		f.Expected = util.MaskSecretsValue(f.Expected)
+		f.Actual = util.MaskSecretsValue(f.Actual)
+		f.Message = util.MaskSecrets(f.Message)
+		masked[i] = f
+	}
+	return masked
+}
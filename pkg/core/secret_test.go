@@ -0,0 +1,89 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRunner_SecretMasking tests that secrets from the config secrets list, *_SECRET
+// environment variables and !secret tags are masked in logs, errors and results
+func TestRunner_SecretMasking(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"api_key":  r.Header.Get("X-Api-Key"),
+			"env":      r.Header.Get("X-Env"),
+			"password": r.URL.Query().Get("password"),
+		})
+	}))
+	defer server.Close()
+
+	t.Setenv("TAVERN_TEST_TOKEN_SECRET", "env-secret-value")
+
+	tmpDir := t.TempDir()
+	globalPath := filepath.Join(tmpDir, "global.yaml")
+	require.NoError(t, os.WriteFile(globalPath, []byte(`
+secrets: [api_key]
+variables:
+  api_key: config-secret-value
+`), 0644))
+
+	testPath := filepath.Join(tmpDir, "test_secret.tavern.yaml")
+	require.NoError(t, os.WriteFile(testPath, []byte(`
+test_name: Secrets are masked
+includes:
+  - name: creds
+    description: Credentials
+    variables:
+      password: !secret tagged-secret-value
+stages:
+  - name: leak secrets
+    request:
+      url: `+server.URL+`
+      headers:
+        X-Api-Key: "{api_key}"
+        X-Env: "{tavern.env_vars.TAVERN_TEST_TOKEN_SECRET}"
+      params:
+        password: "{password}"
+    response:
+      status_code: 200
+      body:
+        api_key: wrong
+        env: wrong
+        password: wrong
+`), 0644))
+
+	runner, err := NewRunner(&Config{BaseDir: tmpDir, Debug: true})
+	require.NoError(t, err)
+
+	var logs bytes.Buffer
+	runner.logger.SetOutput(&logs)
+	logrus.SetOutput(&logs)
+	defer logrus.SetOutput(os.Stderr)
+
+	require.NoError(t, runner.LoadGlobalConfigs([]string{globalPath}))
+	runErr := runner.RunFile(testPath)
+	require.Error(t, runErr)
+
+	results := runner.Results()
+	require.Len(t, results, 1)
+	report, err := json.Marshal(results)
+	require.NoError(t, err)
+
+	for _, secret := range []string{"config-secret-value", "env-secret-value", "tagged-secret-value"} {
+		assert.NotContains(t, runErr.Error(), secret)
+		assert.NotContains(t, logs.String(), secret)
+		assert.NotContains(t, string(report), secret)
+	}
+	assert.Contains(t, runErr.Error(), "****")
+	assert.Contains(t, string(report), "****")
+}
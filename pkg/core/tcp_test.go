@@ -0,0 +1,138 @@
+package core
+
+import (
+	"bufio"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startInventory starts a line-protocol service that answers "RESERVE sku-<n>"
+// with "OK id=<n>7" and "STATUS <id>" with "RESERVED <id>", and returns its host:port
+func startInventory(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					command, arg, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+					switch command {
+					case "RESERVE":
+						_, _ = conn.Write([]byte("OK id=" + strings.TrimPrefix(arg, "sku-") + "7\r\n"))
+					case "STATUS":
+						_, _ = conn.Write([]byte("RESERVED " + arg + "\r\n"))
+					default:
+						_, _ = conn.Write([]byte("500 unknown command\r\n"))
+					}
+				}
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// TestRunner_TCPStages tests line-protocol exchanges passing a saved value between stages
+func TestRunner_TCPStages(t *testing.T) {
+	tmpDir := t.TempDir()
+	testPath := filepath.Join(tmpDir, "test_tcp.tavern.yaml")
+	require.NoError(t, os.WriteFile(testPath, []byte(`
+test_name: Inventory
+stages:
+  - name: reserve
+    tcp:
+      host: "{inventory}"
+      send: "RESERVE sku-{sku}\n"
+      until: "\r\n"
+      reply:
+        matches: "^OK id=[0-9]+$"
+      save:
+        reply:
+          reservation: "id=(\\d+)"
+
+  - name: status
+    tcp:
+      host: "{inventory}"
+      send: "STATUS {reservation}\n"
+      until: "\r\n"
+      reply:
+        equals: "RESERVED {reservation}"
+
+  - name: base64 exchange
+    tcp:
+      host: "{inventory}"
+      encoding: base64
+      send: Tk9PUAo=
+      until: DQo=
+      reply:
+        equals: NTAwIHVua25vd24gY29tbWFuZA==
+`), 0644))
+
+	runner, err := NewRunner(&Config{BaseDir: tmpDir})
+	require.NoError(t, err)
+	runner.SetVariable("inventory", startInventory(t))
+	runner.SetVariable("sku", 4)
+
+	require.NoError(t, runner.RunFile(testPath))
+}
+
+// TestRunner_TCPFailures tests replies that don't match, timeouts and invalid tcp stages
+func TestRunner_TCPFailures(t *testing.T) {
+	inventory := startInventory(t)
+	tmpDir := t.TempDir()
+
+	tests := map[string]struct {
+		stages string
+		err    string
+	}{
+		"reply": {`
+  - name: unknown
+    tcp:
+      host: "` + inventory + `"
+      send: "NOOP\n"
+      until: "\r\n"
+      reply:
+        equals: OK
+`, "reply: expected 'OK', got '500 unknown command'"},
+		"timeout": {`
+  - name: silent
+    tcp:
+      host: "` + inventory + `"
+      until: "\r\n"
+      timeout: 0.2
+`, `no delimiter "\r\n" in reply: timed out after 200ms`},
+		"until and bytes": {`
+  - name: both
+    tcp:
+      host: "` + inventory + `"
+      until: "\r\n"
+      bytes: 4
+`, "stages.0.tcp"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testPath := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(name, " ", "_")+".tavern.yaml")
+			require.NoError(t, os.WriteFile(testPath, []byte("test_name: "+name+"\nstages:"+tt.stages), 0644))
+
+			runner, err := NewRunner(&Config{BaseDir: tmpDir})
+			require.NoError(t, err)
+			err = runner.RunFile(testPath)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
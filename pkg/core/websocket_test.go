@@ -0,0 +1,179 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// startNotifications starts a server with a login endpoint setting a session
+// cookie, and a websocket endpoint that needs it. After a subscribe message it
+// sends a heartbeat and a subscription id, and answers {"ack": id} with "acked".
+func startNotifications(t *testing.T) string {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login", func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "s-1", Path: "/"})
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/notifications", func(w http.ResponseWriter, r *http.Request) {
+		if cookie, err := r.Cookie("session"); err != nil || cookie.Value != "s-1" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var msg map[string]interface{}
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg["type"] == "subscribe" {
+				_ = conn.WriteJSON(map[string]interface{}{"type": "heartbeat"})
+				_ = conn.WriteJSON(map[string]interface{}{"type": "subscribed", "channel": msg["channel"], "id": "sub-1"})
+			} else if msg["ack"] == "sub-1" {
+				_ = conn.WriteMessage(websocket.TextMessage, []byte("acked"))
+			}
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+// TestRunner_WebSocketStages tests a connection kept open across stages, using the session cookie
+func TestRunner_WebSocketStages(t *testing.T) {
+	tmpDir := t.TempDir()
+	testPath := filepath.Join(tmpDir, "test_websocket.tavern.yaml")
+	require.NoError(t, os.WriteFile(testPath, []byte(`
+test_name: Notifications
+stages:
+  - name: login
+    request:
+      url: "{host}/login"
+      method: POST
+    response:
+      status_code: 204
+
+  - name: subscribe
+    websocket:
+      connect:
+        url: "{ws_host}/notifications"
+      send:
+        - json:
+            type: subscribe
+            channel: orders
+      match: eventually
+      expect:
+        - json:
+            type: subscribed
+            channel: orders
+          save:
+            json:
+              sub_id: id
+
+  - name: acknowledge
+    websocket:
+      send:
+        - json:
+            ack: "{sub_id}"
+      expect:
+        - text: acked
+      close: true
+`), 0644))
+
+	host := startNotifications(t)
+	runner, err := NewRunner(&Config{BaseDir: tmpDir})
+	require.NoError(t, err)
+	runner.SetVariable("host", host)
+	runner.SetVariable("ws_host", "ws"+strings.TrimPrefix(host, "http"))
+
+	require.NoError(t, runner.RunFile(testPath))
+}
+
+// TestRunner_WebSocketFailures tests stages without a connection and unmet expectations
+func TestRunner_WebSocketFailures(t *testing.T) {
+	host := startNotifications(t)
+	wsHost := "ws" + strings.TrimPrefix(host, "http")
+	tmpDir := t.TempDir()
+
+	tests := map[string]struct {
+		stages string
+		err    string
+	}{
+		"no connection": {`
+  - name: send
+    websocket:
+      send:
+        - text: hi
+`, "no open websocket connection"},
+		"no cookie": {`
+  - name: connect
+    websocket:
+      connect:
+        url: "` + wsHost + `/notifications"
+`, "status 401"},
+		"ordered": {`
+  - name: login
+    request:
+      url: "` + host + `/login"
+    response:
+      status_code: 204
+  - name: subscribe
+    websocket:
+      connect:
+        url: "` + wsHost + `/notifications"
+      send:
+        - json: {type: subscribe, channel: orders}
+      expect:
+        - json: {type: subscribed}
+`, "messages[0].type"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			testPath := filepath.Join(tmpDir, "test_"+strings.ReplaceAll(name, " ", "_")+".tavern.yaml")
+			require.NoError(t, os.WriteFile(testPath, []byte("test_name: "+name+"\nstages:"+tt.stages), 0644))
+
+			runner, err := NewRunner(&Config{BaseDir: tmpDir})
+			require.NoError(t, err)
+			err = runner.RunFile(testPath)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+// TestRunner_WebSocketSchema tests the schema of websocket stages
+func TestRunner_WebSocketSchema(t *testing.T) {
+	tmpDir := t.TempDir()
+	testPath := filepath.Join(tmpDir, "test_websocket_schema.tavern.yaml")
+	require.NoError(t, os.WriteFile(testPath, []byte(`
+test_name: Invalid match
+stages:
+  - name: subscribe
+    websocket:
+      connect:
+        url: ws://localhost:1/notifications
+      match: sometimes
+`), 0644))
+
+	runner, err := NewRunner(&Config{BaseDir: tmpDir})
+	require.NoError(t, err)
+
+	err = runner.RunFile(testPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `websocket.match must be one of the following: "ordered", "eventually"`)
+}
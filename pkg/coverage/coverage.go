@@ -0,0 +1,257 @@
+// Package coverage reports which operations of an OpenAPI document a test run exercised
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/openapi"
+)
+
+// Call is a single request made by the suite, built from its tavern.request_vars
+type Call struct {
+	Method  string
+	URL     string
+	Status  int                    // Response status code, 0 if no response was received
+	Params  map[string]interface{} // Query parameters
+	Headers map[string]interface{} // Request headers
+}
+
+// CallFromRequestVars builds a call from request_vars and the response status
+func CallFromRequestVars(requestVars map[string]interface{}, status int) Call {
+	call := Call{Status: status}
+	call.Method, _ = requestVars["method"].(string)
+	call.URL, _ = requestVars["url"].(string)
+	call.Params, _ = requestVars["params"].(map[string]interface{})
+	call.Headers, _ = requestVars["headers"].(map[string]interface{})
+	return call
+}
+
+// Report is the coverage of an OpenAPI document by a set of calls
+type Report struct {
+	Operations []*OperationCoverage `json:"operations"`
+	Unmatched  []string             `json:"unmatched,omitempty"` // Calls that match no operation, as "METHOD path"
+	Summary    Summary              `json:"summary"`
+}
+
+// OperationCoverage is the coverage of a single operation
+type OperationCoverage struct {
+	Method      string               `json:"method"`
+	Path        string               `json:"path"`
+	OperationID string               `json:"operation_id,omitempty"`
+	Calls       int                  `json:"calls"`
+	Statuses    []*StatusCoverage    `json:"statuses"`
+	Parameters  []*ParameterCoverage `json:"parameters,omitempty"`
+}
+
+// StatusCoverage records whether a documented response was seen
+type StatusCoverage struct {
+	Status  string `json:"status"` // Documented key, e.g. "200", "4XX" or "default"
+	Covered bool   `json:"covered"`
+}
+
+// ParameterCoverage records whether a query or header parameter was ever sent
+type ParameterCoverage struct {
+	Name    string `json:"name"`
+	In      string `json:"in"`
+	Covered bool   `json:"covered"`
+}
+
+// Summary holds covered/total counts for each dimension
+type Summary struct {
+	Operations Ratio `json:"operations"`
+	Statuses   Ratio `json:"statuses"`
+	Parameters Ratio `json:"parameters"`
+}
+
+// Ratio is a covered/total count
+type Ratio struct {
+	Covered int     `json:"covered"`
+	Total   int     `json:"total"`
+	Percent float64 `json:"percent"`
+}
+
+// Covered reports whether the operation was called at least once
+func (o *OperationCoverage) Covered() bool {
+	return o.Calls > 0
+}
+
+// Build computes the coverage of spec by calls
+func Build(spec *openapi.Spec, calls []Call) *Report {
+	report := &Report{}
+	byOperation := make(map[*openapi.Operation]*OperationCoverage, len(spec.Operations))
+
+	for _, op := range spec.Operations {
+		cov := &OperationCoverage{
+			Method:      op.Method,
+			Path:        op.Path,
+			OperationID: op.OperationID,
+		}
+		statuses := make([]string, 0, len(op.Responses))
+		for status := range op.Responses {
+			statuses = append(statuses, status)
+		}
+		sort.Strings(statuses)
+		for _, status := range statuses {
+			cov.Statuses = append(cov.Statuses, &StatusCoverage{Status: status})
+		}
+		// Path parameters are exercised by every call, so only query and header parameters are tracked
+		for _, p := range op.Parameters {
+			if p.In == "query" || p.In == "header" {
+				cov.Parameters = append(cov.Parameters, &ParameterCoverage{Name: p.Name, In: p.In})
+			}
+		}
+		report.Operations = append(report.Operations, cov)
+		byOperation[op] = cov
+	}
+
+	unmatched := map[string]bool{}
+	for _, call := range calls {
+		path := call.URL
+		if u, err := url.Parse(call.URL); err == nil {
+			path = u.Path
+		}
+		op := spec.FindOperation(call.Method, path)
+		if op == nil {
+			unmatched[strings.ToUpper(call.Method)+" "+path] = true
+			continue
+		}
+		cov := byOperation[op]
+		cov.Calls++
+		cov.markStatus(op, call.Status)
+		cov.markParameters(call)
+	}
+
+	for call := range unmatched {
+		report.Unmatched = append(report.Unmatched, call)
+	}
+	sort.Strings(report.Unmatched)
+
+	report.Summary = report.summarize()
+	return report
+}
+
+// markStatus marks the documented response that a status code resolves to
+func (o *OperationCoverage) markStatus(op *openapi.Operation, status int) {
+	if status == 0 {
+		return
+	}
+	key := ""
+	for _, candidate := range []string{strconv.Itoa(status), fmt.Sprintf("%dXX", status/100), fmt.Sprintf("%dxx", status/100), "default"} {
+		if _, ok := op.Responses[candidate]; ok {
+			key = candidate
+			break
+		}
+	}
+	for _, s := range o.Statuses {
+		if s.Status == key {
+			s.Covered = true
+		}
+	}
+}
+
+// markParameters marks the query and header parameters sent by a call
+func (o *OperationCoverage) markParameters(call Call) {
+	for _, p := range o.Parameters {
+		switch p.In {
+		case "query":
+			if _, ok := call.Params[p.Name]; ok {
+				p.Covered = true
+			}
+		case "header":
+			if _, ok := call.Headers[http.CanonicalHeaderKey(p.Name)]; ok {
+				p.Covered = true
+			}
+		}
+	}
+}
+
+// summarize counts covered operations, statuses and parameters
+func (r *Report) summarize() Summary {
+	var s Summary
+	for _, op := range r.Operations {
+		s.Operations.add(op.Covered())
+		for _, status := range op.Statuses {
+			s.Statuses.add(status.Covered)
+		}
+		for _, p := range op.Parameters {
+			s.Parameters.add(p.Covered)
+		}
+	}
+	s.Operations.finish()
+	s.Statuses.finish()
+	s.Parameters.finish()
+	return s
+}
+
+func (r *Ratio) add(covered bool) {
+	r.Total++
+	if covered {
+		r.Covered++
+	}
+}
+
+func (r *Ratio) finish() {
+	if r.Total == 0 {
+		r.Percent = 100
+		return
+	}
+	r.Percent = float64(r.Covered) * 100 / float64(r.Total)
+}
+
+// String formats the ratio as "3/4 (75.0%)"
+func (r Ratio) String() string {
+	return fmt.Sprintf("%d/%d (%.1f%%)", r.Covered, r.Total, r.Percent)
+}
+
+// WriteText writes a human readable report
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("API coverage\n")
+	for _, op := range r.Operations {
+		mark := "✗"
+		if op.Covered() {
+			mark = "✓"
+		}
+		fmt.Fprintf(&b, "  %s %-7s %s (%d calls)\n", mark, op.Method, op.Path, op.Calls)
+
+		var missing []string
+		for _, s := range op.Statuses {
+			if !s.Covered {
+				missing = append(missing, s.Status)
+			}
+		}
+		if len(missing) > 0 {
+			fmt.Fprintf(&b, "      untested statuses: %s\n", strings.Join(missing, ", "))
+		}
+
+		missing = nil
+		for _, p := range op.Parameters {
+			if !p.Covered {
+				missing = append(missing, p.Name+" ("+p.In+")")
+			}
+		}
+		if len(missing) > 0 {
+			fmt.Fprintf(&b, "      untested parameters: %s\n", strings.Join(missing, ", "))
+		}
+	}
+
+	if len(r.Unmatched) > 0 {
+		b.WriteString("Requests matching no operation:\n")
+		for _, call := range r.Unmatched {
+			fmt.Fprintf(&b, "  %s\n", call)
+		}
+	}
+
+	fmt.Fprintf(&b, "Operations: %s\n", r.Summary.Operations)
+	fmt.Fprintf(&b, "Statuses:   %s\n", r.Summary.Statuses)
+	fmt.Fprintf(&b, "Parameters: %s\n", r.Summary.Parameters)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
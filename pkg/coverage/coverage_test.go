@@ -0,0 +1,93 @@
+package coverage
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/openapi"
+)
+
+const spec = `
+openapi: 3.0.3
+info: {title: Items, version: "1"}
+servers:
+  - url: http://localhost/api
+paths:
+  /items:
+    get:
+      parameters:
+        - {name: limit, in: query, schema: {type: integer}}
+        - {name: X-Trace, in: header, schema: {type: string}}
+      responses:
+        "200": {description: ok}
+    post:
+      responses:
+        "201": {description: created}
+        "4XX": {description: invalid}
+  /items/{id}:
+    delete:
+      responses:
+        "204": {description: deleted}
+        default: {description: error}
+`
+
+// loadSpec parses the test document
+func loadSpec(t *testing.T) *openapi.Spec {
+	s, err := openapi.Parse([]byte(spec))
+	require.NoError(t, err)
+	return s
+}
+
+// TestBuild tests operation, status and parameter coverage
+func TestBuild(t *testing.T) {
+	calls := []Call{
+		CallFromRequestVars(map[string]interface{}{
+			"method": "GET",
+			"url":    "http://localhost/api/items?limit=5",
+			"params": map[string]interface{}{"limit": "5"},
+		}, 200),
+		{Method: "POST", URL: "/items", Status: 422},
+		{Method: "POST", URL: "/items", Status: 0},
+		{Method: "GET", URL: "http://localhost/api/health", Status: 200},
+	}
+
+	report := Build(loadSpec(t), calls)
+	require.Len(t, report.Operations, 3)
+
+	get := report.Operations[0]
+	assert.Equal(t, "GET", get.Method)
+	assert.Equal(t, 1, get.Calls)
+	assert.True(t, get.Statuses[0].Covered)
+	assert.True(t, get.Parameters[0].Covered, "limit was sent")
+	assert.False(t, get.Parameters[1].Covered, "X-Trace was never sent")
+
+	post := report.Operations[1]
+	assert.Equal(t, 2, post.Calls)
+	assert.Equal(t, "201", post.Statuses[0].Status)
+	assert.False(t, post.Statuses[0].Covered)
+	assert.True(t, post.Statuses[1].Covered, "422 is covered by 4XX")
+
+	assert.False(t, report.Operations[2].Covered())
+	assert.Equal(t, []string{"GET /api/health"}, report.Unmatched)
+
+	assert.Equal(t, Ratio{Covered: 2, Total: 3, Percent: 200.0 / 3}, report.Summary.Operations)
+	assert.Equal(t, 2, report.Summary.Statuses.Covered)
+	assert.Equal(t, 5, report.Summary.Statuses.Total)
+	assert.Equal(t, "1/2 (50.0%)", report.Summary.Parameters.String())
+}
+
+// TestReport_WriteText tests the human readable report
+func TestReport_WriteText(t *testing.T) {
+	report := Build(loadSpec(t), []Call{{Method: "DELETE", URL: "/api/items/1", Status: 500}})
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteText(&buf))
+	out := buf.String()
+	assert.Contains(t, out, "✓ DELETE  /items/{id} (1 calls)")
+	assert.Contains(t, out, "untested statuses: 204")
+	assert.Contains(t, out, "✗ GET     /items (0 calls)")
+	assert.Contains(t, out, "untested parameters: limit (query), X-Trace (header)")
+	assert.Contains(t, out, "Operations: 1/3 (33.3%)")
+}
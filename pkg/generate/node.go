@@ -0,0 +1,107 @@
+// Package generate writes tavern test files from other formats (OpenAPI, Postman, recorded traffic)
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+
+	yamlpkg "github.com/systemquest/tavern-go/pkg/yaml"
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// Pair is a key and value of a mapping, in output order
+type Pair struct {
+	Key   string
+	Value *goyaml.Node
+}
+
+// Mapping returns a mapping node with keys in the given order
+func Mapping(pairs ...Pair) *goyaml.Node {
+	node := &goyaml.Node{Kind: goyaml.MappingNode, Tag: "!!map"}
+	for _, p := range pairs {
+		if p.Value == nil {
+			continue
+		}
+		node.Content = append(node.Content, String(p.Key), p.Value)
+	}
+	return node
+}
+
+// Sequence returns a sequence node
+func Sequence(items ...*goyaml.Node) *goyaml.Node {
+	return &goyaml.Node{Kind: goyaml.SequenceNode, Tag: "!!seq", Content: items}
+}
+
+// String returns a string scalar node
+func String(s string) *goyaml.Node {
+	return &goyaml.Node{Kind: goyaml.ScalarNode, Tag: "!!str", Value: s}
+}
+
+// Int returns an integer scalar node
+func Int(i int) *goyaml.Node {
+	return &goyaml.Node{Kind: goyaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(i)}
+}
+
+// Tagged returns a scalar with a custom tag such as !anyint
+func Tagged(tag, value string) *goyaml.Node {
+	return &goyaml.Node{Kind: goyaml.ScalarNode, Tag: tag, Value: value}
+}
+
+// Value converts a Go value (as decoded from JSON or YAML) into a node
+// Map keys are sorted so output is deterministic
+func Value(v interface{}) *goyaml.Node {
+	switch val := v.(type) {
+	case nil:
+		return &goyaml.Node{Kind: goyaml.ScalarNode, Tag: "!!null", Value: "null"}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		pairs := make([]Pair, 0, len(keys))
+		for _, k := range keys {
+			pairs = append(pairs, Pair{k, Value(val[k])})
+		}
+		return Mapping(pairs...)
+	case []interface{}:
+		items := make([]*goyaml.Node, 0, len(val))
+		for _, item := range val {
+			items = append(items, Value(item))
+		}
+		return Sequence(items...)
+	case *goyaml.Node:
+		return val
+	default:
+		node := &goyaml.Node{}
+		if err := node.Encode(val); err != nil {
+			return String(fmt.Sprintf("%v", val))
+		}
+		return node
+	}
+}
+
+// WithComment attaches a head comment to a node
+func WithComment(node *goyaml.Node, comment string) *goyaml.Node {
+	node.HeadComment = comment
+	return node
+}
+
+// Encode writes nodes as a multi-document YAML stream in the canonical layout
+// of tavern fmt, so generated files pass fmt --check
+func Encode(documents ...*goyaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := goyaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	for _, doc := range documents {
+		if err := encoder.Encode(doc); err != nil {
+			return nil, err
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return yamlpkg.Format(buf.Bytes())
+}
@@ -0,0 +1,185 @@
+package generate
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/openapi"
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// File is a generated test file
+type File struct {
+	Name    string // File name relative to the output directory
+	Content []byte
+}
+
+// maxAssertionDepth limits how deep body assertions are generated
+const maxAssertionDepth = 4
+
+// FromOpenAPI generates one test file per operation of an OpenAPI document
+// Requests are filled from example values, path parameters become include
+// variables, and response bodies assert the types of required fields
+func FromOpenAPI(spec *openapi.Spec) ([]File, error) {
+	baseURL := spec.ServerURL()
+	if baseURL == "" || !strings.Contains(baseURL, "://") {
+		baseURL = "http://localhost:8080" + baseURL
+	}
+	baseURL = strings.TrimRight(baseURL, "/")
+
+	var files []File
+	used := make(map[string]int)
+	for _, op := range spec.Operations {
+		name := op.Name()
+		used[name]++
+		if used[name] > 1 {
+			name = fmt.Sprintf("%s_%d", name, used[name])
+		}
+
+		content, err := Encode(openAPITest(spec, op, baseURL))
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", op.Method, op.Path, err)
+		}
+		files = append(files, File{Name: "test_" + name + ".tavern.yaml", Content: content})
+	}
+
+	return files, nil
+}
+
+// openAPITest builds the test document for an operation
+func openAPITest(spec *openapi.Spec, op *openapi.Operation, baseURL string) *goyaml.Node {
+	testName := op.Method + " " + op.Path
+	stageName := op.Summary
+	if stageName == "" {
+		stageName = testName
+	}
+
+	// Path parameters become include variables so they are easy to change
+	variables := []Pair{{"base_url", String(baseURL)}}
+	params := map[string]interface{}{}
+	headers := map[string]interface{}{}
+	for _, p := range op.Parameters {
+		value := p.Example
+		if value == nil {
+			value = openapi.Example(resolvedSchema(spec, p.Schema))
+		}
+		switch p.In {
+		case "path":
+			variables = append(variables, Pair{p.Name, Value(value)})
+		case "query":
+			if p.Required {
+				params[p.Name] = fmt.Sprintf("%v", value)
+			}
+		case "header":
+			if p.Required {
+				headers[p.Name] = fmt.Sprintf("%v", value)
+			}
+		}
+	}
+
+	request := []Pair{
+		{"url", String("{base_url}" + op.Path)},
+		{"method", String(op.Method)},
+	}
+	if len(headers) > 0 {
+		request = append(request, Pair{"headers", Value(headers)})
+	}
+	if len(params) > 0 {
+		request = append(request, Pair{"params", Value(params)})
+	}
+	if op.RequestBody != nil {
+		if schema := openapi.JSONSchema(op.RequestBody); schema != nil {
+			request = append(request, Pair{"json", Value(openapi.Example(resolvedSchema(spec, schema)))})
+		}
+	}
+
+	status := op.SuccessStatus()
+	response := []Pair{{"status_code", Int(status)}}
+	if resp, ok := op.Response(status); ok {
+		if schema := openapi.JSONSchema(resp); schema != nil {
+			if body := bodyAssertions(resolvedSchema(spec, schema), 0); body != nil {
+				response = append(response, Pair{"body", body})
+			}
+		}
+	}
+
+	test := Mapping(
+		Pair{"test_name", String(testName)},
+		Pair{"includes", Sequence(Mapping(
+			Pair{"name", String("variables")},
+			Pair{"description", String("Base URL and path parameters")},
+			Pair{"variables", Mapping(variables...)},
+		))},
+		Pair{"stages", Sequence(Mapping(
+			Pair{"name", String(stageName)},
+			Pair{"request", Mapping(request...)},
+			Pair{"response", Mapping(response...)},
+		))},
+	)
+	comment := fmt.Sprintf("Generated from OpenAPI operation %s %s", op.Method, op.Path)
+	if op.OperationID != "" {
+		comment += " (" + op.OperationID + ")"
+	}
+	return WithComment(test, comment)
+}
+
+// resolvedSchema resolves all $refs of a schema
+func resolvedSchema(spec *openapi.Spec, schema map[string]interface{}) map[string]interface{} {
+	resolved, _ := spec.ResolveDeep(schema).(map[string]interface{})
+	return resolved
+}
+
+// bodyAssertions builds type assertions for the required fields of an object schema
+// Non-object schemas and objects without required fields return nil
+func bodyAssertions(schema map[string]interface{}, depth int) *goyaml.Node {
+	if schema == nil || depth >= maxAssertionDepth {
+		return nil
+	}
+	schema = openapi.Flatten(schema)
+	if openapi.SchemaType(schema) != "object" {
+		return nil
+	}
+
+	required := openapi.RequiredProperties(schema)
+	if len(required) == 0 {
+		return nil
+	}
+	sort.Strings(required)
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	var pairs []Pair
+	for _, name := range required {
+		propSchema, _ := properties[name].(map[string]interface{})
+		pairs = append(pairs, Pair{name, typeAssertion(propSchema, depth)})
+	}
+	return Mapping(pairs...)
+}
+
+// typeAssertion returns the tavern tag matching a property's type
+func typeAssertion(schema map[string]interface{}, depth int) *goyaml.Node {
+	if schema == nil {
+		return Tagged("!anything", "")
+	}
+	schema = openapi.Flatten(schema)
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		return Tagged("!anything", "")
+	}
+
+	switch openapi.SchemaType(schema) {
+	case "integer":
+		return Tagged("!anyint", "")
+	case "number":
+		return Tagged("!anyfloat", "")
+	case "string":
+		return Tagged("!anystr", "")
+	case "boolean":
+		return Tagged("!anybool", "")
+	case "object":
+		if nested := bodyAssertions(schema, depth+1); nested != nil {
+			return nested
+		}
+	}
+	// Arrays are compared element by element, so their contents are left open
+	return Tagged("!anything", "")
+}
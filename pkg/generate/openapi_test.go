@@ -0,0 +1,78 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/openapi"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	yamlpkg "github.com/systemquest/tavern-go/pkg/yaml"
+)
+
+// TestFromOpenAPI tests that generated files are valid tests with examples and type assertions
+func TestFromOpenAPI(t *testing.T) {
+	spec, err := openapi.Load("../openapi/testdata/users.yaml")
+	require.NoError(t, err)
+
+	files, err := FromOpenAPI(spec)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "test_create_user.tavern.yaml", files[0].Name)
+	assert.Equal(t, "test_get_user.tavern.yaml", files[1].Name)
+
+	validator, err := schema.NewValidator()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	var tests []*schema.TestSpec
+	for _, file := range files {
+		path := filepath.Join(dir, file.Name)
+		require.NoError(t, os.WriteFile(path, file.Content, 0644))
+
+		formatted, err := yamlpkg.Format(file.Content)
+		require.NoError(t, err)
+		assert.Equal(t, string(formatted), string(file.Content), "%s should pass tavern fmt --check", file.Name)
+
+		loaded, err := yamlpkg.NewLoader(dir).Load(path)
+		require.NoError(t, err)
+		require.Len(t, loaded, 1)
+		require.NoError(t, validator.Validate(loaded[0]))
+		tests = append(tests, loaded[0])
+	}
+
+	create := tests[0]
+	assert.Equal(t, "POST /users", create.TestName)
+	assert.Equal(t, "https://api.example.com/v1", create.Includes[0].Variables["base_url"])
+	stage := create.Stages[0]
+	assert.Equal(t, "Create a user", stage.Name)
+	assert.Equal(t, "{base_url}/users", stage.Request.URL)
+	assert.Equal(t, map[string]interface{}{"name": "Alice", "email": "user@example.com"}, stage.Request.JSON)
+	assert.Equal(t, 201, stage.Response.StatusCode.Single)
+
+	body := stage.Response.Body.(map[string]interface{})
+	assert.Equal(t, "<<INT>>", body["id"])
+	assert.Equal(t, "<<STR>>", body["name"])
+	assert.Equal(t, "<<ANYTHING>>", body["tags"])
+	assert.Equal(t, map[string]interface{}{"verified": "<<BOOL>>"}, body["profile"])
+
+	get := tests[1]
+	assert.Equal(t, 42, get.Includes[0].Variables["id"])
+	assert.Equal(t, "{base_url}/users/{id}", get.Stages[0].Request.URL)
+	assert.Equal(t, map[string]string{"expand": "profile"}, get.Stages[0].Request.Params)
+}
+
+// TestEncode_Tags tests that custom tags and comments are written in fmt's layout
+func TestEncode_Tags(t *testing.T) {
+	doc := WithComment(Mapping(
+		Pair{"id", Tagged("!anyint", "")},
+		Pair{"skipped", nil},
+		Pair{"items", Value([]interface{}{1, "two"})},
+	), "Generated")
+
+	data, err := Encode(doc)
+	require.NoError(t, err)
+	assert.Equal(t, "# Generated\nid: !anyint\n\nitems:\n  - 1\n  - two\n", string(data))
+}
@@ -0,0 +1,436 @@
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/postman"
+	"github.com/systemquest/tavern-go/pkg/util"
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// postmanFolder is a folder (or the collection root) with its direct requests
+// and the auth and scripts it passes on to them
+type postmanFolder struct {
+	path     []string
+	requests []*postman.Item
+	auth     *postman.Auth
+	events   []postman.Event
+}
+
+// FromPostman generates one test file per folder of a Postman collection, with
+// one stage per request. Collection and environment variables become includes,
+// and simple pm.test status and JSON checks become response blocks. Anything
+// that cannot be converted is left as a TODO comment on the stage.
+func FromPostman(collection *postman.Collection, env *postman.Environment) ([]File, error) {
+	var folders []*postmanFolder
+	collectFolders(&folders, nil, collection.Item, collection.Auth, collection.Event)
+
+	includes := postmanIncludes(collection, env)
+
+	var files []File
+	used := make(map[string]int)
+	for _, folder := range folders {
+		testName := collection.Info.Name
+		if len(folder.path) > 0 {
+			testName = strings.Join(folder.path, " / ")
+		}
+
+		name := util.SnakeCase(testName)
+		if name == "" {
+			name = "postman"
+		}
+		used[name]++
+		if used[name] > 1 {
+			name = fmt.Sprintf("%s_%d", name, used[name])
+		}
+
+		content, err := Encode(postmanTest(collection, folder, testName, includes))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", testName, err)
+		}
+		files = append(files, File{Name: "test_" + name + ".tavern.yaml", Content: content})
+	}
+
+	return files, nil
+}
+
+// PostmanDynamicVariables lists the dynamic variables, such as {{$guid}}, used
+// by variables, requests (with the auth they inherit) and test scripts, as
+// "<where>: {{$name}}". FromPostman leaves them as TODO comments.
+func PostmanDynamicVariables(collection *postman.Collection, env *postman.Environment) []string {
+	var folders []*postmanFolder
+	collectFolders(&folders, nil, collection.Item, collection.Auth, collection.Event)
+
+	var uses []string
+	find := func(where string, v interface{}) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+		_, dynamic := postman.ConvertVariables(string(data))
+		for _, name := range dynamic {
+			uses = append(uses, fmt.Sprintf("%s: {{%s}}", where, name))
+		}
+	}
+
+	for _, v := range collection.Variable {
+		if v.Active() {
+			find(fmt.Sprintf("collection variable %q", v.Key), v.Value)
+		}
+	}
+	if env != nil {
+		for _, v := range env.Values {
+			if v.Active() {
+				find(fmt.Sprintf("environment variable %q", v.Key), v.Value)
+			}
+		}
+	}
+	for _, folder := range folders {
+		for _, item := range folder.requests {
+			where := fmt.Sprintf("request %q", item.Name)
+			find(where, item.Request)
+			if item.Request.Auth == nil && item.Auth == nil && folder.auth != nil {
+				find(where, folder.auth)
+			}
+			events := append(append([]postman.Event{}, folder.events...), item.Event...)
+			find(where, postman.Scripts(events, "test"))
+		}
+	}
+	return uses
+}
+
+// collectFolders walks the item tree in order, recording every folder with direct requests
+func collectFolders(folders *[]*postmanFolder, path []string, items []*postman.Item, auth *postman.Auth, events []postman.Event) {
+	folder := &postmanFolder{path: path, auth: auth, events: events}
+	for _, item := range items {
+		if !item.IsFolder() {
+			folder.requests = append(folder.requests, item)
+		}
+	}
+	if len(folder.requests) > 0 {
+		*folders = append(*folders, folder)
+	}
+
+	for _, item := range items {
+		if !item.IsFolder() {
+			continue
+		}
+		childAuth := auth
+		if item.Auth != nil {
+			childAuth = item.Auth
+		}
+		childPath := append(append([]string{}, path...), item.Name)
+		childEvents := append(append([]postman.Event{}, events...), item.Event...)
+		collectFolders(folders, childPath, item.Item, childAuth, childEvents)
+	}
+}
+
+// postmanIncludes turns collection and environment variables into includes
+func postmanIncludes(collection *postman.Collection, env *postman.Environment) []*goyaml.Node {
+	var includes []*goyaml.Node
+	if vars := postmanVariables(collection.Variable); vars != nil {
+		includes = append(includes, Mapping(
+			Pair{"name", String("collection variables")},
+			Pair{"description", String("Variables of Postman collection " + collection.Info.Name)},
+			Pair{"variables", vars},
+		))
+	}
+	if env != nil {
+		if vars := postmanVariables(env.Values); vars != nil {
+			includes = append(includes, Mapping(
+				Pair{"name", String("environment variables")},
+				Pair{"description", String("Variables of Postman environment " + env.Name)},
+				Pair{"variables", vars},
+			))
+		}
+	}
+	return includes
+}
+
+// postmanVariables converts active variables into a mapping, or nil if there are none
+func postmanVariables(vars []postman.Variable) *goyaml.Node {
+	values := map[string]interface{}{}
+	for _, v := range vars {
+		if v.Key == "" || !v.Active() {
+			continue
+		}
+		value := v.Value
+		if s, ok := value.(string); ok {
+			value, _ = postman.ConvertVariables(s)
+		}
+		values[v.Key] = value
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	return Value(values)
+}
+
+// postmanTest builds the test document for a folder
+func postmanTest(collection *postman.Collection, folder *postmanFolder, testName string, includes []*goyaml.Node) *goyaml.Node {
+	stages := make([]*goyaml.Node, 0, len(folder.requests))
+	for _, item := range folder.requests {
+		stages = append(stages, postmanStage(item, folder))
+	}
+
+	pairs := []Pair{{"test_name", String(testName)}}
+	if len(includes) > 0 {
+		pairs = append(pairs, Pair{"includes", Sequence(includes...)})
+	}
+	pairs = append(pairs, Pair{"stages", Sequence(stages...)})
+
+	comment := fmt.Sprintf("Imported from Postman collection %q", collection.Info.Name)
+	if len(folder.path) > 0 {
+		comment += fmt.Sprintf(", folder %q", strings.Join(folder.path, "/"))
+	}
+	return WithComment(Mapping(pairs...), comment)
+}
+
+// postmanStage converts a request item into a stage
+func postmanStage(item *postman.Item, folder *postmanFolder) *goyaml.Node {
+	var todos []string
+	convert := func(s string) string {
+		converted, dynamic := postman.ConvertVariables(s)
+		for _, name := range dynamic {
+			todos = append(todos, fmt.Sprintf("replace Postman dynamic variable {{%s}}", name))
+		}
+		return converted
+	}
+
+	req := item.Request
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = "GET"
+	}
+	request := []Pair{
+		{"url", String(convert(req.URL.Raw))},
+		{"method", String(method)},
+	}
+
+	headers := map[string]interface{}{}
+	for _, h := range req.Header {
+		if !h.Disabled && h.Key != "" {
+			headers[h.Key] = convert(h.String())
+		}
+	}
+
+	auth := folder.auth
+	if item.Auth != nil {
+		auth = item.Auth
+	}
+	if req.Auth != nil {
+		auth = req.Auth
+	}
+	var authNode *goyaml.Node
+	if auth != nil {
+		switch auth.Type {
+		case "noauth", "":
+		case "bearer":
+			headers["Authorization"] = "Bearer " + convert(auth.Attribute("token"))
+		case "basic":
+			authNode = Mapping(
+				Pair{"type", String("basic")},
+				Pair{"username", String(convert(auth.Attribute("username")))},
+				Pair{"password", String(convert(auth.Attribute("password")))},
+			)
+		default:
+			todos = append(todos, fmt.Sprintf("configure Postman %q auth", auth.Type))
+		}
+	}
+	if len(headers) > 0 {
+		request = append(request, Pair{"headers", Value(headers)})
+	}
+	if authNode != nil {
+		request = append(request, Pair{"auth", authNode})
+	}
+
+	if body := req.Body; body != nil {
+		switch body.Mode {
+		case "raw":
+			if strings.TrimSpace(body.Raw) == "" {
+				break
+			}
+			raw, quoted := postman.QuoteBareVariables(body.Raw)
+			var data interface{}
+			if err := json.Unmarshal([]byte(raw), &data); err == nil {
+				request = append(request, Pair{"json", Value(convertValue(data, convert))})
+				for _, name := range quoted {
+					todos = append(todos, fmt.Sprintf("{%s} was unquoted in the Postman body and is now sent as a string; tag it with !int, !float or !bool if needed", name))
+				}
+			} else {
+				request = append(request, Pair{"data", String(convert(body.Raw))})
+				todos = append(todos, "raw body is not JSON; check that braces in it are not taken for tavern variables")
+			}
+		case "urlencoded":
+			form := map[string]interface{}{}
+			for _, kv := range body.URLEncoded {
+				if !kv.Disabled {
+					form[kv.Key] = convert(kv.String())
+				}
+			}
+			request = append(request, Pair{"data", Value(form)})
+		case "formdata":
+			form := map[string]interface{}{}
+			for _, kv := range body.FormData {
+				if kv.Disabled {
+					continue
+				}
+				if kv.Type == "file" {
+					todos = append(todos, fmt.Sprintf("upload file field %q with request.files", kv.Key))
+					continue
+				}
+				form[kv.Key] = convert(kv.String())
+			}
+			if len(form) > 0 {
+				request = append(request, Pair{"data", Value(form)})
+			}
+		default:
+			todos = append(todos, fmt.Sprintf("convert Postman %q request body", body.Mode))
+		}
+	}
+
+	events := append(append([]postman.Event{}, folder.events...), item.Event...)
+	if pre := nonEmpty(postman.Scripts(events, "prerequest")); len(pre) > 0 {
+		todos = append(todos, "port pre-request script:")
+		todos = append(todos, indent(pre)...)
+	}
+
+	script := postman.ParseTests(postman.Scripts(events, "test"))
+	response := postmanResponse(script)
+	if len(script.Unsupported) > 0 {
+		todos = append(todos, "port unsupported test script statements:")
+		todos = append(todos, indent(script.Unsupported)...)
+	}
+
+	stage := Mapping(
+		Pair{"name", String(item.Name)},
+		Pair{"request", Mapping(request...)},
+		Pair{"response", response},
+	)
+	if len(todos) > 0 {
+		lines := make([]string, len(todos))
+		for i, todo := range todos {
+			if strings.HasPrefix(todo, "  ") {
+				lines[i] = todo
+			} else {
+				lines[i] = "TODO: " + todo
+			}
+		}
+		WithComment(stage, strings.Join(lines, "\n"))
+	}
+	return stage
+}
+
+// postmanResponse builds the response block from a parsed test script
+func postmanResponse(script *postman.TestScript) *goyaml.Node {
+	status := Int(200)
+	codes := uniqueInts(script.Status)
+	switch {
+	case len(codes) == 1:
+		status = Int(codes[0])
+	case len(codes) > 1:
+		items := make([]*goyaml.Node, len(codes))
+		for i, code := range codes {
+			items[i] = Int(code)
+		}
+		status = Sequence(items...)
+	}
+	pairs := []Pair{{"status_code", status}}
+
+	if len(script.Body) > 0 {
+		var body []Pair
+		for _, check := range script.Body {
+			body = append(body, Pair{check.Path, bodyCheckNode(check)})
+		}
+		pairs = append(pairs, Pair{"body", Mapping(body...)})
+	}
+
+	if len(script.Saves) > 0 {
+		var saves []Pair
+		for _, save := range script.Saves {
+			saves = append(saves, Pair{save.Variable, String(save.Path)})
+		}
+		pairs = append(pairs, Pair{"save", Mapping(Pair{"body", Mapping(saves...)})})
+	}
+
+	return Mapping(pairs...)
+}
+
+// bodyCheckNode returns the expected value or type matcher of a body check
+func bodyCheckNode(check postman.BodyCheck) *goyaml.Node {
+	switch check.Kind {
+	case postman.CheckEquals:
+		if s, ok := check.Value.(string); ok {
+			converted, _ := postman.ConvertVariables(s)
+			return String(converted)
+		}
+		return Value(check.Value)
+	case postman.CheckType:
+		switch check.Type {
+		case "string":
+			return Tagged("!anystr", "")
+		case "number":
+			return Tagged("!anyfloat", "")
+		case "boolean":
+			return Tagged("!anybool", "")
+		}
+	}
+	return Tagged("!anything", "")
+}
+
+// convertValue converts Postman variable references in every string of a decoded JSON value
+func convertValue(v interface{}, convert func(string) string) interface{} {
+	switch val := v.(type) {
+	case string:
+		return convert(val)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			result[k] = convertValue(item, convert)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, item := range val {
+			result[i] = convertValue(item, convert)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// uniqueInts returns the distinct values in order of first appearance
+func uniqueInts(values []int) []int {
+	seen := map[int]bool{}
+	var result []int
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// nonEmpty drops blank lines
+func nonEmpty(lines []string) []string {
+	var result []string
+	for _, line := range lines {
+		if strings.TrimSpace(line) != "" {
+			result = append(result, line)
+		}
+	}
+	return result
+}
+
+// indent prefixes lines for nesting under a TODO comment
+func indent(lines []string) []string {
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = "  " + strings.TrimSpace(line)
+	}
+	return result
+}
@@ -0,0 +1,110 @@
+package generate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/postman"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	yamlpkg "github.com/systemquest/tavern-go/pkg/yaml"
+)
+
+// TestFromPostman tests that folders become valid test files with converted checks
+func TestFromPostman(t *testing.T) {
+	collection, err := postman.LoadCollection("../postman/testdata/collection.json")
+	require.NoError(t, err)
+	env, err := postman.LoadEnvironment("../postman/testdata/environment.json")
+	require.NoError(t, err)
+
+	files, err := FromPostman(collection, env)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, "test_shop_api.tavern.yaml", files[0].Name)
+	assert.Equal(t, "test_orders.tavern.yaml", files[1].Name)
+
+	validator, err := schema.NewValidator()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	var tests []*schema.TestSpec
+	for _, file := range files {
+		path := filepath.Join(dir, file.Name)
+		require.NoError(t, os.WriteFile(path, file.Content, 0644))
+
+		formatted, err := yamlpkg.Format(file.Content)
+		require.NoError(t, err)
+		assert.Equal(t, string(formatted), string(file.Content), "%s should pass tavern fmt --check", file.Name)
+
+		loaded, err := yamlpkg.NewLoader(dir).Load(path)
+		require.NoError(t, err)
+		require.Len(t, loaded, 1)
+		require.NoError(t, validator.Validate(loaded[0]))
+		tests = append(tests, loaded[0])
+	}
+
+	root := tests[0]
+	assert.Equal(t, "Shop API", root.TestName)
+	require.Len(t, root.Includes, 2)
+	assert.Equal(t, map[string]interface{}{"base_url": "http://localhost:8080"}, root.Includes[0].Variables)
+	assert.Equal(t, map[string]interface{}{"sku": "ABC-1", "quantity": "2"}, root.Includes[1].Variables)
+	assert.Equal(t, "{base_url}/health", root.Stages[0].Request.URL)
+	assert.Empty(t, root.Stages[0].Request.Headers, "noauth overrides the collection bearer auth")
+
+	orders := tests[1]
+	assert.Equal(t, "Orders", orders.TestName)
+	require.Len(t, orders.Stages, 2)
+
+	create := orders.Stages[0]
+	assert.Equal(t, "POST", create.Request.Method)
+	assert.Equal(t, map[string]string{
+		"Authorization": "Bearer {token}",
+		"Content-Type":  "application/json",
+	}, create.Request.Headers)
+	assert.Equal(t, "{sku}", create.Request.JSON.(map[string]interface{})["sku"])
+	assert.Equal(t, 201, create.Response.StatusCode.Single)
+	assert.Equal(t, map[string]interface{}{
+		"status":      "pending",
+		"items.0.sku": "{sku}",
+		"id":          "<<FLOAT>>",
+		"paid":        false,
+	}, create.Response.Body)
+	assert.Equal(t, map[string]interface{}{"order_id": "id"}, create.Response.Save.GetSpec().Body)
+
+	get := orders.Stages[1]
+	assert.Equal(t, "{base_url}/orders/{order_id}", get.Request.URL)
+	require.NotNil(t, get.Request.Auth)
+	assert.Equal(t, "admin", get.Request.Auth.Username)
+
+	// Unsupported parts are left as TODO comments
+	content := string(files[1].Content)
+	assert.Contains(t, content, "# TODO: port pre-request script:")
+	assert.Contains(t, content, "#   pm.expect(pm.response.responseTime).to.be.below(500);")
+	assert.Contains(t, content, "# TODO: replace Postman dynamic variable {{$guid}}")
+	assert.Contains(t, content, "# TODO: {quantity} was unquoted")
+}
+
+// TestPostmanDynamicVariables tests listing where dynamic variables such as {{$guid}} are used
+func TestPostmanDynamicVariables(t *testing.T) {
+	collection := &postman.Collection{
+		Info:     postman.Info{Name: "Shop"},
+		Variable: []postman.Variable{{Key: "stamp", Value: "{{$timestamp}}"}},
+		Item: []*postman.Item{{
+			Name: "Create order",
+			Request: &postman.Request{
+				Method: "POST",
+				URL:    postman.URL{Raw: "{{base_url}}/orders?ref={{$guid}}"},
+			},
+		}},
+	}
+	assert.Equal(t, []string{
+		`collection variable "stamp": {{$timestamp}}`,
+		`request "Create order": {{$guid}}`,
+	}, PostmanDynamicVariables(collection, nil))
+
+	collection.Variable = nil
+	collection.Item[0].Request.URL.Raw = "{{base_url}}/orders"
+	assert.Empty(t, PostmanDynamicVariables(collection, nil))
+}
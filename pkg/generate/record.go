@@ -0,0 +1,343 @@
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/record"
+	"github.com/systemquest/tavern-go/pkg/util"
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// Thresholds for correlating response values with later requests. Short words
+// and small numbers (flags, counts, page numbers) cause too many false matches.
+const (
+	minIDString      = 3  // Shortest string with a digit that is correlated, e.g. "u42"
+	minTokenString   = 8  // Shortest string correlated regardless of content, also inside longer strings such as "Bearer <token>"
+	minCorrelatedInt = 10 // Smallest integer correlated
+)
+
+// skippedRequestHeaders are not written to stages: the HTTP client sets them,
+// cookies are kept by the session, and the rest is browser or proxy noise
+var skippedRequestHeaders = map[string]bool{
+	"Accept-Encoding": true, "Accept-Language": true, "Connection": true, "Content-Length": true,
+	"Cookie": true, "Host": true, "Origin": true, "Referer": true, "User-Agent": true,
+	"X-Forwarded-For": true, "X-Forwarded-Host": true, "X-Forwarded-Proto": true, "Postman-Token": true,
+}
+
+// produced is a response value that later requests may reuse
+type produced struct {
+	stage    int
+	path     string // Dotted body path
+	value    interface{}
+	variable string // Assigned when first reused
+}
+
+// FromExchanges generates a test with one stage per recorded exchange.
+// Values from JSON response bodies that reappear in later requests are saved
+// and referenced as {variables}.
+func FromExchanges(testName, baseURL string, exchanges []record.Exchange) ([]byte, error) {
+	c := &correlator{used: map[string]int{}}
+	saves := make([][]*produced, len(exchanges))
+	stages := make([]*goyaml.Node, 0, len(exchanges))
+
+	for i, ex := range exchanges {
+		request := c.request(ex)
+		for _, p := range c.reused {
+			saves[p.stage] = append(saves[p.stage], p)
+		}
+		c.reused = nil
+
+		stages = append(stages, Mapping(
+			Pair{"name", String(ex.Method + " " + ex.Path)},
+			Pair{"request", Mapping(request...)},
+		))
+
+		if body, ok := jsonBody(ex.ResponseHeader, ex.ResponseBody); ok {
+			c.collect(i, "", body)
+		}
+	}
+
+	// Responses are built last, once it is known which of their values are saved
+	for i, ex := range exchanges {
+		stage := stages[i]
+		stage.Content = append(stage.Content, String("response"), recordedResponse(ex, saves[i]))
+	}
+
+	test := Mapping(
+		Pair{"test_name", String(testName)},
+		Pair{"includes", Sequence(Mapping(
+			Pair{"name", String("recording")},
+			Pair{"description", String("Upstream the traffic was recorded against")},
+			Pair{"variables", Mapping(Pair{"base_url", String(strings.TrimRight(baseURL, "/"))})},
+		))},
+		Pair{"stages", Sequence(stages...)},
+	)
+	return Encode(WithComment(test, fmt.Sprintf("Recorded with tavern record from %d exchange(s)", len(exchanges))))
+}
+
+// correlator tracks response values and replaces them in later requests
+type correlator struct {
+	values []*produced    // In order of production; later values win
+	reused []*produced    // Values reused by the request being built
+	used   map[string]int // Variable names in use
+}
+
+// collect records the scalar leaves of a response body
+func (c *correlator) collect(stage int, path string, v interface{}) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, k := range sortedKeys(val) {
+			c.collect(stage, joinPath(path, k), val[k])
+		}
+	case []interface{}:
+		for i, item := range val {
+			c.collect(stage, joinPath(path, strconv.Itoa(i)), item)
+		}
+	case string:
+		if len(val) >= minTokenString || (len(val) >= minIDString && strings.ContainsAny(val, "0123456789")) {
+			c.values = append(c.values, &produced{stage: stage, path: path, value: val})
+		}
+	case float64:
+		if val == float64(int64(val)) && (val >= minCorrelatedInt || val <= -minCorrelatedInt) {
+			c.values = append(c.values, &produced{stage: stage, path: path, value: val})
+		}
+	}
+}
+
+// lookup returns the most recent produced value that matches, marking it as reused
+func (c *correlator) lookup(match func(p *produced) bool) *produced {
+	for i := len(c.values) - 1; i >= 0; i-- {
+		if match(c.values[i]) {
+			return c.use(c.values[i])
+		}
+	}
+	return nil
+}
+
+// use assigns a variable name to a produced value the first time it is reused
+func (c *correlator) use(p *produced) *produced {
+	if p.variable == "" {
+		segments := strings.Split(p.path, ".")
+		name := util.SnakeCase(segments[len(segments)-1])
+		if _, err := strconv.Atoi(name); err == nil || name == "" {
+			name = util.SnakeCase(strings.ReplaceAll(p.path, ".", "_"))
+		}
+		if name == "" || (name[0] >= '0' && name[0] <= '9') {
+			name = "value_" + name
+		}
+		c.used[name]++
+		if c.used[name] > 1 {
+			name = fmt.Sprintf("%s_%d", name, c.used[name])
+		}
+		p.variable = name
+		c.reused = append(c.reused, p)
+	}
+	return p
+}
+
+// text replaces produced values in a string: whole matches of any value, and
+// embedded matches of long strings
+func (c *correlator) text(s string) string {
+	if p := c.lookup(func(p *produced) bool { return valueText(p.value) == s }); p != nil {
+		return "{" + p.variable + "}"
+	}
+	for i := len(c.values) - 1; i >= 0; i-- {
+		p := c.values[i]
+		if str, ok := p.value.(string); ok && len(str) >= minTokenString && strings.Contains(s, str) {
+			c.use(p)
+			s = strings.ReplaceAll(s, str, "{"+p.variable+"}")
+		}
+	}
+	return s
+}
+
+// jsonValue replaces produced values in a request JSON body
+func (c *correlator) jsonValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(val))
+		for _, k := range sortedKeys(val) {
+			result[k] = c.jsonValue(val[k])
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, item := range val {
+			result[i] = c.jsonValue(item)
+		}
+		return result
+	case string:
+		return c.text(val)
+	case float64:
+		if p := c.lookup(func(p *produced) bool { return p.value == val }); p != nil {
+			return Tagged("!int", "{"+p.variable+"}")
+		}
+		return val
+	default:
+		return v
+	}
+}
+
+// request builds the request block of an exchange
+func (c *correlator) request(ex record.Exchange) []Pair {
+	segments := strings.Split(ex.Path, "/")
+	for i, segment := range segments {
+		if segment != "" {
+			if unescaped, err := url.PathUnescape(segment); err == nil {
+				segments[i] = c.text(unescaped)
+			}
+		}
+	}
+	request := []Pair{
+		{"url", String("{base_url}" + strings.Join(segments, "/"))},
+		{"method", String(ex.Method)},
+	}
+
+	if len(ex.Query) > 0 {
+		params := map[string]interface{}{}
+		for _, key := range sortedKeys(ex.Query) {
+			params[key] = c.text(ex.Query[key][0])
+		}
+		request = append(request, Pair{"params", Value(params)})
+	}
+
+	body, isJSON := jsonBody(ex.RequestHeader, ex.RequestBody)
+	headers := map[string]interface{}{}
+	for _, key := range sortedKeys(ex.RequestHeader) {
+		name := http.CanonicalHeaderKey(key)
+		if skippedRequestHeaders[name] || (isJSON && name == "Content-Type") {
+			continue
+		}
+		headers[name] = c.text(ex.RequestHeader[key][0])
+	}
+	if len(headers) > 0 {
+		request = append(request, Pair{"headers", Value(headers)})
+	}
+
+	switch {
+	case isJSON:
+		request = append(request, Pair{"json", Value(c.jsonValue(body))})
+	case isForm(ex.RequestHeader):
+		form := map[string]interface{}{}
+		values, _ := url.ParseQuery(string(ex.RequestBody))
+		for _, key := range sortedKeys(values) {
+			form[key] = c.text(values[key][0])
+		}
+		request = append(request, Pair{"data", Value(form)})
+	case len(ex.RequestBody) > 0:
+		request = append(request, Pair{"data", String(string(ex.RequestBody))})
+	}
+	return request
+}
+
+// recordedResponse builds the response block of an exchange. The recorded JSON
+// body is asserted as-is, except for saved values which are dynamic by nature
+// and only have their type checked.
+func recordedResponse(ex record.Exchange, saves []*produced) *goyaml.Node {
+	pairs := []Pair{{"status_code", Int(ex.Status)}}
+
+	if body, ok := jsonBody(ex.ResponseHeader, ex.ResponseBody); ok {
+		if _, isObject := body.(map[string]interface{}); isObject {
+			savedPaths := map[string]bool{}
+			for _, p := range saves {
+				savedPaths[p.path] = true
+			}
+			pairs = append(pairs, Pair{"body", responseValue(body, "", savedPaths)})
+		}
+	}
+
+	if len(saves) > 0 {
+		var savePairs []Pair
+		for _, p := range saves {
+			savePairs = append(savePairs, Pair{p.variable, String(p.path)})
+		}
+		pairs = append(pairs, Pair{"save", Mapping(Pair{"body", Mapping(savePairs...)})})
+	}
+	return Mapping(pairs...)
+}
+
+// responseValue converts a recorded body, replacing saved values with type matchers
+func responseValue(v interface{}, path string, saved map[string]bool) *goyaml.Node {
+	if saved[path] {
+		if _, ok := v.(string); ok {
+			return Tagged("!anystr", "")
+		}
+		return Tagged("!anyint", "")
+	}
+	switch val := v.(type) {
+	case map[string]interface{}:
+		keys := sortedKeys(val)
+		pairs := make([]Pair, 0, len(keys))
+		for _, k := range keys {
+			pairs = append(pairs, Pair{k, responseValue(val[k], joinPath(path, k), saved)})
+		}
+		return Mapping(pairs...)
+	case []interface{}:
+		items := make([]*goyaml.Node, len(val))
+		for i, item := range val {
+			items[i] = responseValue(item, joinPath(path, strconv.Itoa(i)), saved)
+		}
+		return Sequence(items...)
+	case float64:
+		if val == float64(int64(val)) {
+			return Int(int(val))
+		}
+		return Value(val)
+	default:
+		return Value(v)
+	}
+}
+
+// jsonBody decodes a body with a JSON content type
+func jsonBody(header http.Header, body []byte) (interface{}, bool) {
+	if len(body) == 0 {
+		return nil, false
+	}
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil, false
+	}
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return nil, false
+	}
+	return v, true
+}
+
+// isForm reports whether a request has a form-encoded body
+func isForm(header http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return mediaType == "application/x-www-form-urlencoded"
+}
+
+// valueText formats a produced value as it would appear in a URL or header
+func valueText(v interface{}) string {
+	if f, ok := v.(float64); ok {
+		return strconv.FormatInt(int64(f), 10)
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// sortedKeys returns the keys of a map in order, so generated output is deterministic
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
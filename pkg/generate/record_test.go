@@ -0,0 +1,95 @@
+package generate
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/core"
+	"github.com/systemquest/tavern-go/pkg/record"
+)
+
+// sessionServer issues a new token and order id on every login and order, so a
+// replay only passes if they are saved and reused
+func sessionServer() *httptest.Server {
+	var counter int64
+	token := ""
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		n := atomic.AddInt64(&counter, 1)
+		switch {
+		case r.URL.Path == "/login":
+			token = "tok-" + strings.Repeat("x", 8) + string(rune('a'+n))
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"access_token": token, "expires": 3600})
+		case r.Header.Get("Authorization") != "Bearer "+token:
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/orders" && r.Method == "POST":
+			w.WriteHeader(http.StatusCreated)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"order": map[string]interface{}{"id": 1000 + n}})
+		case strings.HasPrefix(r.URL.Path, "/orders/"):
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "pending"})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// TestFromExchanges tests that a recorded session replays with correlated values
+func TestFromExchanges(t *testing.T) {
+	upstream := sessionServer()
+	defer upstream.Close()
+
+	recorder, err := record.NewRecorder(upstream.URL)
+	require.NoError(t, err)
+	proxy := httptest.NewServer(recorder)
+	defer proxy.Close()
+
+	// send makes a request through the proxy and decodes the JSON response
+	send := func(method, path, token, body string) map[string]interface{} {
+		req, err := http.NewRequest(method, proxy.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var data map[string]interface{}
+		_ = json.NewDecoder(resp.Body).Decode(&data)
+		return data
+	}
+
+	// An exploratory session: log in, create an order and look it up
+	token := send("POST", "/login", "", `{"user": "alice"}`)["access_token"].(string)
+	order := send("POST", "/orders", token, `{"sku": "A-1"}`)["order"].(map[string]interface{})
+	send("GET", fmt.Sprintf("/orders/%v", order["id"]), token, "")
+
+	content, err := FromExchanges("Order session", upstream.URL, recorder.Exchanges())
+	require.NoError(t, err)
+	text := string(content)
+	assert.Contains(t, text, "Authorization: Bearer {access_token}")
+	assert.Contains(t, text, "url: \"{base_url}/orders/{id}\"")
+	assert.Contains(t, text, "access_token: access_token")
+	assert.Contains(t, text, "id: order.id")
+	assert.Contains(t, text, "access_token: !anystr")
+	assert.Contains(t, text, "expires: 3600", "Other recorded values are asserted as-is")
+
+	// The generated test replays against the live server with fresh values
+	path := filepath.Join(t.TempDir(), "recorded.tavern.yaml")
+	require.NoError(t, os.WriteFile(path, content, 0644))
+	runner, err := core.NewRunner(&core.Config{})
+	require.NoError(t, err)
+	require.NoError(t, runner.RunFile(path))
+}
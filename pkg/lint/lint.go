@@ -0,0 +1,189 @@
+// Package lint statically checks tavern test files for mistakes that the JSON
+// schema can't catch, such as undefined variables and missing includes
+package lint
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/schema"
+	yamlpkg "github.com/systemquest/tavern-go/pkg/yaml"
+)
+
+// Severities of lint issues
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Rules reported by the linter
+const (
+	RuleSchema            = "schema"             // Test does not match the test schema
+	RuleUndefinedVariable = "undefined-variable" // {var} that nothing provides
+	RuleUnusedSave        = "unused-save"        // Saved variable never used by a later stage
+	RuleDuplicateTestName = "duplicate-test-name"
+	RuleMissingInclude    = "missing-include" // !include of a file that doesn't exist
+	RuleBodyOnGet         = "body-on-get"     // GET/HEAD/OPTIONS request with a body
+)
+
+// Issue is a single lint finding
+type Issue struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Rule     string `json:"rule"`
+	Message  string `json:"message"`
+}
+
+// String formats the issue like a compiler diagnostic: file:line:col: severity: message (rule)
+func (i Issue) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s (%s)", i.File, i.Line, i.Column, i.Severity, i.Message, i.Rule)
+}
+
+// Options configures the linter
+type Options struct {
+	// Variables are names provided outside the test files (global configs, --var)
+	Variables []string
+}
+
+// Linter checks test files
+type Linter struct {
+	loader    *yamlpkg.Loader
+	validator *schema.Validator
+	variables map[string]bool
+}
+
+// NewLinter creates a new linter
+func NewLinter(opts Options) (*Linter, error) {
+	validator, err := schema.NewValidator()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create validator: %w", err)
+	}
+
+	variables := map[string]bool{"tavern": true}
+	for _, name := range opts.Variables {
+		variables[name] = true
+	}
+
+	return &Linter{
+		loader:    yamlpkg.NewLoader("."),
+		validator: validator,
+		variables: variables,
+	}, nil
+}
+
+// LintFiles checks the given test files and returns the issues sorted by position
+// Test names are checked for duplicates across all files
+func (l *Linter) LintFiles(filenames []string) ([]Issue, error) {
+	var issues []Issue
+	testNames := make(map[string]Issue)
+
+	for _, filename := range filenames {
+		documents, err := l.loader.LoadDocuments(filename)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+		issues = append(issues, l.lintDocuments(documents, testNames)...)
+	}
+
+	sortIssues(issues)
+	return issues, nil
+}
+
+// LintSource checks the content of a single test file, such as an unsaved
+// editor buffer. An error is returned if the content is not valid YAML.
+func (l *Linter) LintSource(filename string, data []byte) ([]Issue, error) {
+	documents, err := l.loader.ParseDocuments(filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := l.lintDocuments(documents, make(map[string]Issue))
+	sortIssues(issues)
+	return issues, nil
+}
+
+// lintDocuments checks documents, recording test names in testNames to find duplicates
+func (l *Linter) lintDocuments(documents []*yamlpkg.Document, testNames map[string]Issue) []Issue {
+	var issues []Issue
+	for _, doc := range documents {
+		issues = append(issues, l.lintDocument(doc)...)
+
+		if doc.Test == nil {
+			continue
+		}
+		nameNode := yamlpkg.MappingValue(doc.Node, "test_name")
+		at := issueAt(doc.Filename, nameNode)
+		if first, ok := testNames[doc.Test.TestName]; ok {
+			at.Severity = SeverityWarning
+			at.Rule = RuleDuplicateTestName
+			at.Message = fmt.Sprintf("duplicate test name '%s' (first defined at %s:%d)", doc.Test.TestName, first.File, first.Line)
+			issues = append(issues, at)
+		} else {
+			testNames[doc.Test.TestName] = at
+		}
+	}
+	return issues
+}
+
+// sortIssues orders issues by file and position
+func sortIssues(issues []Issue) {
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+}
+
+// HasErrors reports whether any issue has error severity
+func HasErrors(issues []Issue) bool {
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// lintDocument runs the per-test rules on a single document
+func (l *Linter) lintDocument(doc *yamlpkg.Document) []Issue {
+	var issues []Issue
+
+	// A missing include usually also breaks decoding, so only report the cause
+	issues = append(issues, checkMissingIncludes(doc)...)
+	if doc.Err != nil {
+		if len(issues) > 0 {
+			return issues
+		}
+		issue := issueAt(doc.Filename, doc.Node)
+		issue.Severity = SeverityError
+		issue.Rule = RuleSchema
+		issue.Message = singleLine(doc.Err.Error())
+		return append(issues, issue)
+	}
+
+	if err := l.validator.Validate(doc.Test); err != nil {
+		issue := issueAt(doc.Filename, yamlpkg.MappingValue(doc.Node, "test_name"))
+		issue.Severity = SeverityError
+		issue.Rule = RuleSchema
+		issue.Message = singleLine(err.Error())
+		issues = append(issues, issue)
+	}
+
+	issues = append(issues, l.checkVariables(doc)...)
+	issues = append(issues, checkRequestBodies(doc)...)
+
+	return issues
+}
+
+// singleLine collapses a multi-line error message onto one line
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,452 @@
+package lint
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeTestFile writes a test file into dir and returns its path
+func writeTestFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+// issuesByRule groups issues by rule
+func issuesByRule(issues []Issue) map[string][]Issue {
+	byRule := make(map[string][]Issue)
+	for _, issue := range issues {
+		byRule[issue.Rule] = append(byRule[issue.Rule], issue)
+	}
+	return byRule
+}
+
+// TestLinter_Variables tests undefined variable and unused save detection
+func TestLinter_Variables(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "test_vars.tavern.yaml", `
+test_name: Variables
+includes:
+  - name: common
+    description: Common variables
+    variables:
+      user: alice
+stages:
+  - name: login
+    request:
+      url: "{host}/login"
+      method: POST
+      json:
+        user: "{user}"
+        token: "{tavern.env_vars.TOKEN}"
+    response:
+      status_code: 200
+      save:
+        body:
+          token: token
+          session: session_id
+  - name: profile
+    request:
+      url: "{host}/me"
+      headers:
+        Authorization: "Bearer {token}"
+        X-Trace: "{trace_id}"
+    response:
+      status_code: 200
+      body:
+        name: "{user}"
+`)
+
+	linter, err := NewLinter(Options{Variables: []string{"host"}})
+	require.NoError(t, err)
+
+	issues, err := linter.LintFiles([]string{path})
+	require.NoError(t, err)
+
+	byRule := issuesByRule(issues)
+	require.Len(t, byRule[RuleUndefinedVariable], 1)
+	undefined := byRule[RuleUndefinedVariable][0]
+	assert.Equal(t, SeverityError, undefined.Severity)
+	assert.Contains(t, undefined.Message, "{trace_id}")
+	assert.Equal(t, 27, undefined.Line)
+
+	require.Len(t, byRule[RuleUnusedSave], 1)
+	assert.Contains(t, byRule[RuleUnusedSave][0].Message, "'session'")
+	assert.Equal(t, SeverityWarning, byRule[RuleUnusedSave][0].Severity)
+	assert.True(t, HasErrors(issues))
+}
+
+// TestLinter_GraphQLQuery tests that braces in a GraphQL query are not taken for
+// variables, while its variables are still checked
+func TestLinter_GraphQLQuery(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "test_graphql.tavern.yaml", `
+test_name: GraphQL
+stages:
+  - name: user
+    request:
+      url: http://localhost/graphql
+      graphql:
+        query: "query User($id: ID!) { user(id: $id) { name } }"
+        variables:
+          id: "{user_id}"
+    response:
+      save:
+        data:
+          name: user.name
+`)
+
+	linter, err := NewLinter(Options{})
+	require.NoError(t, err)
+
+	issues, err := linter.LintFiles([]string{path})
+	require.NoError(t, err)
+
+	byRule := issuesByRule(issues)
+	require.Len(t, byRule[RuleUndefinedVariable], 1)
+	assert.Contains(t, byRule[RuleUndefinedVariable][0].Message, "{user_id}")
+	require.Len(t, byRule[RuleUnusedSave], 1)
+	assert.Contains(t, byRule[RuleUnusedSave][0].Message, "'name'")
+}
+
+// TestLinter_GRPCStages tests that variables are checked in gRPC stages and saved from them
+func TestLinter_GRPCStages(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "test_grpc.tavern.yaml", `
+test_name: gRPC
+stages:
+  - name: create
+    grpc_request:
+      host: "{grpc_host}"
+      service: users.Users/Create
+      body:
+        name: alice
+    grpc_response:
+      save:
+        body:
+          user_id: user.id
+  - name: get
+    grpc_request:
+      host: localhost:50051
+      service: users.Users/Get
+      body:
+        id: "{user_id}"
+    grpc_response:
+      status: OK
+`)
+
+	linter, err := NewLinter(Options{})
+	require.NoError(t, err)
+
+	issues, err := linter.LintFiles([]string{path})
+	require.NoError(t, err)
+
+	byRule := issuesByRule(issues)
+	require.Len(t, byRule[RuleUndefinedVariable], 1)
+	assert.Contains(t, byRule[RuleUndefinedVariable][0].Message, "{grpc_host}")
+	assert.Empty(t, byRule[RuleUnusedSave])
+}
+
+// TestLinter_EventSaves tests that variables saved from server-sent events are defined
+func TestLinter_EventSaves(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "test_events.tavern.yaml", `
+test_name: Events
+stages:
+  - name: complete
+    request:
+      url: http://localhost/complete
+    response:
+      events:
+        until:
+          event: done
+          save:
+            data:
+              completion_id: id
+        expect:
+          - event: token
+            save:
+              data:
+                first_token: text
+  - name: get
+    request:
+      url: "http://localhost/completions/{completion_id}"
+    response:
+      status_code: 200
+`)
+
+	linter, err := NewLinter(Options{})
+	require.NoError(t, err)
+
+	issues, err := linter.LintFiles([]string{path})
+	require.NoError(t, err)
+
+	byRule := issuesByRule(issues)
+	assert.Empty(t, byRule[RuleUndefinedVariable])
+	require.Len(t, byRule[RuleUnusedSave], 1)
+	assert.Contains(t, byRule[RuleUnusedSave][0].Message, "'first_token'")
+}
+
+// TestLinter_WebSocketStages tests variables used and saved by websocket messages
+func TestLinter_WebSocketStages(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "test_websocket.tavern.yaml", `
+test_name: WebSocket
+stages:
+  - name: subscribe
+    websocket:
+      connect:
+        url: "{ws_host}/notifications"
+      send:
+        - json:
+            type: subscribe
+      expect:
+        - json:
+            type: subscribed
+          save:
+            json:
+              sub_id: id
+              channel: channel
+  - name: acknowledge
+    websocket:
+      send:
+        - text: "ack {sub_id}"
+      expect:
+        - text: acked
+`)
+
+	linter, err := NewLinter(Options{})
+	require.NoError(t, err)
+
+	issues, err := linter.LintFiles([]string{path})
+	require.NoError(t, err)
+
+	byRule := issuesByRule(issues)
+	require.Len(t, byRule[RuleUndefinedVariable], 1)
+	assert.Contains(t, byRule[RuleUndefinedVariable][0].Message, "{ws_host}")
+	require.Len(t, byRule[RuleUnusedSave], 1)
+	assert.Contains(t, byRule[RuleUnusedSave][0].Message, "'channel'")
+}
+
+// TestLinter_CommandStages tests refs and saves of command stages, and that regexes are not checked
+func TestLinter_CommandStages(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "test_command.tavern.yaml", `
+test_name: CLI
+stages:
+  - name: create
+    command:
+      argv: [mycli, create, "{name}"]
+    command_response:
+      stdout:
+        matches: "^id=[0-9]{4}"
+      save:
+        stdout:
+          item_id: "id=([0-9]+)"
+          created: "at=(.*)"
+  - name: show
+    command:
+      argv: [mycli, show]
+      env:
+        ITEM: "{item_id}"
+`)
+
+	linter, err := NewLinter(Options{})
+	require.NoError(t, err)
+
+	issues, err := linter.LintFiles([]string{path})
+	require.NoError(t, err)
+
+	byRule := issuesByRule(issues)
+	require.Len(t, byRule[RuleUndefinedVariable], 1)
+	assert.Contains(t, byRule[RuleUndefinedVariable][0].Message, "{name}")
+	require.Len(t, byRule[RuleUnusedSave], 1)
+	assert.Contains(t, byRule[RuleUnusedSave][0].Message, "'created'")
+}
+
+// TestLinter_TCPStages tests refs and saves of tcp stages, and that reply regexes are not checked
+func TestLinter_TCPStages(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "test_tcp.tavern.yaml", `
+test_name: TCP
+stages:
+  - name: reserve
+    tcp:
+      host: "{inventory}"
+      send: "RESERVE {sku}\n"
+      until: "\r\n"
+      reply:
+        matches: "^OK id=[0-9]{2,}"
+      save:
+        reply:
+          reservation: "id=([0-9]+)"
+  - name: status
+    tcp:
+      host: localhost:7000
+      send: "STATUS {reservation}\n"
+      reply:
+        equals: "RESERVED {reservation}"
+`)
+
+	linter, err := NewLinter(Options{})
+	require.NoError(t, err)
+
+	issues, err := linter.LintFiles([]string{path})
+	require.NoError(t, err)
+
+	byRule := issuesByRule(issues)
+	require.Len(t, byRule[RuleUndefinedVariable], 2)
+	assert.Contains(t, byRule[RuleUndefinedVariable][0].Message, "{inventory}")
+	assert.Contains(t, byRule[RuleUndefinedVariable][1].Message, "{sku}")
+	assert.Empty(t, byRule[RuleUnusedSave])
+}
+
+// TestLinter_RedisStages tests variables used in and saved by redis stages
+func TestLinter_RedisStages(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "test_redis.tavern.yaml", `
+test_name: Redis
+stages:
+  - name: counter
+    redis:
+      host: "{cache}"
+      command: [INCR, "visits:{page}"]
+      save:
+        reply:
+          visits: ""
+  - name: check
+    redis:
+      host: localhost:6379
+      command: [GET, last_visit]
+      reply: "{visits}"
+`)
+
+	linter, err := NewLinter(Options{})
+	require.NoError(t, err)
+
+	issues, err := linter.LintFiles([]string{path})
+	require.NoError(t, err)
+
+	byRule := issuesByRule(issues)
+	require.Len(t, byRule[RuleUndefinedVariable], 2)
+	assert.Contains(t, byRule[RuleUndefinedVariable][0].Message, "{cache}")
+	assert.Contains(t, byRule[RuleUndefinedVariable][1].Message, "{page}")
+	assert.Empty(t, byRule[RuleUnusedSave])
+}
+
+// TestLinter_ExtSaveSuppressesUndefined tests that extension saves may provide any variable
+func TestLinter_ExtSaveSuppressesUndefined(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTestFile(t, dir, "test_ext.tavern.yaml", `
+test_name: Extension save
+stages:
+  - name: save with extension
+    request:
+      url: http://example.com
+    response:
+      status_code: 200
+      save:
+        $ext:
+          function: my_ext:save_all
+  - name: use
+    request:
+      url: "http://example.com/{anything}"
+    response:
+      status_code: 200
+`)
+
+	linter, err := NewLinter(Options{})
+	require.NoError(t, err)
+
+	issues, err := linter.LintFiles([]string{path})
+	require.NoError(t, err)
+	assert.Empty(t, issuesByRule(issues)[RuleUndefinedVariable])
+}
+
+// TestLinter_StructuralRules tests missing includes, bodies on GET and duplicate test names
+func TestLinter_StructuralRules(t *testing.T) {
+	dir := t.TempDir()
+	first := writeTestFile(t, dir, "test_a.tavern.yaml", `
+test_name: Shared name
+stages:
+  - name: get with body
+    request:
+      url: http://example.com
+      json:
+        a: 1
+    response:
+      status_code: 200
+`)
+	second := writeTestFile(t, dir, "test_b.tavern.yaml", `
+test_name: Shared name
+stages:
+  - name: post
+    request:
+      url: http://example.com
+      method: POST
+      json: !include missing.yaml
+    response:
+      status_code: 200
+`)
+
+	linter, err := NewLinter(Options{})
+	require.NoError(t, err)
+
+	issues, err := linter.LintFiles([]string{first, second})
+	require.NoError(t, err)
+	byRule := issuesByRule(issues)
+
+	require.Len(t, byRule[RuleBodyOnGet], 1)
+	assert.Equal(t, first, byRule[RuleBodyOnGet][0].File)
+	assert.Equal(t, 7, byRule[RuleBodyOnGet][0].Line)
+
+	require.Len(t, byRule[RuleMissingInclude], 1)
+	assert.Equal(t, second, byRule[RuleMissingInclude][0].File)
+	assert.Equal(t, 8, byRule[RuleMissingInclude][0].Line)
+	assert.Contains(t, byRule[RuleMissingInclude][0].Message, "missing.yaml")
+
+	require.Len(t, byRule[RuleDuplicateTestName], 1)
+	assert.Equal(t, second, byRule[RuleDuplicateTestName][0].File)
+	assert.Contains(t, byRule[RuleDuplicateTestName][0].Message, first+":2")
+}
+
+// TestIssue_String tests the compiler-style text format
+func TestIssue_String(t *testing.T) {
+	issue := Issue{File: "a.yaml", Line: 3, Column: 5, Severity: SeverityError, Rule: RuleSchema, Message: "bad"}
+	assert.Equal(t, "a.yaml:3:5: error: bad (schema)", issue.String())
+}
+
+// TestLinter_LintSource tests linting unsaved content with includes resolved next to the filename
+func TestLinter_LintSource(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, dir, "common.yaml", "name: common\ndescription: Common variables\nvariables:\n  host: http://localhost\n")
+
+	linter, err := NewLinter(Options{})
+	require.NoError(t, err)
+
+	filename := filepath.Join(dir, "test_unsaved.tavern.yaml")
+	issues, err := linter.LintSource(filename, []byte(`
+test_name: Unsaved
+includes:
+  - !include common.yaml
+stages:
+  - name: get
+    request:
+      url: "{host}/items/{item_id}"
+    response:
+      status_code: 200
+`))
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, RuleUndefinedVariable, issues[0].Rule)
+	assert.Equal(t, filename, issues[0].File)
+	assert.Equal(t, 8, issues[0].Line)
+
+	_, err = linter.LintSource(filename, []byte("test_name: [unclosed\n"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,326 @@
+package lint
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/util"
+	yamlpkg "github.com/systemquest/tavern-go/pkg/yaml"
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// varRef is a {var} reference found in a scalar
+type varRef struct {
+	name string // Root variable name, e.g. "user" for {user.id}
+	path string // Full reference, e.g. "user.id"
+	node *goyaml.Node
+}
+
+// savedVar is a variable saved by a stage
+type savedVar struct {
+	name string
+	node *goyaml.Node
+	used bool
+}
+
+// checkVariables flags {var} references that nothing provides and saves that are never used
+func (l *Linter) checkVariables(doc *yamlpkg.Document) []Issue {
+	var issues []Issue
+
+	defined := make(map[string]bool, len(l.variables))
+	for name := range l.variables {
+		defined[name] = true
+	}
+
+	undefined := func(refs []varRef) {
+		for _, ref := range refs {
+			if defined[ref.name] {
+				continue
+			}
+			issue := issueAt(doc.Filename, ref.node)
+			issue.Severity = SeverityError
+			issue.Rule = RuleUndefinedVariable
+			issue.Message = fmt.Sprintf("variable '{%s}' is not defined by an include, global config or earlier save", ref.path)
+			issues = append(issues, issue)
+		}
+	}
+
+	// Includes are formatted in order, each with the variables defined before it
+	if includes := yamlpkg.MappingValue(doc.Node, "includes"); includes != nil {
+		for _, include := range includes.Content {
+			variables := yamlpkg.MappingValue(include, "variables")
+			if variables == nil || variables.Kind != goyaml.MappingNode {
+				continue
+			}
+			undefined(collectRefs(variables))
+			for i := 0; i+1 < len(variables.Content); i += 2 {
+				defined[variables.Content[i].Value] = true
+			}
+		}
+	}
+
+	// The MQTT connection is made before the first stage
+	if mqtt := yamlpkg.MappingValue(doc.Node, "mqtt"); mqtt != nil {
+		undefined(collectRefs(mqtt))
+	}
+
+	var saved []*savedVar
+	dynamic := false // An extension save can provide any variable
+
+	stages := yamlpkg.MappingValue(doc.Node, "stages")
+	if stages == nil {
+		return issues
+	}
+	for _, stage := range stages.Content {
+		var refs []varRef
+		if req := yamlpkg.MappingValue(stage, "request"); req != nil {
+			refs = append(refs, collectRefs(req, "data", "graphql")...)
+			// A GraphQL query is sent as-is, since its selections use braces
+			if graphql := yamlpkg.MappingValue(req, "graphql"); graphql != nil {
+				refs = append(refs, collectRefs(graphql, "query")...)
+			}
+		}
+		if req := yamlpkg.MappingValue(stage, "grpc_request"); req != nil {
+			refs = append(refs, collectRefs(req)...)
+		}
+		resp := yamlpkg.MappingValue(stage, "response")
+		if resp == nil {
+			resp = yamlpkg.MappingValue(stage, "grpc_response")
+		}
+		var saves []*goyaml.Node
+		if resp != nil {
+			refs = append(refs, collectRefs(resp, "save")...)
+			saves = append(saves, yamlpkg.MappingValue(resp, "save"))
+			saves = append(saves, eventSaves(yamlpkg.MappingValue(resp, "events"))...)
+		}
+		if publish := yamlpkg.MappingValue(stage, "mqtt_publish"); publish != nil {
+			refs = append(refs, collectRefs(publish)...)
+		}
+		if mqttResp := yamlpkg.MappingValue(stage, "mqtt_response"); mqttResp != nil {
+			refs = append(refs, collectRefs(mqttResp, "save")...)
+			saves = append(saves, yamlpkg.MappingValue(mqttResp, "save"))
+		}
+		if command := yamlpkg.MappingValue(stage, "command"); command != nil {
+			refs = append(refs, collectRefs(command)...)
+		}
+		if commandResp := yamlpkg.MappingValue(stage, "command_response"); commandResp != nil {
+			// Regular expressions are used as-is, since braces are regex syntax
+			for _, stream := range []string{"stdout", "stderr"} {
+				if output := yamlpkg.MappingValue(commandResp, stream); output != nil {
+					refs = append(refs, collectRefs(output, "matches")...)
+				}
+			}
+			saves = append(saves, yamlpkg.MappingValue(commandResp, "save"))
+		}
+		if tcp := yamlpkg.MappingValue(stage, "tcp"); tcp != nil {
+			refs = append(refs, collectRefs(tcp, "reply", "save")...)
+			if reply := yamlpkg.MappingValue(tcp, "reply"); reply != nil {
+				refs = append(refs, collectRefs(reply, "matches")...)
+			}
+			saves = append(saves, yamlpkg.MappingValue(tcp, "save"))
+		}
+		if redis := yamlpkg.MappingValue(stage, "redis"); redis != nil {
+			refs = append(refs, collectRefs(redis, "save")...)
+			saves = append(saves, yamlpkg.MappingValue(redis, "save"))
+		}
+		if ws := yamlpkg.MappingValue(stage, "websocket"); ws != nil {
+			refs = append(refs, collectRefs(ws, "expect")...)
+			if expect := yamlpkg.MappingValue(ws, "expect"); expect != nil {
+				for _, message := range expect.Content {
+					refs = append(refs, collectRefs(message, "save")...)
+					saves = append(saves, yamlpkg.MappingValue(message, "save"))
+				}
+			}
+		}
+
+		for _, ref := range refs {
+			for _, s := range saved {
+				if s.name == ref.name {
+					s.used = true
+				}
+			}
+		}
+		if !dynamic {
+			undefined(refs)
+		}
+
+		for _, save := range saves {
+			if save == nil || save.Kind != goyaml.MappingNode {
+				continue
+			}
+			for i := 0; i+1 < len(save.Content); i += 2 {
+				block, values := save.Content[i], save.Content[i+1]
+				if block.Value == "$ext" {
+					dynamic = true
+					continue
+				}
+				if values.Kind != goyaml.MappingNode {
+					continue
+				}
+				for j := 0; j+1 < len(values.Content); j += 2 {
+					key := values.Content[j]
+					defined[key.Value] = true
+					saved = append(saved, &savedVar{name: key.Value, node: key})
+				}
+			}
+		}
+	}
+
+	for _, s := range saved {
+		if s.used {
+			continue
+		}
+		issue := issueAt(doc.Filename, s.node)
+		issue.Severity = SeverityWarning
+		issue.Rule = RuleUnusedSave
+		issue.Message = fmt.Sprintf("saved variable '%s' is never used by a later stage", s.name)
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// checkRequestBodies flags GET, HEAD and OPTIONS requests that send a body
+func checkRequestBodies(doc *yamlpkg.Document) []Issue {
+	var issues []Issue
+
+	stages := yamlpkg.MappingValue(doc.Node, "stages")
+	if stages == nil {
+		return nil
+	}
+	for _, stage := range stages.Content {
+		req := yamlpkg.MappingValue(stage, "request")
+		if req == nil {
+			continue
+		}
+
+		method := "GET"
+		if methodNode := yamlpkg.MappingValue(req, "method"); methodNode != nil {
+			method = strings.ToUpper(methodNode.Value)
+		}
+		if method != "GET" && method != "HEAD" && method != "OPTIONS" {
+			continue
+		}
+
+		for _, key := range []string{"json", "data", "files"} {
+			if body := yamlpkg.MappingKey(req, key); body != nil {
+				issue := issueAt(doc.Filename, body)
+				issue.Severity = SeverityWarning
+				issue.Rule = RuleBodyOnGet
+				issue.Message = fmt.Sprintf("%s request sends a body ('%s'), which servers may ignore", method, key)
+				issues = append(issues, issue)
+			}
+		}
+	}
+
+	return issues
+}
+
+// checkMissingIncludes flags !include tags the loader could not resolve
+func checkMissingIncludes(doc *yamlpkg.Document) []Issue {
+	var issues []Issue
+
+	var walk func(node *goyaml.Node)
+	walk = func(node *goyaml.Node) {
+		if node.Tag == "!include" {
+			issue := issueAt(doc.Filename, node)
+			issue.Severity = SeverityError
+			issue.Rule = RuleMissingInclude
+			issue.Message = fmt.Sprintf("included file '%s' does not exist or is not valid YAML", node.Value)
+			issues = append(issues, issue)
+			return
+		}
+		for _, child := range node.Content {
+			walk(child)
+		}
+	}
+	walk(doc.Node)
+
+	return issues
+}
+
+// collectRefs returns the {var} references in all scalars under node, skipping
+// the given top-level keys and $ext blocks (whose arguments are not formatted)
+func collectRefs(node *goyaml.Node, skipKeys ...string) []varRef {
+	var refs []varRef
+
+	var walk func(node *goyaml.Node)
+	walk = func(node *goyaml.Node) {
+		switch node.Kind {
+		case goyaml.ScalarNode:
+			refs = append(refs, scalarRefs(node)...)
+		case goyaml.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == "$ext" {
+					continue
+				}
+				walk(node.Content[i+1])
+			}
+		default:
+			for _, child := range node.Content {
+				walk(child)
+			}
+		}
+	}
+
+	if node.Kind == goyaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if slices.Contains(skipKeys, node.Content[i].Value) {
+				continue
+			}
+			walk(node.Content[i+1])
+		}
+	} else {
+		walk(node)
+	}
+
+	return refs
+}
+
+// eventSaves returns the save blocks of the until and expected events of an events block
+func eventSaves(events *goyaml.Node) []*goyaml.Node {
+	saves := []*goyaml.Node{yamlpkg.MappingValue(yamlpkg.MappingValue(events, "until"), "save")}
+	if expect := yamlpkg.MappingValue(events, "expect"); expect != nil {
+		for _, event := range expect.Content {
+			saves = append(saves, yamlpkg.MappingValue(event, "save"))
+		}
+	}
+	return saves
+}
+
+// scalarRefs finds {var} references the same way the runner formats strings
+func scalarRefs(node *goyaml.Node) []varRef {
+	value := node.Value
+	if util.IsExternalValue(value) {
+		return nil
+	}
+
+	var refs []varRef
+	for {
+		start := strings.Index(value, "{")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(value[start:], "}")
+		if end == -1 {
+			break
+		}
+		end += start
+
+		path := value[start+1 : end]
+		name, _, _ := strings.Cut(path, ".")
+		refs = append(refs, varRef{name: name, path: path, node: node})
+		value = value[end+1:]
+	}
+	return refs
+}
+
+// issueAt returns an issue positioned at node
+func issueAt(filename string, node *goyaml.Node) Issue {
+	issue := Issue{File: filename, Line: 1, Column: 1}
+	if node != nil && node.Line > 0 {
+		issue.Line, issue.Column = node.Line, node.Column
+	}
+	return issue
+}
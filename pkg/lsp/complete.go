@@ -0,0 +1,310 @@
+package lsp
+
+import (
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/extension"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	yamlpkg "github.com/systemquest/tavern-go/pkg/yaml"
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// tags are the custom YAML tags understood by the loader
+var tags = []struct{ name, detail string }{
+	{"!include", "Include another YAML file"},
+	{"!anything", "Match any value"},
+	{"!anystr", "Match any string"},
+	{"!anyint", "Match any integer"},
+	{"!anyfloat", "Match any float"},
+	{"!anybool", "Match any boolean"},
+	{"!str", "Match any string"},
+	{"!int", "Match any integer, or convert a formatted value to an integer"},
+	{"!float", "Match any float, or convert a formatted value to a float"},
+	{"!bool", "Match any boolean, or convert a formatted value to a boolean"},
+	{"!approx", "Match a number approximately"},
+	{"!file", "Value read from a file"},
+	{"!env", "Value read from an environment variable"},
+	{"!cmd", "Output of a shell command"},
+	{"!secret", "Value masked in logs and reports"},
+}
+
+var (
+	tagPrefix      = regexp.MustCompile(`(?:^|[\s:\-\[,])!\w*$`)
+	functionPrefix = regexp.MustCompile(`^\s*(?:-\s+)?function:\s*\S*$`)
+	keyPrefix      = regexp.MustCompile(`^(\s*)((?:-\s+)*)[\w$]*$`)
+	keyLine        = regexp.MustCompile(`^(\s*)((?:-\s+)*)([\w$]+)\s*:`)
+)
+
+// complete returns the completions at a position of a document
+func (s *Server) complete(filename, text string, pos Position) []CompletionItem {
+	lines := strings.Split(text, "\n")
+	if pos.Line < 0 || pos.Line >= len(lines) {
+		return []CompletionItem{}
+	}
+	line := lines[pos.Line]
+	prefix := line[:byteOffset(line, pos.Character)]
+
+	items := []CompletionItem{}
+	switch {
+	case tagPrefix.MatchString(prefix):
+		for _, tag := range tags {
+			items = append(items, CompletionItem{Label: tag.name, Kind: kindKeyword, Detail: tag.detail})
+		}
+
+	case inVariable(prefix):
+		for _, name := range s.variablesInScope(filename, lines, pos.Line) {
+			items = append(items, CompletionItem{Label: name, Kind: kindVariable})
+		}
+
+	case functionPrefix.MatchString(prefix):
+		for _, fn := range extensionFunctions() {
+			items = append(items, CompletionItem{Label: fn.name, Kind: kindFunction, Detail: fn.detail})
+		}
+
+	case keyPrefix.MatchString(prefix):
+		m := keyPrefix.FindStringSubmatch(prefix)
+		column := len(m[1]) + len(m[2])
+		if m[2] != "" {
+			column = len(m[1]) // A new list item belongs to the list's parent
+		}
+		for _, key := range keysAt(parentPath(lines, pos.Line, column)) {
+			items = append(items, CompletionItem{Label: key, Kind: kindProperty})
+		}
+	}
+	return items
+}
+
+// inVariable reports whether the prefix ends inside an unclosed {variable}
+func inVariable(prefix string) bool {
+	start := strings.LastIndex(prefix, "{")
+	if start == -1 || strings.LastIndex(prefix, "}") > start {
+		return false
+	}
+	for _, r := range prefix[start+1:] {
+		if r != '_' && r != '.' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && !('0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+// parentPath returns the keys enclosing a key at column on line, outermost
+// first, by walking up to lines that are less indented
+func parentPath(lines []string, line, column int) []string {
+	var path []string
+	for i := line - 1; i >= 0 && column > 0; i-- {
+		if strings.TrimSpace(lines[i]) == "---" {
+			break
+		}
+		m := keyLine.FindStringSubmatch(lines[i])
+		if m == nil || strings.HasPrefix(strings.TrimSpace(lines[i]), "#") {
+			continue
+		}
+		keyColumn := len(m[1]) + len(m[2])
+		if keyColumn >= column {
+			continue
+		}
+		path = append([]string{m[3]}, path...)
+		column = keyColumn
+		if m[2] != "" {
+			column = len(m[1])
+		}
+	}
+	return path
+}
+
+var (
+	testSpecType   = reflect.TypeOf(schema.TestSpec{})
+	saveConfigType = reflect.TypeOf(schema.SaveConfig{})
+	saveSpecType   = reflect.TypeOf(schema.SaveSpec{})
+	extSpecType    = reflect.TypeOf(schema.ExtSpec{})
+)
+
+// keysAt returns the keys allowed in the mapping at path, from the yaml tags
+// of the schema types
+func keysAt(path []string) []string {
+	t := testSpecType
+	for _, key := range path {
+		t = elem(t)
+		if t == saveConfigType {
+			t = saveSpecType
+			if key == "$ext" {
+				t = extSpecType
+				continue
+			}
+		}
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+		field, ok := fieldByKey(t, key)
+		if !ok {
+			return nil
+		}
+		t = field.Type
+	}
+
+	t = elem(t)
+	var keys []string
+	if t == saveConfigType {
+		keys = append(keys, "$ext")
+		t = saveSpecType
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if name := yamlKey(t.Field(i)); name != "" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// elem dereferences pointer and slice types
+func elem(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t
+}
+
+// fieldByKey finds the struct field with the given yaml key
+func fieldByKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if yamlKey(t.Field(i)) == key {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// yamlKey returns the yaml key of an exported field, or "" if it has none
+func yamlKey(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+	if name == "-" {
+		return ""
+	}
+	return name
+}
+
+// extensionFunctions returns the registered extension function names
+func extensionFunctions() []struct{ name, detail string } {
+	var functions []struct{ name, detail string }
+	add := func(names []string, detail string) {
+		sort.Strings(names)
+		for _, name := range names {
+			functions = append(functions, struct{ name, detail string }{name, detail})
+		}
+	}
+	add(extension.ListValidators(), "validator")
+	add(extension.ListParameterizedValidators(), "parameterized validator")
+	add(extension.ListSavers(), "saver")
+	add(extension.ListParameterizedSavers(), "parameterized saver")
+	add(extension.ListGenerators(), "request generator")
+	return functions
+}
+
+// variablesInScope returns the variables usable on a line: global variables,
+// include variables and the saves of earlier stages of the same test
+func (s *Server) variablesInScope(filename string, lines []string, line int) []string {
+	seen := map[string]bool{}
+	var names []string
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	for _, name := range s.variables {
+		add(name)
+	}
+	add("tavern.env_vars.")
+
+	// The line being edited usually doesn't parse, so it is left out
+	edited := append([]string(nil), lines...)
+	edited[line] = ""
+	documents, err := yamlpkg.NewLoader(".").ParseDocuments(filename, []byte(strings.Join(edited, "\n")))
+	if err != nil {
+		return names
+	}
+
+	var root *goyaml.Node
+	for _, doc := range documents {
+		if doc.Node.Line <= line+1 {
+			root = doc.Node
+		}
+	}
+
+	if includes := yamlpkg.MappingValue(root, "includes"); includes != nil {
+		for _, include := range includes.Content {
+			for _, key := range mappingKeys(yamlpkg.MappingValue(include, "variables")) {
+				add(key)
+			}
+		}
+	}
+
+	if stages := yamlpkg.MappingValue(root, "stages"); stages != nil {
+		var earlier []*goyaml.Node
+		for _, stage := range stages.Content {
+			if stage.Line > line+1 {
+				break
+			}
+			earlier = append(earlier, stage)
+		}
+		if len(earlier) > 0 {
+			earlier = earlier[:len(earlier)-1] // The stage being edited
+		}
+		for _, stage := range earlier {
+			resp := yamlpkg.MappingValue(stage, "response")
+			if resp == nil {
+				resp = yamlpkg.MappingValue(stage, "grpc_response")
+			}
+			saves := []*goyaml.Node{yamlpkg.MappingValue(resp, "save")}
+			saves = append(saves, yamlpkg.MappingValue(yamlpkg.MappingValue(stage, "mqtt_response"), "save"))
+			saves = append(saves, yamlpkg.MappingValue(yamlpkg.MappingValue(stage, "command_response"), "save"))
+			saves = append(saves, yamlpkg.MappingValue(yamlpkg.MappingValue(stage, "tcp"), "save"))
+			saves = append(saves, yamlpkg.MappingValue(yamlpkg.MappingValue(stage, "redis"), "save"))
+			events := yamlpkg.MappingValue(resp, "events")
+			saves = append(saves, yamlpkg.MappingValue(yamlpkg.MappingValue(events, "until"), "save"))
+			if expect := yamlpkg.MappingValue(events, "expect"); expect != nil {
+				for _, event := range expect.Content {
+					saves = append(saves, yamlpkg.MappingValue(event, "save"))
+				}
+			}
+			if expect := yamlpkg.MappingValue(yamlpkg.MappingValue(stage, "websocket"), "expect"); expect != nil {
+				for _, message := range expect.Content {
+					saves = append(saves, yamlpkg.MappingValue(message, "save"))
+				}
+			}
+			for _, save := range saves {
+				for _, block := range mappingKeys(save) {
+					if block == "$ext" {
+						continue // Extension saves don't name their variables up front
+					}
+					for _, key := range mappingKeys(yamlpkg.MappingValue(save, block)) {
+						add(key)
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+// mappingKeys returns the keys of a mapping node in order
+func mappingKeys(node *goyaml.Node) []string {
+	if node == nil || node.Kind != goyaml.MappingNode {
+		return nil
+	}
+	var keys []string
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keys = append(keys, node.Content[i].Value)
+	}
+	return keys
+}
@@ -0,0 +1,237 @@
+// Package lsp implements a Language Server Protocol server for tavern test
+// files, offering completion and diagnostics to editors over stdio
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/lint"
+	"github.com/systemquest/tavern-go/pkg/version"
+)
+
+// Options configures the server
+type Options struct {
+	// Variables are names provided outside the test files (global configs, --var)
+	Variables []string
+}
+
+// Server answers LSP requests for open test files
+type Server struct {
+	linter    *lint.Linter
+	variables []string
+	docs      map[string]string // Open documents by URI
+	out       io.Writer
+}
+
+// NewServer creates a new language server
+func NewServer(opts Options) (*Server, error) {
+	linter, err := lint.NewLinter(lint.Options{Variables: opts.Variables})
+	if err != nil {
+		return nil, err
+	}
+	return &Server{
+		linter:    linter,
+		variables: opts.Variables,
+		docs:      make(map[string]string),
+	}, nil
+}
+
+// Run serves messages from r until the client sends exit or closes the stream
+func (s *Server) Run(r io.Reader, w io.Writer) error {
+	s.out = w
+	reader := bufio.NewReader(r)
+	for {
+		msg, err := readMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Error != nil {
+			if err := writeMessage(w, &message{ID: msg.ID, Error: msg.Error}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+
+		result, rpcErr := s.handle(msg)
+		if msg.ID == nil {
+			continue // Notifications have no response
+		}
+		response := &message{ID: msg.ID, Result: result, Error: rpcErr}
+		if rpcErr == nil && result == nil {
+			response.Result = json.RawMessage("null")
+		}
+		if err := writeMessage(w, response); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a request or notification
+func (s *Server) handle(msg *message) (interface{}, *responseError) {
+	decode := func(v interface{}) *responseError {
+		if err := json.Unmarshal(msg.Params, v); err != nil {
+			return &responseError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return nil
+	}
+
+	switch msg.Method {
+	case "initialize":
+		var result initializeResult
+		result.Capabilities.TextDocumentSync = 1
+		result.Capabilities.CompletionProvider.TriggerCharacters = []string{"!", "{"}
+		result.ServerInfo.Name = "tavern"
+		result.ServerInfo.Version = version.Version
+		return result, nil
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		s.docs[params.TextDocument.URI] = params.TextDocument.Text
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			s.docs[params.TextDocument.URI] = params.ContentChanges[n-1].Text
+		}
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/didSave":
+		var params didSaveParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		if params.Text != nil {
+			s.docs[params.TextDocument.URI] = *params.Text
+		}
+		return nil, s.publishDiagnostics(params.TextDocument.URI)
+
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		delete(s.docs, params.TextDocument.URI)
+		return nil, s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+
+	case "textDocument/completion":
+		var params completionParams
+		if err := decode(&params); err != nil {
+			return nil, err
+		}
+		text, ok := s.docs[params.TextDocument.URI]
+		if !ok {
+			return CompletionList{Items: []CompletionItem{}}, nil
+		}
+		return CompletionList{Items: s.complete(uriFilename(params.TextDocument.URI), text, params.Position)}, nil
+
+	case "initialized", "shutdown", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil, nil
+	}
+
+	if msg.ID != nil {
+		return nil, &responseError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not supported: %s", msg.Method)}
+	}
+	return nil, nil
+}
+
+// notify sends a notification to the client
+func (s *Server) notify(method string, params interface{}) *responseError {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	if err := writeMessage(s.out, &message{Method: method, Params: data}); err != nil {
+		return &responseError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// publishDiagnostics lints an open document and sends the issues to the client
+func (s *Server) publishDiagnostics(uri string) *responseError {
+	text, ok := s.docs[uri]
+	if !ok {
+		return nil
+	}
+	return s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: s.diagnostics(uriFilename(uri), text),
+	})
+}
+
+// yamlErrorLine finds the line number in a YAML syntax error
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// diagnostics converts lint issues of a document into diagnostics covering the
+// rest of the line they point at
+func (s *Server) diagnostics(filename, text string) []Diagnostic {
+	lines := strings.Split(text, "\n")
+	at := func(line, column int) Range {
+		line = min(max(line-1, 0), len(lines)-1)
+		start := utf16Len(lines[line][:min(max(column-1, 0), len(lines[line]))])
+		end := max(utf16Len(lines[line]), start)
+		return Range{Start: Position{Line: line, Character: start}, End: Position{Line: line, Character: end}}
+	}
+
+	diagnostics := []Diagnostic{}
+	issues, err := s.linter.LintSource(filename, []byte(text))
+	if err != nil {
+		line := 1
+		if m := yamlErrorLine.FindStringSubmatch(err.Error()); m != nil {
+			line, _ = strconv.Atoi(m[1])
+		}
+		return append(diagnostics, Diagnostic{
+			Range:    at(line, 1),
+			Severity: severityError,
+			Code:     "yaml",
+			Source:   "tavern",
+			Message:  err.Error(),
+		})
+	}
+
+	for _, issue := range issues {
+		severity := severityError
+		if issue.Severity == lint.SeverityWarning {
+			severity = severityWarning
+		}
+		diagnostics = append(diagnostics, Diagnostic{
+			Range:    at(issue.Line, issue.Column),
+			Severity: severity,
+			Code:     issue.Rule,
+			Source:   "tavern",
+			Message:  issue.Message,
+		})
+	}
+	return diagnostics
+}
+
+// uriFilename returns the path of a file:// URI, so includes resolve relative to it
+func uriFilename(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return u.Path
+}
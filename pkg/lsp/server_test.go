@@ -0,0 +1,236 @@
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/extension"
+)
+
+const testURI = "file:///tmp/test_lsp.tavern.yaml"
+
+// session runs the server over the given client messages and returns the messages it sent back
+func session(t *testing.T, messages ...map[string]interface{}) []*message {
+	t.Helper()
+
+	var in bytes.Buffer
+	for _, msg := range messages {
+		msg["jsonrpc"] = "2.0"
+		body, err := json.Marshal(msg)
+		require.NoError(t, err)
+		fmt.Fprintf(&in, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+
+	server, err := NewServer(Options{})
+	require.NoError(t, err)
+	var out bytes.Buffer
+	require.NoError(t, server.Run(&in, &out))
+
+	var sent []*message
+	reader := bufio.NewReader(&out)
+	for {
+		msg, err := readMessage(reader)
+		if err == io.EOF {
+			return sent
+		}
+		require.NoError(t, err)
+		sent = append(sent, msg)
+	}
+}
+
+// decodeParams decodes the params of a sent notification
+func decodeParams(t *testing.T, msg *message, v interface{}) {
+	t.Helper()
+	require.NoError(t, json.Unmarshal(msg.Params, v))
+}
+
+// completionLabels returns the labels of the completions at the | marker in text
+func completionLabels(t *testing.T, server *Server, text string) []string {
+	t.Helper()
+	var pos Position
+	for i, line := range strings.Split(text, "\n") {
+		if col := strings.Index(line, "|"); col != -1 {
+			pos = Position{Line: i, Character: col}
+		}
+	}
+	items := server.complete("/tmp/test_lsp.tavern.yaml", strings.Replace(text, "|", "", 1), pos)
+	labels := make([]string, len(items))
+	for i, item := range items {
+		labels[i] = item.Label
+	}
+	return labels
+}
+
+// TestServer_Session tests initialize, diagnostics on open and change, completion and shutdown over the wire
+func TestServer_Session(t *testing.T) {
+	sent := session(t,
+		map[string]interface{}{"id": 1, "method": "initialize", "params": map[string]interface{}{}},
+		map[string]interface{}{"method": "initialized", "params": map[string]interface{}{}},
+		map[string]interface{}{"method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": testURI, "languageId": "yaml", "version": 1, "text": "test_name: Session\nstages:\n  - name: get\n    request:\n      url: \"{host}/items\"\n    response:\n      status_code: 200\n"},
+		}},
+		map[string]interface{}{"method": "textDocument/didChange", "params": map[string]interface{}{
+			"textDocument":   map[string]interface{}{"uri": testURI, "version": 2},
+			"contentChanges": []map[string]interface{}{{"text": "test_name: Session\nstages:\n  - name: [unclosed\n"}},
+		}},
+		map[string]interface{}{"id": 2, "method": "textDocument/completion", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": testURI},
+			"position":     map[string]interface{}{"line": 0, "character": 0},
+		}},
+		map[string]interface{}{"id": 3, "method": "textDocument/hover", "params": map[string]interface{}{}},
+		map[string]interface{}{"method": "textDocument/didClose", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": testURI},
+		}},
+		map[string]interface{}{"id": 4, "method": "shutdown"},
+		map[string]interface{}{"method": "exit"},
+	)
+	require.Len(t, sent, 7)
+
+	// initialize
+	result, err := json.Marshal(sent[0].Result)
+	require.NoError(t, err)
+	var init initializeResult
+	require.NoError(t, json.Unmarshal(result, &init))
+	assert.Equal(t, 1, init.Capabilities.TextDocumentSync)
+	assert.Equal(t, []string{"!", "{"}, init.Capabilities.CompletionProvider.TriggerCharacters)
+
+	// didOpen: {host} is not defined
+	assert.Equal(t, "textDocument/publishDiagnostics", sent[1].Method)
+	var opened PublishDiagnosticsParams
+	decodeParams(t, sent[1], &opened)
+	require.Len(t, opened.Diagnostics, 1)
+	assert.Equal(t, "undefined-variable", opened.Diagnostics[0].Code)
+	assert.Equal(t, severityError, opened.Diagnostics[0].Severity)
+	assert.Equal(t, Range{Start: Position{Line: 4, Character: 11}, End: Position{Line: 4, Character: 25}}, opened.Diagnostics[0].Range)
+
+	// didChange: YAML syntax error
+	var changed PublishDiagnosticsParams
+	decodeParams(t, sent[2], &changed)
+	require.Len(t, changed.Diagnostics, 1)
+	assert.Equal(t, "yaml", changed.Diagnostics[0].Code)
+
+	// completion of top-level keys
+	items, err := json.Marshal(sent[3].Result)
+	require.NoError(t, err)
+	assert.Contains(t, string(items), `"label":"test_name"`)
+
+	// unsupported request
+	require.NotNil(t, sent[4].Error)
+	assert.Equal(t, codeMethodNotFound, sent[4].Error.Code)
+
+	// didClose clears diagnostics
+	var closed PublishDiagnosticsParams
+	decodeParams(t, sent[5], &closed)
+	assert.Equal(t, testURI, closed.URI)
+	assert.Empty(t, closed.Diagnostics)
+
+	// shutdown
+	assert.Nil(t, sent[6].Error)
+}
+
+// TestServer_CompleteKeys tests key completion from the test schema at different nesting levels
+func TestServer_CompleteKeys(t *testing.T) {
+	server, err := NewServer(Options{})
+	require.NoError(t, err)
+
+	assert.Contains(t, completionLabels(t, server, "|"), "stages")
+	assert.Contains(t, completionLabels(t, server, "test_name: Keys\nst|"), "includes")
+
+	stage := completionLabels(t, server, "test_name: Keys\nstages:\n  - name: first\n    |")
+	assert.Contains(t, stage, "request")
+	assert.Contains(t, stage, "delay_before")
+	assert.NotContains(t, stage, "stages")
+
+	newStage := completionLabels(t, server, "test_name: Keys\nstages:\n  - name: first\n  - |")
+	assert.Contains(t, newStage, "response")
+
+	request := completionLabels(t, server, "test_name: Keys\nstages:\n  - name: first\n    request:\n      url: http://localhost\n      # comment\n      |")
+	assert.Contains(t, request, "method")
+	assert.Contains(t, request, "headers")
+	assert.NotContains(t, request, "status_code")
+
+	save := completionLabels(t, server, "stages:\n  - name: first\n    response:\n      save:\n        |")
+	assert.ElementsMatch(t, []string{"$ext", "body", "headers", "redirect_query_params", "data"}, save)
+
+	ext := completionLabels(t, server, "stages:\n  - response:\n      save:\n        $ext:\n          |")
+	assert.ElementsMatch(t, []string{"function", "extra_args", "extra_kwargs"}, ext)
+
+	grpc := completionLabels(t, server, "stages:\n  - name: call\n    grpc_request:\n      |")
+	assert.Contains(t, grpc, "service")
+	assert.Contains(t, grpc, "proto_files")
+
+	websocket := completionLabels(t, server, "stages:\n  - name: subscribe\n    websocket:\n      |")
+	assert.Contains(t, websocket, "connect")
+	assert.Contains(t, websocket, "expect")
+	expect := completionLabels(t, server, "stages:\n  - name: subscribe\n    websocket:\n      expect:\n        - |")
+	assert.ElementsMatch(t, []string{"text", "json", "save"}, expect)
+
+	// Free-form mappings have no key suggestions
+	assert.Empty(t, completionLabels(t, server, "stages:\n  - request:\n      headers:\n        |"))
+}
+
+// TestServer_CompleteTagsAndFunctions tests completion of custom tags and extension function names
+func TestServer_CompleteTagsAndFunctions(t *testing.T) {
+	extension.RegisterValidator("test_lsp_validator", func(*http.Response) error { return nil })
+
+	server, err := NewServer(Options{})
+	require.NoError(t, err)
+
+	tags := completionLabels(t, server, "stages:\n  - response:\n      body:\n        id: !|")
+	assert.Contains(t, tags, "!anyint")
+	assert.Contains(t, tags, "!approx")
+	assert.Contains(t, tags, "!include")
+
+	assert.Contains(t, completionLabels(t, server, "includes:\n  - !inc|"), "!include")
+
+	functions := completionLabels(t, server, "stages:\n  - response:\n      save:\n        $ext:\n          function: |")
+	assert.Contains(t, functions, "test_lsp_validator")
+}
+
+// TestServer_CompleteVariables tests that variables from globals, includes and earlier saves are offered
+func TestServer_CompleteVariables(t *testing.T) {
+	server, err := NewServer(Options{Variables: []string{"base_url"}})
+	require.NoError(t, err)
+
+	text := `test_name: Variables
+includes:
+  - name: common
+    description: Common variables
+    variables:
+      user: alice
+stages:
+  - name: login
+    request:
+      url: "{base_url}/login"
+    response:
+      status_code: 200
+      save:
+        body:
+          token: token
+  - name: profile
+    request:
+      url: "{base_url}/me"
+      headers:
+        Authorization: "Bearer {to|"
+    response:
+      status_code: 200
+      save:
+        body:
+          name: name
+`
+	variables := completionLabels(t, server, text)
+	assert.Equal(t, []string{"base_url", "tavern.env_vars.", "user", "token"}, variables)
+
+	// Saves are only in scope for later stages
+	first := completionLabels(t, server, strings.Replace(strings.Replace(text, "{to|", "{to", 1), `"{base_url}/login"`, `"{|`, 1))
+	assert.NotContains(t, first, "token")
+	assert.Contains(t, first, "user")
+}
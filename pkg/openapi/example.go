@@ -0,0 +1,181 @@
+package openapi
+
+import "sort"
+
+// maxExampleDepth stops example generation for deeply nested (or recursive) schemas
+const maxExampleDepth = 8
+
+// Example returns an example value for a schema whose $refs have been resolved
+// It uses example, default and enum values when present, and otherwise a
+// placeholder of the schema's type. Objects include their required properties,
+// or all properties when none are required.
+func Example(schema map[string]interface{}) interface{} {
+	return example(schema, 0)
+}
+
+func example(schema map[string]interface{}, depth int) interface{} {
+	if schema == nil || depth > maxExampleDepth {
+		return nil
+	}
+
+	if v, ok := schema["example"]; ok {
+		return v
+	}
+	if examples, ok := schema["examples"].([]interface{}); ok && len(examples) > 0 {
+		return examples[0]
+	}
+	if v, ok := schema["default"]; ok {
+		return v
+	}
+	if enum, ok := schema["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+
+	schema = Flatten(schema)
+
+	switch SchemaType(schema) {
+	case "object":
+		properties, _ := schema["properties"].(map[string]interface{})
+		names := RequiredProperties(schema)
+		if len(names) == 0 {
+			for name := range properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+		}
+		obj := make(map[string]interface{}, len(names))
+		for _, name := range names {
+			propSchema, _ := properties[name].(map[string]interface{})
+			obj[name] = example(propSchema, depth+1)
+		}
+		return obj
+	case "array":
+		items, _ := schema["items"].(map[string]interface{})
+		return []interface{}{example(items, depth+1)}
+	case "integer":
+		return 1
+	case "number":
+		return 1.5
+	case "boolean":
+		return true
+	case "string":
+		return stringExample(schema)
+	default:
+		return nil
+	}
+}
+
+// stringExample returns a placeholder for a string schema, based on its format
+func stringExample(schema map[string]interface{}) string {
+	switch schema["format"] {
+	case "date":
+		return "2024-01-01"
+	case "date-time":
+		return "2024-01-01T00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	default:
+		return "string"
+	}
+}
+
+// Flatten merges allOf subschemas into one, and picks the first oneOf/anyOf alternative
+func Flatten(schema map[string]interface{}) map[string]interface{} {
+	if allOf, ok := schema["allOf"].([]interface{}); ok {
+		merged := make(map[string]interface{})
+		properties := make(map[string]interface{})
+		var required []interface{}
+		for k, v := range schema {
+			if k != "allOf" {
+				merged[k] = v
+			}
+		}
+		for _, sub := range allOf {
+			subMap, ok := sub.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			subMap = Flatten(subMap)
+			for k, v := range subMap {
+				switch k {
+				case "properties":
+					props, _ := v.(map[string]interface{})
+					for name, p := range props {
+						properties[name] = p
+					}
+				case "required":
+					list, _ := v.([]interface{})
+					required = append(required, list...)
+				default:
+					merged[k] = v
+				}
+			}
+		}
+		if props, ok := merged["properties"].(map[string]interface{}); ok {
+			for name, p := range props {
+				properties[name] = p
+			}
+		}
+		if list, ok := merged["required"].([]interface{}); ok {
+			required = append(required, list...)
+		}
+		if len(properties) > 0 {
+			merged["properties"] = properties
+			if _, ok := merged["type"]; !ok {
+				merged["type"] = "object"
+			}
+		}
+		if len(required) > 0 {
+			merged["required"] = required
+		}
+		return merged
+	}
+
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if alternatives, ok := schema[key].([]interface{}); ok && len(alternatives) > 0 {
+			if first, ok := alternatives[0].(map[string]interface{}); ok {
+				return Flatten(first)
+			}
+		}
+	}
+
+	return schema
+}
+
+// SchemaType returns the schema's type, inferring object from properties
+// OpenAPI 3.1 type lists return their first non-null entry
+func SchemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok && s != "null" {
+				return s
+			}
+		}
+	}
+	if _, ok := schema["properties"]; ok {
+		return "object"
+	}
+	return ""
+}
+
+// RequiredProperties returns the sorted required property names of an object schema
+func RequiredProperties(schema map[string]interface{}) []string {
+	list, _ := schema["required"].([]interface{})
+	names := make([]string, 0, len(list))
+	seen := make(map[string]bool, len(list))
+	for _, item := range list {
+		if name, ok := item.(string); ok && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,78 @@
+package openapi
+
+import (
+	"strings"
+)
+
+// Resolve follows $ref chains at the top level of v
+// Only local references (#/...) are supported; unresolvable references return nil
+func (s *Spec) Resolve(v interface{}) interface{} {
+	seen := map[string]bool{}
+	for {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return v
+		}
+		ref, ok := m["$ref"].(string)
+		if !ok {
+			return v
+		}
+		if seen[ref] {
+			return nil
+		}
+		seen[ref] = true
+		v = s.lookup(ref)
+	}
+}
+
+// ResolveDeep returns a copy of v with every local $ref replaced by its target
+// Recursive references are cut off with an empty schema
+func (s *Spec) ResolveDeep(v interface{}) interface{} {
+	return s.resolveDeep(v, map[string]bool{})
+}
+
+func (s *Spec) resolveDeep(v interface{}, stack map[string]bool) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := val["$ref"].(string); ok {
+			if stack[ref] {
+				return map[string]interface{}{}
+			}
+			stack[ref] = true
+			resolved := s.resolveDeep(s.lookup(ref), stack)
+			delete(stack, ref)
+			return resolved
+		}
+		result := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			result[k] = s.resolveDeep(item, stack)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(val))
+		for i, item := range val {
+			result[i] = s.resolveDeep(item, stack)
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// lookup returns the value at a local JSON pointer reference such as #/components/schemas/User
+func (s *Spec) lookup(ref string) interface{} {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+
+	var current interface{} = s.Doc
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[token]
+	}
+	return current
+}
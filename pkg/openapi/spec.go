@@ -0,0 +1,322 @@
+// Package openapi loads OpenAPI 3 documents and matches requests to their operations
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/util"
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// httpMethods are the operation keys of a path item, in output order
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Spec is a loaded OpenAPI 3 document
+type Spec struct {
+	Doc        map[string]interface{} // Raw document, with $refs left in place
+	Operations []*Operation
+
+	validator validator // Compiled response schemas, see ValidateResponse
+}
+
+// Operation is a single method + path of the API
+type Operation struct {
+	Method      string // Upper case, e.g. GET
+	Path        string // Path template, e.g. /users/{id}
+	OperationID string
+	Summary     string
+	Parameters  []*Parameter
+	RequestBody map[string]interface{}            // Request body object, $refs resolved
+	Responses   map[string]map[string]interface{} // Status code (or "default", "2XX") -> response object, $refs resolved
+
+	pathPattern *regexp.Regexp
+	literals    int // Number of literal path segments, used to prefer /users/me over /users/{id}
+}
+
+// Parameter is an operation parameter
+type Parameter struct {
+	Name     string
+	In       string // path, query, header or cookie
+	Required bool
+	Schema   map[string]interface{}
+	Example  interface{}
+}
+
+// Load reads an OpenAPI 3 document from a YAML or JSON file
+func Load(filename string) (*Spec, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI document: %w", err)
+	}
+	return Parse(data)
+}
+
+// Parse parses an OpenAPI 3 document from YAML or JSON
+func Parse(data []byte) (*Spec, error) {
+	var doc map[string]interface{}
+	if err := goyaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI document: %w", err)
+	}
+
+	version, _ := doc["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("unsupported OpenAPI version %q (expected 3.x)", version)
+	}
+
+	spec := &Spec{Doc: doc}
+	paths, _ := doc["paths"].(map[string]interface{})
+
+	pathNames := make([]string, 0, len(paths))
+	for path := range paths {
+		pathNames = append(pathNames, path)
+	}
+	sort.Strings(pathNames)
+
+	for _, path := range pathNames {
+		item, ok := spec.Resolve(paths[path]).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		pathParams := spec.parameters(item["parameters"])
+
+		for _, method := range httpMethods {
+			opMap, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			op, err := spec.newOperation(strings.ToUpper(method), path, opMap, pathParams)
+			if err != nil {
+				return nil, err
+			}
+			spec.Operations = append(spec.Operations, op)
+		}
+	}
+
+	return spec, nil
+}
+
+// newOperation builds an operation, merging path-level parameters
+func (s *Spec) newOperation(method, path string, opMap map[string]interface{}, pathParams []*Parameter) (*Operation, error) {
+	op := &Operation{
+		Method:    method,
+		Path:      path,
+		Responses: make(map[string]map[string]interface{}),
+	}
+	op.OperationID, _ = opMap["operationId"].(string)
+	op.Summary, _ = opMap["summary"].(string)
+
+	// Operation parameters override path-level ones with the same name and location
+	params := s.parameters(opMap["parameters"])
+	for _, p := range pathParams {
+		overridden := false
+		for _, op := range params {
+			if op.Name == p.Name && op.In == p.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			params = append(params, p)
+		}
+	}
+	op.Parameters = params
+
+	if body, ok := s.Resolve(opMap["requestBody"]).(map[string]interface{}); ok {
+		op.RequestBody = body
+	}
+
+	if responses, ok := opMap["responses"].(map[string]interface{}); ok {
+		for status, resp := range responses {
+			if respMap, ok := s.Resolve(resp).(map[string]interface{}); ok {
+				op.Responses[status] = respMap
+			}
+		}
+	}
+
+	pattern, literals, err := compilePathTemplate(path)
+	if err != nil {
+		return nil, err
+	}
+	op.pathPattern = pattern
+	op.literals = literals
+
+	return op, nil
+}
+
+// parameters resolves a list of parameter objects
+func (s *Spec) parameters(raw interface{}) []*Parameter {
+	list, _ := raw.([]interface{})
+	var params []*Parameter
+	for _, item := range list {
+		pMap, ok := s.Resolve(item).(map[string]interface{})
+		if !ok {
+			continue
+		}
+		p := &Parameter{}
+		p.Name, _ = pMap["name"].(string)
+		p.In, _ = pMap["in"].(string)
+		p.Required, _ = pMap["required"].(bool)
+		p.Schema, _ = s.Resolve(pMap["schema"]).(map[string]interface{})
+		p.Example = pMap["example"]
+		params = append(params, p)
+	}
+	return params
+}
+
+// compilePathTemplate turns /users/{id} into a regular expression
+func compilePathTemplate(path string) (*regexp.Regexp, int, error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	literals := 0
+	var b strings.Builder
+	b.WriteString("^")
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		b.WriteString("/")
+		if strings.Contains(segment, "{") {
+			// Templated segment, possibly with a literal prefix/suffix such as {id}.json
+			parts := regexp.MustCompile(`\{[^}]+\}`).Split(segment, -1)
+			for i, part := range parts {
+				if i > 0 {
+					b.WriteString("[^/]+")
+				}
+				b.WriteString(regexp.QuoteMeta(part))
+			}
+		} else {
+			literals++
+			b.WriteString(regexp.QuoteMeta(segment))
+		}
+	}
+	b.WriteString("/?$")
+
+	pattern, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid path template %s: %w", path, err)
+	}
+	return pattern, literals, nil
+}
+
+// BasePath returns the path of the first server URL (e.g. "/v1"), or ""
+func (s *Spec) BasePath() string {
+	server := s.ServerURL()
+	if server == "" {
+		return ""
+	}
+	u, err := url.Parse(server)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimRight(u.Path, "/")
+}
+
+// ServerURL returns the URL of the first server, or ""
+func (s *Spec) ServerURL() string {
+	servers, _ := s.Doc["servers"].([]interface{})
+	if len(servers) == 0 {
+		return ""
+	}
+	server, _ := servers[0].(map[string]interface{})
+	serverURL, _ := server["url"].(string)
+	return serverURL
+}
+
+// FindOperation returns the operation matching a request method and URL path
+// The first server's base path is stripped if present. When several templates
+// match, the one with the most literal segments wins.
+func (s *Spec) FindOperation(method, path string) *Operation {
+	method = strings.ToUpper(method)
+	candidates := []string{path}
+	if base := s.BasePath(); base != "" && strings.HasPrefix(path, base) {
+		candidates = append([]string{strings.TrimPrefix(path, base)}, candidates...)
+	}
+
+	for _, candidate := range candidates {
+		if candidate == "" {
+			candidate = "/"
+		}
+		var best *Operation
+		for _, op := range s.Operations {
+			if op.Method != method || !op.pathPattern.MatchString(candidate) {
+				continue
+			}
+			if best == nil || op.literals > best.literals {
+				best = op
+			}
+		}
+		if best != nil {
+			return best
+		}
+	}
+	return nil
+}
+
+// Name returns a file-name friendly identifier for the operation
+func (op *Operation) Name() string {
+	name := op.OperationID
+	if name == "" {
+		name = op.Method + " " + op.Path
+	}
+
+	return util.SnakeCase(name)
+}
+
+// SuccessStatus returns the lowest documented 2xx status code, or 200
+func (op *Operation) SuccessStatus() int {
+	best := 0
+	for status := range op.Responses {
+		var code int
+		if _, err := fmt.Sscanf(status, "%d", &code); err != nil || len(status) != 3 {
+			continue
+		}
+		if code >= 200 && code < 300 && (best == 0 || code < best) {
+			best = code
+		}
+	}
+	if best == 0 {
+		return 200
+	}
+	return best
+}
+
+// Response returns the response object for a status code, falling back to
+// range keys such as "2XX" and then "default"
+func (op *Operation) Response(status int) (map[string]interface{}, bool) {
+	if resp, ok := op.Responses[fmt.Sprintf("%d", status)]; ok {
+		return resp, true
+	}
+	if resp, ok := op.Responses[fmt.Sprintf("%dXX", status/100)]; ok {
+		return resp, true
+	}
+	if resp, ok := op.Responses[fmt.Sprintf("%dxx", status/100)]; ok {
+		return resp, true
+	}
+	resp, ok := op.Responses["default"]
+	return resp, ok
+}
+
+// JSONSchema returns the schema of the application/json (or +json) content of a
+// request body or response object, or nil
+func JSONSchema(obj map[string]interface{}) map[string]interface{} {
+	content, _ := obj["content"].(map[string]interface{})
+	keys := make([]string, 0, len(content))
+	for mediaType := range content {
+		keys = append(keys, mediaType)
+	}
+	sort.Strings(keys)
+	for _, mediaType := range keys {
+		base := strings.TrimSpace(strings.Split(mediaType, ";")[0])
+		if base != "application/json" && !strings.HasSuffix(base, "+json") {
+			continue
+		}
+		media, _ := content[mediaType].(map[string]interface{})
+		schema, _ := media["schema"].(map[string]interface{})
+		return schema
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package openapi
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLoad_Operations tests that operations are loaded with merged parameters and resolved responses
+func TestLoad_Operations(t *testing.T) {
+	spec, err := Load("testdata/users.yaml")
+	require.NoError(t, err)
+	require.Len(t, spec.Operations, 2)
+
+	create := spec.Operations[0]
+	assert.Equal(t, "POST", create.Method)
+	assert.Equal(t, "/users", create.Path)
+	assert.Equal(t, "create_user", create.Name())
+	assert.Equal(t, 201, create.SuccessStatus())
+
+	get := spec.Operations[1]
+	assert.Equal(t, "GET", get.Method)
+	assert.Equal(t, "get_user", get.Name())
+	require.Len(t, get.Parameters, 2)
+	assert.Equal(t, "expand", get.Parameters[0].Name)
+	assert.Equal(t, "id", get.Parameters[1].Name, "Path-level parameters are merged")
+	assert.Equal(t, "path", get.Parameters[1].In)
+
+	resp, ok := get.Response(200)
+	require.True(t, ok)
+	assert.NotNil(t, JSONSchema(resp))
+}
+
+// TestParse_RejectsSwagger2 tests that only OpenAPI 3 documents are accepted
+func TestParse_RejectsSwagger2(t *testing.T) {
+	_, err := Parse([]byte("swagger: '2.0'\npaths: {}\n"))
+	assert.ErrorContains(t, err, "unsupported OpenAPI version")
+}
+
+// TestFindOperation tests matching of request paths to operations
+func TestFindOperation(t *testing.T) {
+	spec, err := Parse([]byte(`
+openapi: 3.0.0
+servers: [{url: "https://api.example.com/v1"}]
+paths:
+  /users/{id}:
+    get: {responses: {"200": {description: ok}}}
+  /users/me:
+    get: {responses: {"200": {description: ok}}}
+  /files/{name}.json:
+    get: {responses: {"200": {description: ok}}}
+`))
+	require.NoError(t, err)
+
+	op := spec.FindOperation("get", "/v1/users/42")
+	require.NotNil(t, op)
+	assert.Equal(t, "/users/{id}", op.Path)
+
+	op = spec.FindOperation("GET", "/users/me")
+	require.NotNil(t, op)
+	assert.Equal(t, "/users/me", op.Path, "Literal segments win over templates")
+
+	op = spec.FindOperation("GET", "/files/report.json")
+	require.NotNil(t, op)
+	assert.Equal(t, "/files/{name}.json", op.Path)
+
+	assert.Nil(t, spec.FindOperation("DELETE", "/users/42"))
+	assert.Nil(t, spec.FindOperation("GET", "/users/42/posts"))
+}
+
+// TestResolveDeep_Recursive tests that recursive schemas are cut off
+func TestResolveDeep_Recursive(t *testing.T) {
+	spec, err := Load("testdata/users.yaml")
+	require.NoError(t, err)
+
+	resolved := spec.ResolveDeep(map[string]interface{}{"$ref": "#/components/schemas/User"}).(map[string]interface{})
+	allOf := resolved["allOf"].([]interface{})
+	profile := allOf[1].(map[string]interface{})["properties"].(map[string]interface{})["profile"].(map[string]interface{})
+	manager := profile["properties"].(map[string]interface{})["manager"]
+	assert.Equal(t, map[string]interface{}{}, manager)
+}
+
+// TestExample tests example generation from schemas
+func TestExample(t *testing.T) {
+	spec, err := Load("testdata/users.yaml")
+	require.NoError(t, err)
+
+	schema := spec.ResolveDeep(map[string]interface{}{"$ref": "#/components/schemas/User"}).(map[string]interface{})
+	example := Example(schema).(map[string]interface{})
+
+	assert.Equal(t, "Alice", example["name"])
+	assert.Equal(t, "user@example.com", example["email"])
+	assert.Equal(t, 1, example["id"])
+	assert.Equal(t, []interface{}{"string"}, example["tags"])
+	assert.Equal(t, map[string]interface{}{"verified": true}, example["profile"])
+	assert.NotContains(t, example, "age", "Only required properties are included")
+}
@@ -0,0 +1,285 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// Parts of a response checked by ValidateResponse
+const (
+	PartStatus  = "status"
+	PartHeaders = "headers"
+	PartBody    = "body"
+)
+
+// componentSchemaRef is the prefix of references to reusable schemas
+const componentSchemaRef = "#/components/schemas/"
+
+// Violation is a single way in which a response breaks its OpenAPI contract
+type Violation struct {
+	Part     string      // status, headers or body
+	Path     string      // Header name or dotted body path, empty for the whole part
+	Expected interface{} // Documented value or constraint
+	Actual   interface{} // Value found in the response
+	Message  string
+}
+
+// validator caches compiled JSON schemas per operation response
+type validator struct {
+	mu      sync.Mutex
+	schemas map[string]*gojsonschema.Schema
+}
+
+// ValidateResponse checks a response against the operation matching method and
+// path. It returns the matched operation (nil if none matches) and any
+// violations; the error is only set when the document's schema cannot be compiled.
+func (s *Spec) ValidateResponse(method, path string, status int, header http.Header, body []byte) (*Operation, []Violation, error) {
+	op := s.FindOperation(method, path)
+	if op == nil {
+		return nil, nil, nil
+	}
+
+	resp, ok := op.Response(status)
+	if !ok {
+		return op, []Violation{{
+			Part:     PartStatus,
+			Expected: op.documentedStatuses(),
+			Actual:   status,
+			Message: fmt.Sprintf("status code %d is not documented for %s %s",
+				status, op.Method, op.Path),
+		}}, nil
+	}
+
+	violations := s.validateHeaders(resp, header)
+
+	bodySchema := JSONSchema(resp)
+	if bodySchema == nil {
+		return op, violations, nil
+	}
+	compiled, err := s.compile(fmt.Sprintf("%s %s %d", op.Method, op.Path, status), bodySchema)
+	if err != nil {
+		return op, violations, fmt.Errorf("invalid response schema for %s %s: %w", op.Method, op.Path, err)
+	}
+	return op, append(violations, validateBody(compiled, body)...), nil
+}
+
+// documentedStatuses lists the documented response keys in order
+func (op *Operation) documentedStatuses() []string {
+	statuses := make([]string, 0, len(op.Responses))
+	for status := range op.Responses {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+// validateHeaders checks that required response headers are present and that
+// header values match simple scalar schemas
+func (s *Spec) validateHeaders(resp map[string]interface{}, header http.Header) []Violation {
+	headers, _ := resp["headers"].(map[string]interface{})
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var violations []Violation
+	for _, name := range names {
+		// Content-Type is described by the content map, not headers
+		if strings.EqualFold(name, "Content-Type") {
+			continue
+		}
+		def, _ := s.Resolve(headers[name]).(map[string]interface{})
+		required, _ := def["required"].(bool)
+
+		values, present := header[http.CanonicalHeaderKey(name)]
+		if !present {
+			if required {
+				violations = append(violations, Violation{
+					Part:     PartHeaders,
+					Path:     name,
+					Expected: name,
+					Message:  fmt.Sprintf("required header %q is missing", name),
+				})
+			}
+			continue
+		}
+
+		headerSchema, _ := s.Resolve(def["schema"]).(map[string]interface{})
+		if !headerValueMatches(SchemaType(headerSchema), values[0]) {
+			violations = append(violations, Violation{
+				Part:     PartHeaders,
+				Path:     name,
+				Expected: SchemaType(headerSchema),
+				Actual:   values[0],
+				Message:  fmt.Sprintf("header %q value %q is not of type %s", name, values[0], SchemaType(headerSchema)),
+			})
+		}
+	}
+	return violations
+}
+
+// headerValueMatches reports whether a header string can be read as the schema type
+func headerValueMatches(schemaType, value string) bool {
+	switch schemaType {
+	case "integer":
+		_, err := strconv.ParseInt(value, 10, 64)
+		return err == nil
+	case "number":
+		_, err := strconv.ParseFloat(value, 64)
+		return err == nil
+	case "boolean":
+		_, err := strconv.ParseBool(value)
+		return err == nil
+	default:
+		return true
+	}
+}
+
+// validateBody validates a JSON body against a compiled schema
+func validateBody(compiled *gojsonschema.Schema, body []byte) []Violation {
+	if len(body) == 0 {
+		return []Violation{{
+			Part:    PartBody,
+			Message: "response body is empty but the contract documents a JSON body",
+		}}
+	}
+
+	var data interface{}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return []Violation{{
+			Part:    PartBody,
+			Actual:  string(body),
+			Message: fmt.Sprintf("response body is not valid JSON: %v", err),
+		}}
+	}
+
+	result, err := compiled.Validate(gojsonschema.NewGoLoader(data))
+	if err != nil {
+		return []Violation{{Part: PartBody, Message: err.Error()}}
+	}
+
+	var violations []Violation
+	for _, e := range result.Errors() {
+		violations = append(violations, Violation{
+			Part:     PartBody,
+			Path:     bodyPath(e.Field()),
+			Expected: e.Details()["expected"],
+			Actual:   e.Value(),
+			Message:  e.String(),
+		})
+	}
+	return violations
+}
+
+// bodyPath converts a gojsonschema field such as "items.0.id" to the
+// validator's path notation "items[0].id"
+func bodyPath(field string) string {
+	if field == "(root)" {
+		return ""
+	}
+	var b strings.Builder
+	for i, segment := range strings.Split(field, ".") {
+		if _, err := strconv.Atoi(segment); err == nil {
+			b.WriteString("[" + segment + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(segment)
+	}
+	return b.String()
+}
+
+// compile converts an OpenAPI schema to JSON Schema and compiles it, caching by key
+func (s *Spec) compile(key string, schema map[string]interface{}) (*gojsonschema.Schema, error) {
+	s.validator.mu.Lock()
+	defer s.validator.mu.Unlock()
+
+	if compiled, ok := s.validator.schemas[key]; ok {
+		return compiled, nil
+	}
+
+	root, _ := toJSONSchema(schema).(map[string]interface{})
+	definitions := map[string]interface{}{}
+	if components, ok := s.Doc["components"].(map[string]interface{}); ok {
+		if schemas, ok := components["schemas"].(map[string]interface{}); ok {
+			for name, def := range schemas {
+				definitions[name] = toJSONSchema(def)
+			}
+		}
+	}
+	root["definitions"] = definitions
+
+	compiled, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(root))
+	if err != nil {
+		return nil, err
+	}
+	if s.validator.schemas == nil {
+		s.validator.schemas = make(map[string]*gojsonschema.Schema)
+	}
+	s.validator.schemas[key] = compiled
+	return compiled, nil
+}
+
+// openAPIKeywords are schema keywords that draft-04 JSON Schema does not understand
+var openAPIKeywords = map[string]bool{
+	"nullable": true, "example": true, "discriminator": true, "readOnly": true,
+	"writeOnly": true, "xml": true, "externalDocs": true, "deprecated": true,
+}
+
+// toJSONSchema converts an OpenAPI 3.0 schema object to draft-04 JSON Schema:
+// component references point at definitions and nullable adds "null" to the type
+func toJSONSchema(v interface{}) interface{} {
+	schema, ok := v.(map[string]interface{})
+	if !ok {
+		return v
+	}
+	if ref, ok := schema["$ref"].(string); ok && strings.HasPrefix(ref, componentSchemaRef) {
+		return map[string]interface{}{
+			"$ref": "#/definitions/" + strings.TrimPrefix(ref, componentSchemaRef),
+		}
+	}
+
+	result := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		if openAPIKeywords[key] {
+			continue
+		}
+		switch key {
+		case "properties", "patternProperties":
+			props, _ := value.(map[string]interface{})
+			converted := make(map[string]interface{}, len(props))
+			for name, prop := range props {
+				converted[name] = toJSONSchema(prop)
+			}
+			result[key] = converted
+		case "items", "additionalProperties", "not":
+			result[key] = toJSONSchema(value)
+		case "allOf", "anyOf", "oneOf":
+			list, _ := value.([]interface{})
+			converted := make([]interface{}, len(list))
+			for i, item := range list {
+				converted[i] = toJSONSchema(item)
+			}
+			result[key] = converted
+		default:
+			result[key] = value
+		}
+	}
+
+	if nullable, _ := schema["nullable"].(bool); nullable {
+		if t, ok := schema["type"].(string); ok {
+			result["type"] = []interface{}{t, "null"}
+		}
+	}
+	return result
+}
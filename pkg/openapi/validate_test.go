@@ -0,0 +1,76 @@
+package openapi
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const validUser = `{"id": 5, "name": "Alice", "email": "a@example.com", "tags": [],
+	"nickname": null, "profile": {"verified": true}}`
+
+// TestValidateResponse_Valid tests that a conforming response has no violations
+func TestValidateResponse_Valid(t *testing.T) {
+	spec, err := Load("testdata/users.yaml")
+	require.NoError(t, err)
+
+	header := http.Header{"X-Rate-Limit": []string{"100"}}
+	op, violations, err := spec.ValidateResponse("GET", "/v1/users/5", 200, header, []byte(validUser))
+	require.NoError(t, err)
+	require.NotNil(t, op)
+	assert.Equal(t, "/users/{id}", op.Path)
+	assert.Empty(t, violations)
+}
+
+// TestValidateResponse_Violations tests status, header and body violations
+func TestValidateResponse_Violations(t *testing.T) {
+	spec, err := Load("testdata/users.yaml")
+	require.NoError(t, err)
+
+	t.Run("undocumented status", func(t *testing.T) {
+		_, violations, err := spec.ValidateResponse("GET", "/v1/users/5", 404, nil, nil)
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		assert.Equal(t, PartStatus, violations[0].Part)
+		assert.Equal(t, []string{"200"}, violations[0].Expected)
+	})
+
+	t.Run("missing and mistyped headers", func(t *testing.T) {
+		_, violations, err := spec.ValidateResponse("GET", "/v1/users/5", 200, http.Header{}, []byte(validUser))
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		assert.Equal(t, PartHeaders, violations[0].Part)
+		assert.Equal(t, "X-Rate-Limit", violations[0].Path)
+
+		header := http.Header{"X-Rate-Limit": []string{"lots"}}
+		_, violations, err = spec.ValidateResponse("GET", "/v1/users/5", 200, header, []byte(validUser))
+		require.NoError(t, err)
+		require.Len(t, violations, 1)
+		assert.Contains(t, violations[0].Message, "not of type integer")
+	})
+
+	t.Run("body schema", func(t *testing.T) {
+		body := `{"id": "5", "name": "Alice", "email": "a@example.com", "tags": [1],
+			"profile": {"verified": true, "manager": {"name": "Bob"}}}`
+		_, violations, err := spec.ValidateResponse("POST", "/v1/users", 201, nil, []byte(body))
+		require.NoError(t, err)
+
+		paths := make([]string, 0, len(violations))
+		for _, v := range violations {
+			assert.Equal(t, PartBody, v.Part)
+			paths = append(paths, v.Path)
+		}
+		assert.Contains(t, paths, "id")
+		assert.Contains(t, paths, "tags[0]")
+		assert.Contains(t, paths, "profile.manager", "Recursive $refs are validated")
+	})
+
+	t.Run("unmatched operation", func(t *testing.T) {
+		op, violations, err := spec.ValidateResponse("DELETE", "/v1/users", 204, nil, nil)
+		require.NoError(t, err)
+		assert.Nil(t, op)
+		assert.Empty(t, violations)
+	})
+}
@@ -0,0 +1,203 @@
+// Package postman reads Postman collections (v2.0 and v2.1) and environments
+package postman
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Collection is a Postman collection
+type Collection struct {
+	Info     Info       `json:"info"`
+	Item     []*Item    `json:"item"`
+	Variable []Variable `json:"variable"`
+	Event    []Event    `json:"event"`
+	Auth     *Auth      `json:"auth"`
+}
+
+// Info holds the collection metadata
+type Info struct {
+	Name   string `json:"name"`
+	Schema string `json:"schema"`
+}
+
+// Item is either a folder (with Item set) or a request
+type Item struct {
+	Name    string   `json:"name"`
+	Item    []*Item  `json:"item"`
+	Request *Request `json:"request"`
+	Event   []Event  `json:"event"`
+	Auth    *Auth    `json:"auth"`
+}
+
+// IsFolder reports whether the item groups other items
+func (i *Item) IsFolder() bool {
+	return i.Request == nil
+}
+
+// Request is a Postman request
+type Request struct {
+	Method string `json:"method"`
+	Header []KV   `json:"header"`
+	URL    URL    `json:"url"`
+	Body   *Body  `json:"body"`
+	Auth   *Auth  `json:"auth"`
+}
+
+// URL is a request URL, given as a string or an object with a raw form
+type URL struct {
+	Raw string `json:"raw"`
+}
+
+// UnmarshalJSON accepts both the string and object forms
+func (u *URL) UnmarshalJSON(data []byte) error {
+	var raw string
+	if err := json.Unmarshal(data, &raw); err == nil {
+		u.Raw = raw
+		return nil
+	}
+	type alias URL
+	return json.Unmarshal(data, (*alias)(u))
+}
+
+// Body is a request body
+type Body struct {
+	Mode       string `json:"mode"` // raw, urlencoded, formdata, file or graphql
+	Raw        string `json:"raw"`
+	URLEncoded []KV   `json:"urlencoded"`
+	FormData   []KV   `json:"formdata"`
+}
+
+// KV is a key/value pair used for headers, form fields and auth attributes
+type KV struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Type     string      `json:"type"` // "file" for file form fields
+	Disabled bool        `json:"disabled"`
+}
+
+// String returns the value as a string
+func (kv KV) String() string {
+	if kv.Value == nil {
+		return ""
+	}
+	if s, ok := kv.Value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", kv.Value)
+}
+
+// Auth is a request, folder or collection auth setting
+type Auth struct {
+	Type   string `json:"type"` // noauth, basic, bearer, apikey, ...
+	Basic  []KV   `json:"basic"`
+	Bearer []KV   `json:"bearer"`
+}
+
+// Attribute returns an auth attribute (e.g. "token" or "username") of the auth type
+func (a *Auth) Attribute(key string) string {
+	var attrs []KV
+	switch a.Type {
+	case "basic":
+		attrs = a.Basic
+	case "bearer":
+		attrs = a.Bearer
+	}
+	for _, kv := range attrs {
+		if kv.Key == key {
+			return kv.String()
+		}
+	}
+	return ""
+}
+
+// Variable is a collection or environment variable
+type Variable struct {
+	Key      string      `json:"key"`
+	Value    interface{} `json:"value"`
+	Disabled bool        `json:"disabled"`
+	Enabled  *bool       `json:"enabled"` // Environments use enabled instead of disabled
+}
+
+// Active reports whether the variable is in use
+func (v Variable) Active() bool {
+	return !v.Disabled && (v.Enabled == nil || *v.Enabled)
+}
+
+// Event is a script attached to an item
+type Event struct {
+	Listen string `json:"listen"` // test or prerequest
+	Script Script `json:"script"`
+}
+
+// Script holds the lines of a script
+type Script struct {
+	Exec Lines `json:"exec"`
+}
+
+// Lines is a script body, given as a list of lines or a single string
+type Lines []string
+
+// UnmarshalJSON accepts both the list and string forms
+func (l *Lines) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = strings.Split(single, "\n")
+		return nil
+	}
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return err
+	}
+	// Elements may themselves contain newlines
+	*l = strings.Split(strings.Join(lines, "\n"), "\n")
+	return nil
+}
+
+// Environment is a Postman environment export
+type Environment struct {
+	Name   string     `json:"name"`
+	Values []Variable `json:"values"`
+}
+
+// LoadCollection reads a collection export
+func LoadCollection(filename string) (*Collection, error) {
+	var c Collection
+	if err := loadJSON(filename, &c); err != nil {
+		return nil, fmt.Errorf("failed to load Postman collection: %w", err)
+	}
+	if !strings.Contains(c.Info.Schema, "v2.") {
+		return nil, fmt.Errorf("unsupported Postman collection schema %q (expected v2.0 or v2.1)", c.Info.Schema)
+	}
+	return &c, nil
+}
+
+// LoadEnvironment reads an environment export
+func LoadEnvironment(filename string) (*Environment, error) {
+	var e Environment
+	if err := loadJSON(filename, &e); err != nil {
+		return nil, fmt.Errorf("failed to load Postman environment: %w", err)
+	}
+	return &e, nil
+}
+
+func loadJSON(filename string, v interface{}) error {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// Scripts returns the lines of all scripts listening to the given event
+func Scripts(events []Event, listen string) []string {
+	var lines []string
+	for _, e := range events {
+		if e.Listen == listen {
+			lines = append(lines, e.Script.Exec...)
+		}
+	}
+	return lines
+}
@@ -0,0 +1,242 @@
+package postman
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Body check kinds
+const (
+	CheckEquals = "equals" // Value must equal Value
+	CheckType   = "type"   // Value must have JSON type Type (string, number, boolean, ...)
+	CheckExists = "exists" // Value must be present
+)
+
+// BodyCheck is a JSON body assertion found in a test script
+type BodyCheck struct {
+	Path  string // Dotted path into the body, e.g. "data.items.0.id"
+	Kind  string // See Check* constants
+	Value interface{}
+	Type  string
+}
+
+// Save is a variable set from the response body in a test script
+type Save struct {
+	Variable string
+	Path     string
+}
+
+// TestScript is what could be understood of a test script
+type TestScript struct {
+	Status      []int
+	Body        []BodyCheck
+	Saves       []Save
+	Unsupported []string // Statements that could not be converted
+}
+
+// Script expressions. A JSON expression is pm.response.json() or an alias of it
+// followed by property and index accessors.
+const (
+	jsonExpr     = `(pm\.response\.json\(\)|[A-Za-z_$][\w$]*)((?:\.[A-Za-z_$][\w$]*|\[\d+\]|\[["'][^"'\]]+["']\])*)`
+	variableSets = `(?:pm\.(?:environment|collectionVariables|globals|variables)\.set|postman\.set(?:Environment|Global)Variable)`
+	variableGets = `pm\.(?:environment|collectionVariables|globals|variables)\.get\(["']([^"']+)["']\)`
+)
+
+var (
+	jsonAliasPattern  = regexp.MustCompile(`^(?:var|let|const)\s+([A-Za-z_$][\w$]*)\s*=\s*(?:pm\.response\.json\(\)|JSON\.parse\(responseBody\))$`)
+	statusPattern     = regexp.MustCompile(`^pm\.response\.to\.(?:have\.status|be\.status)\((\d{3})\)$`)
+	codeEqualsPattern = regexp.MustCompile(`^pm\.expect\(pm\.response\.code\)\.to\.(?:eql|equal|eq|be\.equal)\((\d{3})\)$`)
+	codeOneOfPattern  = regexp.MustCompile(`^pm\.expect\(pm\.response\.code\)\.to\.be\.oneOf\(\[([\d,\s]+)\]\)$`)
+	legacyCodePattern = regexp.MustCompile(`^tests\[.*\]\s*=\s*responseCode\.code\s*===?\s*(\d{3})$`)
+	expectPattern     = regexp.MustCompile(`^pm\.expect\(` + jsonExpr + `\)\.to\.(.+)$`)
+	savePattern       = regexp.MustCompile(`^` + variableSets + `\(["']([^"']+)["']\s*,\s*` + jsonExpr + `\)$`)
+	equalsPattern     = regexp.MustCompile(`^(?:eql|equal|eq|be\.equal|deep\.equal)\((.+)\)$`)
+	typePattern       = regexp.MustCompile(`^(?:be\.an?)\(["'](\w+)["']\)$`)
+	variableGetRegexp = regexp.MustCompile(`^` + variableGets + `$`)
+	accessorPattern   = regexp.MustCompile(`\.([A-Za-z_$][\w$]*)|\[(\d+)\]|\[["']([^"'\]]+)["']\]`)
+	testOpenPattern   = regexp.MustCompile(`^pm\.test\(.*(?:function\s*\(\)|\(\)\s*=>)\s*\{$`)
+	postmanVariable   = regexp.MustCompile(`\{\{[^{}]+\}\}`)
+)
+
+// ParseTests converts the statements of a test script that have a tavern
+// equivalent: status checks, JSON body checks and variables saved from the body
+func ParseTests(lines []string) *TestScript {
+	script := &TestScript{}
+	aliases := map[string]bool{}
+
+	for _, line := range lines {
+		stmt := strings.TrimSuffix(strings.TrimSpace(line), ";")
+		switch {
+		case stmt == "", strings.HasPrefix(stmt, "//"), stmt == "})", stmt == "}", testOpenPattern.MatchString(stmt):
+			continue
+		}
+
+		if m := jsonAliasPattern.FindStringSubmatch(stmt); m != nil {
+			aliases[m[1]] = true
+			continue
+		}
+		if code, ok := statusCode(stmt); ok {
+			script.Status = append(script.Status, code...)
+			continue
+		}
+		if m := expectPattern.FindStringSubmatch(stmt); m != nil && isJSON(m[1], aliases) {
+			if check, ok := bodyCheck(bodyPath(m[2]), m[3]); ok {
+				script.Body = append(script.Body, check)
+				continue
+			}
+		}
+		if m := savePattern.FindStringSubmatch(stmt); m != nil && isJSON(m[2], aliases) {
+			script.Saves = append(script.Saves, Save{Variable: m[1], Path: bodyPath(m[3])})
+			continue
+		}
+
+		script.Unsupported = append(script.Unsupported, strings.TrimSpace(line))
+	}
+
+	return script
+}
+
+// statusCode matches the supported status code assertions
+func statusCode(stmt string) ([]int, bool) {
+	for _, pattern := range []*regexp.Regexp{statusPattern, codeEqualsPattern, legacyCodePattern} {
+		if m := pattern.FindStringSubmatch(stmt); m != nil {
+			code, _ := strconv.Atoi(m[1])
+			return []int{code}, true
+		}
+	}
+	if m := codeOneOfPattern.FindStringSubmatch(stmt); m != nil {
+		var codes []int
+		for _, s := range strings.Split(m[1], ",") {
+			if code, err := strconv.Atoi(strings.TrimSpace(s)); err == nil {
+				codes = append(codes, code)
+			}
+		}
+		return codes, len(codes) > 0
+	}
+	return nil, false
+}
+
+// isJSON reports whether an expression root is the response JSON
+func isJSON(root string, aliases map[string]bool) bool {
+	return root == "pm.response.json()" || aliases[root]
+}
+
+// bodyPath converts accessors such as .data.items[0]["id"] to data.items.0.id
+func bodyPath(accessors string) string {
+	var parts []string
+	for _, m := range accessorPattern.FindAllStringSubmatch(accessors, -1) {
+		for _, group := range m[1:] {
+			if group != "" {
+				parts = append(parts, group)
+			}
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// bodyCheck converts the chai assertion following .to. into a body check
+func bodyCheck(path, assertion string) (BodyCheck, bool) {
+	if path == "" {
+		return BodyCheck{}, false
+	}
+	check := BodyCheck{Path: path}
+
+	switch assertion {
+	case "be.true":
+		check.Kind, check.Value = CheckEquals, true
+		return check, true
+	case "be.false":
+		check.Kind, check.Value = CheckEquals, false
+		return check, true
+	case "be.null":
+		check.Kind, check.Value = CheckEquals, nil
+		return check, true
+	case "exist", "not.be.undefined", "be.ok":
+		check.Kind = CheckExists
+		return check, true
+	}
+
+	if m := typePattern.FindStringSubmatch(assertion); m != nil {
+		check.Kind, check.Type = CheckType, strings.ToLower(m[1])
+		return check, true
+	}
+	if m := equalsPattern.FindStringSubmatch(assertion); m != nil {
+		value, ok := literal(strings.TrimSpace(m[1]))
+		if !ok {
+			return BodyCheck{}, false
+		}
+		check.Kind, check.Value = CheckEquals, value
+		return check, true
+	}
+	return BodyCheck{}, false
+}
+
+// literal parses a JavaScript literal, or a variable lookup which becomes a
+// tavern format string
+func literal(s string) (interface{}, bool) {
+	if m := variableGetRegexp.FindStringSubmatch(s); m != nil {
+		return "{" + m[1] + "}", true
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' && !strings.Contains(s[1:len(s)-1], "'") {
+		s = strconv.Quote(strings.ReplaceAll(s[1:len(s)-1], `\"`, `"`))
+	}
+	var value interface{}
+	if err := json.Unmarshal([]byte(s), &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// ConvertVariables turns Postman {{name}} references into tavern {name} references
+// Dynamic variables such as {{$guid}} have no equivalent and are reported
+func ConvertVariables(s string) (converted string, dynamic []string) {
+	converted = postmanVariable.ReplaceAllStringFunc(s, func(ref string) string {
+		name := strings.TrimSpace(ref[2 : len(ref)-2])
+		if strings.HasPrefix(name, "$") {
+			dynamic = append(dynamic, name)
+			return ref
+		}
+		return "{" + name + "}"
+	})
+	return converted, dynamic
+}
+
+// QuoteBareVariables quotes {{name}} references that appear outside JSON strings,
+// as in {"id": {{id}}}, so that the body parses as JSON. It returns the quoted names.
+func QuoteBareVariables(raw string) (string, []string) {
+	var b strings.Builder
+	var quoted []string
+	inString, escaped := false, false
+
+	for i := 0; i < len(raw); i++ {
+		c := raw[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			b.WriteByte(c)
+			continue
+		}
+		if c == '"' {
+			inString = true
+		}
+		if c == '{' && strings.HasPrefix(raw[i:], "{{") {
+			if end := strings.Index(raw[i:], "}}"); end > 0 {
+				ref := raw[i : i+end+2]
+				quoted = append(quoted, strings.TrimSpace(ref[2:len(ref)-2]))
+				b.WriteString(strconv.Quote(ref))
+				i += end + 1
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String(), quoted
+}
@@ -0,0 +1,74 @@
+package postman
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseTests tests which test script statements are converted
+func TestParseTests(t *testing.T) {
+	script := ParseTests([]string{
+		"const body = JSON.parse(responseBody);",
+		"pm.test('ok', function () {",
+		"  pm.response.to.have.status(200);",
+		"  pm.expect(pm.response.code).to.be.oneOf([200, 204]);",
+		"  pm.expect(body.data[\"user\"].name).to.eql(\"Alice\");",
+		"  pm.expect(body.tags[1]).to.equal(3);",
+		"  pm.expect(body.token).to.exist;",
+		"  pm.expect(body.admin).to.be.a('boolean');",
+		"  pm.expect(other.value).to.eql(1);",
+		"});",
+		"// a comment",
+		"postman.setEnvironmentVariable('token', body.token);",
+		"pm.collectionVariables.set(\"user_id\", pm.response.json().data.user.id);",
+		"console.log(body);",
+	})
+
+	assert.Equal(t, []int{200, 200, 204}, script.Status)
+	assert.Equal(t, []BodyCheck{
+		{Path: "data.user.name", Kind: CheckEquals, Value: "Alice"},
+		{Path: "tags.1", Kind: CheckEquals, Value: float64(3)},
+		{Path: "token", Kind: CheckExists},
+		{Path: "admin", Kind: CheckType, Type: "boolean"},
+	}, script.Body)
+	assert.Equal(t, []Save{
+		{Variable: "token", Path: "token"},
+		{Variable: "user_id", Path: "data.user.id"},
+	}, script.Saves)
+	assert.Equal(t, []string{
+		"pm.expect(other.value).to.eql(1);",
+		"console.log(body);",
+	}, script.Unsupported, "Checks on values other than the response JSON are not converted")
+}
+
+// TestConvertVariables tests {{var}} to {var} conversion
+func TestConvertVariables(t *testing.T) {
+	converted, dynamic := ConvertVariables("{{base_url}}/items/{{ id }}?r={{$randomInt}}")
+	assert.Equal(t, "{base_url}/items/{id}?r={{$randomInt}}", converted)
+	assert.Equal(t, []string{"$randomInt"}, dynamic)
+}
+
+// TestQuoteBareVariables tests that only variables outside JSON strings are quoted
+func TestQuoteBareVariables(t *testing.T) {
+	raw, quoted := QuoteBareVariables(`{"a": {{n}}, "b": "{{s}} \" {{t}}", "c": [{{m}}]}`)
+	assert.Equal(t, `{"a": "{{n}}", "b": "{{s}} \" {{t}}", "c": ["{{m}}"]}`, raw)
+	assert.Equal(t, []string{"n", "m"}, quoted)
+}
+
+// TestLoadCollection tests loading collections and rejecting other formats
+func TestLoadCollection(t *testing.T) {
+	c, err := LoadCollection("testdata/collection.json")
+	require.NoError(t, err)
+	assert.Equal(t, "Shop API", c.Info.Name)
+	require.Len(t, c.Item, 2)
+	assert.False(t, c.Item[0].IsFolder())
+	assert.Equal(t, []string{"pm.response.to.have.status(200);"}, Scripts(c.Item[0].Event, "test"))
+	assert.True(t, c.Item[1].IsFolder())
+	assert.Equal(t, "{{base_url}}/orders", c.Item[1].Item[0].Request.URL.Raw)
+	assert.Equal(t, "admin", c.Item[1].Item[1].Request.Auth.Attribute("username"))
+
+	_, err = LoadCollection("testdata/environment.json")
+	assert.ErrorContains(t, err, "unsupported Postman collection schema")
+}
@@ -0,0 +1,130 @@
+// Package record captures HTTP traffic through a reverse proxy so it can be turned into tests
+package record
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+)
+
+// Exchange is a captured request/response pair
+type Exchange struct {
+	Method         string
+	Path           string // Path as requested from the proxy, without query
+	Query          url.Values
+	RequestHeader  http.Header
+	RequestBody    []byte
+	Status         int
+	ResponseHeader http.Header
+	ResponseBody   []byte
+}
+
+// Recorder is a reverse proxy to an upstream server that records every exchange
+type Recorder struct {
+	Upstream *url.URL
+	proxy    *httputil.ReverseProxy
+
+	mu        sync.Mutex
+	exchanges []Exchange
+
+	// OnExchange is called after each exchange is recorded, e.g. for logging
+	OnExchange func(Exchange)
+}
+
+// incomingKey carries the proxied request's original URL to the transport
+type incomingKey struct{}
+
+// NewRecorder creates a recording proxy for the upstream base URL
+func NewRecorder(upstream string) (*Recorder, error) {
+	target, err := url.Parse(upstream)
+	if err != nil || target.Scheme == "" || target.Host == "" {
+		return nil, fmt.Errorf("invalid upstream URL %q (expected e.g. http://localhost:8080)", upstream)
+	}
+
+	r := &Recorder{Upstream: target}
+	r.proxy = &httputil.ReverseProxy{
+		Rewrite: func(pr *httputil.ProxyRequest) {
+			pr.SetURL(target)
+			pr.Out.Host = target.Host
+			// Ask for uncompressed bodies so they can be recorded as-is
+			pr.Out.Header.Del("Accept-Encoding")
+			pr.Out = pr.Out.WithContext(context.WithValue(pr.Out.Context(), incomingKey{}, pr.In.URL))
+		},
+		Transport: &recordingTransport{base: http.DefaultTransport, recorder: r},
+	}
+	return r, nil
+}
+
+// ServeHTTP proxies the request to the upstream server
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.proxy.ServeHTTP(w, req)
+}
+
+// Exchanges returns the exchanges recorded so far, in order of completion
+func (r *Recorder) Exchanges() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Exchange(nil), r.exchanges...)
+}
+
+func (r *Recorder) add(ex Exchange) {
+	r.mu.Lock()
+	r.exchanges = append(r.exchanges, ex)
+	r.mu.Unlock()
+
+	if r.OnExchange != nil {
+		r.OnExchange(ex)
+	}
+}
+
+// recordingTransport forwards requests and records them with their responses
+type recordingTransport struct {
+	base     http.RoundTripper
+	recorder *Recorder
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	incoming, _ := req.Context().Value(incomingKey{}).(*url.URL)
+	if incoming == nil {
+		incoming = req.URL
+	}
+	t.recorder.add(Exchange{
+		Method:         req.Method,
+		Path:           incoming.Path,
+		Query:          incoming.Query(),
+		RequestHeader:  req.Header.Clone(),
+		RequestBody:    reqBody,
+		Status:         resp.StatusCode,
+		ResponseHeader: resp.Header.Clone(),
+		ResponseBody:   respBody,
+	})
+	return resp, nil
+}
@@ -0,0 +1,55 @@
+package record
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecorder tests that proxied exchanges are forwarded and recorded
+func TestRecorder(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"path": "` + r.URL.Path + `", "echo": ` + string(body) + `}`))
+	}))
+	defer upstream.Close()
+
+	recorder, err := NewRecorder(upstream.URL + "/api")
+	require.NoError(t, err)
+	var seen []Exchange
+	recorder.OnExchange = func(ex Exchange) { seen = append(seen, ex) }
+
+	proxy := httptest.NewServer(recorder)
+	defer proxy.Close()
+
+	resp, err := http.Post(proxy.URL+"/items?x=1", "application/json", strings.NewReader(`{"a": 1}`))
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.JSONEq(t, `{"path": "/api/items", "echo": {"a": 1}}`, string(body), "Paths are joined to the upstream base path")
+
+	exchanges := recorder.Exchanges()
+	require.Len(t, exchanges, 1)
+	assert.Len(t, seen, 1)
+	ex := exchanges[0]
+	assert.Equal(t, "POST", ex.Method)
+	assert.Equal(t, "/items", ex.Path, "The path as requested from the proxy is recorded")
+	assert.Equal(t, "1", ex.Query.Get("x"))
+	assert.Equal(t, `{"a": 1}`, string(ex.RequestBody))
+	assert.Equal(t, 201, ex.Status)
+	assert.Equal(t, string(body), string(ex.ResponseBody))
+}
+
+// TestNewRecorder_InvalidUpstream tests that upstream URLs need a scheme and host
+func TestNewRecorder_InvalidUpstream(t *testing.T) {
+	_, err := NewRecorder("localhost:8080")
+	assert.ErrorContains(t, err, "invalid upstream URL")
+}
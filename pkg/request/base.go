@@ -1,9 +1,11 @@
 package request
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
 )
 
 // Executor defines the interface for executing requests
@@ -35,3 +37,12 @@ func NewBaseClient(config *Config) *BaseClient {
 func (c *BaseClient) GetConfig() *Config {
 	return c.config
 }
+
+// format formats a string with the test variables
+func (c *BaseClient) format(s string) (string, error) {
+	formatted, err := util.FormatKeys(s, c.config.Variables)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%v", formatted), nil
+}
@@ -0,0 +1,157 @@
+package request
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// GRPCClient calls unary gRPC methods, building messages from their descriptors at runtime
+type GRPCClient struct {
+	*BaseClient
+	timeout time.Duration
+}
+
+// GRPCResponse represents the result of a unary gRPC call
+type GRPCResponse struct {
+	Status   *status.Status
+	Body     interface{} // Response message in protobuf JSON form, nil unless the call succeeded
+	Header   metadata.MD
+	Trailer  metadata.MD
+	Duration time.Duration
+}
+
+// NewGRPCClient creates a new gRPC client
+func NewGRPCClient(config *Config) *GRPCClient {
+	timeout := 30 * time.Second
+	if config != nil && config.Timeout > 0 {
+		timeout = config.Timeout
+	}
+
+	return &GRPCClient{
+		BaseClient: NewBaseClient(config),
+		timeout:    timeout,
+	}
+}
+
+// Execute calls the method. A status returned by the server is part of the
+// response; an error means the call could not be made.
+func (c *GRPCClient) Execute(spec schema.GRPCRequestSpec) (*GRPCResponse, error) {
+	host, err := util.FormatKeys(spec.Host, c.config.Variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format host: %w", err)
+	}
+
+	var body interface{} = map[string]interface{}{}
+	if spec.Body != nil {
+		if body, err = util.FormatKeys(spec.Body, c.config.Variables); err != nil {
+			return nil, fmt.Errorf("failed to format body: %w", err)
+		}
+	}
+
+	md := metadata.MD{}
+	for k, v := range spec.Metadata {
+		formatted, err := util.FormatKeys(v, c.config.Variables)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format metadata: %w", err)
+		}
+		md.Append(k, fmt.Sprintf("%v", formatted))
+	}
+
+	timeout := c.timeout
+	if spec.Timeout > 0 {
+		timeout = time.Duration(spec.Timeout * float64(time.Second))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	creds := insecure.NewCredentials()
+	if spec.TLS != nil {
+		tlsConfig, err := c.tlsConfig(spec.TLS)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(tlsConfig)
+	}
+	conn, err := grpc.NewClient(fmt.Sprintf("%v", host), grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %v: %w", host, err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	method, err := resolveMethod(ctx, conn, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	requestJSON, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode body: %w", err)
+	}
+	req := dynamicpb.NewMessage(method.Input())
+	if err := protojson.Unmarshal(requestJSON, req); err != nil {
+		return nil, fmt.Errorf("body does not match %s: %w", method.Input().FullName(), err)
+	}
+
+	resp := &GRPCResponse{}
+	reply := dynamicpb.NewMessage(method.Output())
+	fullMethod := fmt.Sprintf("/%s/%s", method.Parent().FullName(), method.Name())
+
+	start := time.Now()
+	err = conn.Invoke(metadata.NewOutgoingContext(ctx, md), fullMethod, req, reply,
+		grpc.Header(&resp.Header), grpc.Trailer(&resp.Trailer))
+	resp.Duration = time.Since(start)
+	resp.Status = status.Convert(err)
+	if err != nil {
+		return resp, nil
+	}
+
+	// Field names as written in the .proto file
+	replyJSON, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(reply)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if err := json.Unmarshal(replyJSON, &resp.Body); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return resp, nil
+}
+
+// tlsConfig builds the TLS settings of a call: those of the shared HTTP
+// client, from the global config's tls block, overridden by the stage's
+func (c *GRPCClient) tlsConfig(spec *schema.TLSSpec) (*tls.Config, error) {
+	stage, err := NewTLSConfig(spec)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.config.HTTPClient != nil {
+		if transport, ok := c.config.HTTPClient.Transport.(*http.Transport); ok && transport.TLSClientConfig != nil {
+			config = transport.TLSClientConfig.Clone()
+		}
+	}
+	if spec.Verify != nil {
+		config.InsecureSkipVerify = stage.InsecureSkipVerify
+	}
+	if stage.RootCAs != nil {
+		config.RootCAs = stage.RootCAs
+	}
+	if len(stage.Certificates) > 0 {
+		config.Certificates = stage.Certificates
+	}
+	return config, nil
+}
@@ -0,0 +1,249 @@
+package request
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// compileGreeter compiles testdata/greeter.proto
+func compileGreeter(t *testing.T) protoreflect.FileDescriptor {
+	t.Helper()
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: []string{"testdata"}}),
+	}
+	files, err := compiler.Compile(context.Background(), "greeter.proto")
+	require.NoError(t, err)
+	return files[0]
+}
+
+// startGreeter starts an in-process Greeter server with server reflection and returns its address.
+// SayHello greets the name from the request, echoes x-user metadata in the trailer and
+// fails with INVALID_ARGUMENT for an empty name.
+func startGreeter(t *testing.T, opts ...grpc.ServerOption) string {
+	t.Helper()
+	file := compileGreeter(t)
+	sayHello := file.Services().ByName("Greeter").Methods().ByName("SayHello")
+
+	handler := func(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+		req := dynamicpb.NewMessage(sayHello.Input())
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		name := req.Get(sayHello.Input().Fields().ByName("name")).String()
+		if name == "" {
+			return nil, status.Error(codes.InvalidArgument, "name is required")
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+		_ = grpc.SetHeader(ctx, metadata.Pairs("x-request-id", "req-1"))
+		_ = grpc.SetTrailer(ctx, metadata.Pairs("x-user", strings.Join(md.Get("x-user"), ",")))
+
+		fields := sayHello.Output().Fields()
+		reply := dynamicpb.NewMessage(sayHello.Output())
+		reply.Set(fields.ByName("message"), protoreflect.ValueOfString("Hello, "+name))
+		reply.Set(fields.ByName("greeting_count"), protoreflect.ValueOfInt32(1))
+		return reply, nil
+	}
+
+	files, err := protodesc.NewFiles(descriptorSet(file))
+	require.NoError(t, err)
+
+	server := grpc.NewServer(opts...)
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: "tavern.test.Greeter",
+		HandlerType: (*interface{})(nil),
+		Methods:     []grpc.MethodDesc{{MethodName: "SayHello", Handler: handler}},
+	}, struct{}{})
+	reflectionpb.RegisterServerReflectionServer(server, reflection.NewServerV1(reflection.ServerOptions{
+		Services:           server,
+		DescriptorResolver: files,
+	}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+	return listener.Addr().String()
+}
+
+// descriptorSet returns a file and its imports as a FileDescriptorSet
+func descriptorSet(file protoreflect.FileDescriptor) *descriptorpb.FileDescriptorSet {
+	set := &descriptorpb.FileDescriptorSet{}
+	seen := map[string]bool{}
+	var add func(file protoreflect.FileDescriptor)
+	add = func(file protoreflect.FileDescriptor) {
+		if seen[file.Path()] {
+			return
+		}
+		seen[file.Path()] = true
+		for i := 0; i < file.Imports().Len(); i++ {
+			add(file.Imports().Get(i).FileDescriptor)
+		}
+		set.File = append(set.File, protodesc.ToFileDescriptorProto(file))
+	}
+	add(file)
+	return set
+}
+
+// TestGRPCClient_Reflection tests a unary call with message types from server reflection
+func TestGRPCClient_Reflection(t *testing.T) {
+	addr := startGreeter(t)
+	client := NewGRPCClient(&Config{Variables: map[string]interface{}{"addr": addr, "user": "alice"}})
+
+	resp, err := client.Execute(schema.GRPCRequestSpec{
+		Host:     "{addr}",
+		Service:  "tavern.test.Greeter/SayHello",
+		Body:     map[string]interface{}{"name": "{user}"},
+		Metadata: map[string]string{"x-user": "{user}"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, codes.OK, resp.Status.Code())
+	assert.Equal(t, map[string]interface{}{"message": "Hello, alice", "greeting_count": float64(1)}, resp.Body)
+	assert.Equal(t, []string{"req-1"}, resp.Header.Get("x-request-id"))
+	assert.Equal(t, []string{"alice"}, resp.Trailer.Get("x-user"))
+}
+
+// TestGRPCClient_ErrorStatus tests that a status returned by the server is part of the response
+func TestGRPCClient_ErrorStatus(t *testing.T) {
+	addr := startGreeter(t)
+	client := NewGRPCClient(&Config{})
+
+	resp, err := client.Execute(schema.GRPCRequestSpec{Host: addr, Service: "tavern.test.Greeter/SayHello"})
+	require.NoError(t, err)
+	assert.Equal(t, codes.InvalidArgument, resp.Status.Code())
+	assert.Equal(t, "name is required", resp.Status.Message())
+	assert.Nil(t, resp.Body)
+}
+
+// TestGRPCClient_LocalDescriptors tests message types from a descriptor set and from .proto files
+func TestGRPCClient_LocalDescriptors(t *testing.T) {
+	addr := startGreeter(t)
+	client := NewGRPCClient(&Config{})
+
+	data, err := proto.Marshal(descriptorSet(compileGreeter(t)))
+	require.NoError(t, err)
+	setPath := filepath.Join(t.TempDir(), "greeter.pb")
+	require.NoError(t, os.WriteFile(setPath, data, 0644))
+
+	specs := map[string]schema.GRPCRequestSpec{
+		"descriptor set": {DescriptorSet: setPath},
+		"proto files":    {ProtoFiles: []string{"greeter.proto"}, ImportPaths: []string{"testdata"}},
+	}
+	for name, spec := range specs {
+		t.Run(name, func(t *testing.T) {
+			spec.Host = addr
+			spec.Service = "/tavern.test.Greeter/SayHello"
+			spec.Body = map[string]interface{}{"name": "bob"}
+			resp, err := client.Execute(spec)
+			require.NoError(t, err)
+			assert.Equal(t, codes.OK, resp.Status.Code())
+			assert.Equal(t, "Hello, bob", resp.Body.(map[string]interface{})["message"])
+		})
+	}
+}
+
+// TestGRPCClient_Errors tests calls that cannot be made
+func TestGRPCClient_Errors(t *testing.T) {
+	addr := startGreeter(t)
+	client := NewGRPCClient(&Config{})
+
+	tests := map[string]struct {
+		spec schema.GRPCRequestSpec
+		err  string
+	}{
+		"invalid method name": {schema.GRPCRequestSpec{Service: "tavern.test.Greeter.SayHello"}, "expected package.Service/Method"},
+		"unknown service":     {schema.GRPCRequestSpec{Service: "tavern.test.Missing/SayHello"}, "server reflection"},
+		"unknown method":      {schema.GRPCRequestSpec{Service: "tavern.test.Greeter/Missing"}, "has no method Missing"},
+		"streaming method":    {schema.GRPCRequestSpec{Service: "tavern.test.Greeter/Chat"}, "only unary methods"},
+		"unknown field": {schema.GRPCRequestSpec{
+			Service: "tavern.test.Greeter/SayHello",
+			Body:    map[string]interface{}{"nickname": "al"},
+		}, "does not match tavern.test.HelloRequest"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.spec.Host = addr
+			_, err := client.Execute(tt.spec)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+// TestGRPCClient_TLS tests a private CA and client certificates, from the stage
+// or from the shared HTTP client's TLS settings
+func TestGRPCClient_TLS(t *testing.T) {
+	// Borrow the certificate of a TLS test server, which is valid for 127.0.0.1
+	https := httptest.NewTLSServer(http.NotFoundHandler())
+	defer https.Close()
+	cert := https.TLS.Certificates[0]
+	addr := startGreeter(t, grpc.Creds(credentials.NewTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAnyClientCert,
+	})))
+
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Certificate[0]}), 0644))
+	keyDER, err := x509.MarshalPKCS8PrivateKey(cert.PrivateKey)
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, "client.key")
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	spec := schema.GRPCRequestSpec{
+		Host:    addr,
+		Service: "tavern.test.Greeter/SayHello",
+		Body:    map[string]interface{}{"name": "alice"},
+		TLS:     &schema.TLSSpec{CACert: caPath, ClientCert: caPath, ClientKey: keyPath},
+	}
+	resp, err := NewGRPCClient(&Config{}).Execute(spec)
+	require.NoError(t, err)
+	assert.Equal(t, codes.OK, resp.Status.Code())
+
+	// The global config's settings apply to an empty tls block
+	global, err := NewTLSConfig(spec.TLS)
+	require.NoError(t, err)
+	httpClient := &http.Client{Transport: &http.Transport{TLSClientConfig: global}}
+	spec.TLS = &schema.TLSSpec{}
+	resp, err = NewGRPCClient(&Config{HTTPClient: httpClient}).Execute(spec)
+	require.NoError(t, err)
+	assert.Equal(t, codes.OK, resp.Status.Code())
+
+	// Without the client certificate, or the CA, the handshake fails
+	for name, tlsSpec := range map[string]*schema.TLSSpec{
+		"no client certificate": {CACert: caPath},
+		"no ca":                 {ClientCert: caPath, ClientKey: keyPath},
+	} {
+		t.Run(name, func(t *testing.T) {
+			spec.TLS = tlsSpec
+			_, err := NewGRPCClient(&Config{}).Execute(spec)
+			assert.Error(t, err)
+		})
+	}
+}
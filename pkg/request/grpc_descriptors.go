@@ -0,0 +1,181 @@
+package request
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"google.golang.org/grpc"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// descriptorResolver finds descriptors by full name
+type descriptorResolver interface {
+	FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error)
+}
+
+// splitMethodName splits a full method name such as package.Service/Method
+func splitMethodName(fullName string) (service, method string, err error) {
+	fullName = strings.TrimPrefix(fullName, "/")
+	service, method, ok := strings.Cut(fullName, "/")
+	if !ok || service == "" || method == "" || strings.Contains(method, "/") {
+		return "", "", fmt.Errorf("invalid gRPC method %q (expected package.Service/Method)", fullName)
+	}
+	return service, method, nil
+}
+
+// resolveMethod finds the descriptor of the called method, from the descriptor
+// set or proto files of the spec, or else through server reflection
+func resolveMethod(ctx context.Context, conn *grpc.ClientConn, spec schema.GRPCRequestSpec) (protoreflect.MethodDescriptor, error) {
+	service, method, err := splitMethodName(spec.Service)
+	if err != nil {
+		return nil, err
+	}
+
+	var resolver descriptorResolver
+	switch {
+	case spec.DescriptorSet != "":
+		resolver, err = descriptorSetResolver(spec.DescriptorSet)
+	case len(spec.ProtoFiles) > 0:
+		resolver, err = protoFilesResolver(ctx, spec.ProtoFiles, spec.ImportPaths)
+	default:
+		resolver, err = reflectionResolver(ctx, conn, service)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	desc, err := resolver.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, fmt.Errorf("service %s not found: %w", service, err)
+	}
+	serviceDesc, ok := desc.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a service", service)
+	}
+	methodDesc := serviceDesc.Methods().ByName(protoreflect.Name(method))
+	if methodDesc == nil {
+		return nil, fmt.Errorf("service %s has no method %s", service, method)
+	}
+	if methodDesc.IsStreamingClient() || methodDesc.IsStreamingServer() {
+		return nil, fmt.Errorf("method %s/%s is streaming, only unary methods are supported", service, method)
+	}
+	return methodDesc, nil
+}
+
+// descriptorSetResolver loads a FileDescriptorSet written by protoc --descriptor_set_out
+func descriptorSetResolver(path string) (descriptorResolver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read descriptor set: %w", err)
+	}
+	var set descriptorpb.FileDescriptorSet
+	if err := proto.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("failed to parse descriptor set %s: %w", path, err)
+	}
+	files, err := protodesc.NewFiles(&set)
+	if err != nil {
+		return nil, fmt.Errorf("invalid descriptor set %s (was it built with --include_imports?): %w", path, err)
+	}
+	return files, nil
+}
+
+// protoFilesResolver compiles .proto files. The well-known types are available
+// without being on the import paths.
+func protoFilesResolver(ctx context.Context, protoFiles, importPaths []string) (descriptorResolver, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: importPaths}),
+	}
+	files, err := compiler.Compile(ctx, protoFiles...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile proto files: %w", err)
+	}
+	return files.AsResolver(), nil
+}
+
+// reflectionResolver fetches the file defining service, and the files it
+// depends on, through the server reflection service (grpc.reflection.v1)
+func reflectionResolver(ctx context.Context, conn *grpc.ClientConn, service string) (descriptorResolver, error) {
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection: %w", err)
+	}
+	defer func() { _ = stream.CloseSend() }()
+
+	protos := make(map[string]*descriptorpb.FileDescriptorProto)
+	fetch := func(req *reflectionpb.ServerReflectionRequest) error {
+		if err := stream.Send(req); err != nil {
+			return fmt.Errorf("server reflection: %w", err)
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			return fmt.Errorf("server reflection: %w", err)
+		}
+		if errResp := resp.GetErrorResponse(); errResp != nil {
+			return fmt.Errorf("server reflection: %s", errResp.GetErrorMessage())
+		}
+		for _, data := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			file := &descriptorpb.FileDescriptorProto{}
+			if err := proto.Unmarshal(data, file); err != nil {
+				return fmt.Errorf("server reflection: invalid file descriptor: %w", err)
+			}
+			protos[file.GetName()] = file
+		}
+		return nil
+	}
+
+	err = fetch(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Servers usually send dependencies along, fetch any that are missing
+	requested := make(map[string]bool)
+	for {
+		var missing []string
+		for _, file := range protos {
+			for _, dep := range file.GetDependency() {
+				if protos[dep] == nil {
+					missing = append(missing, dep)
+				}
+			}
+		}
+		if len(missing) == 0 {
+			break
+		}
+		for _, name := range missing {
+			if protos[name] != nil {
+				continue
+			}
+			if requested[name] {
+				return nil, fmt.Errorf("server reflection: server did not return %s", name)
+			}
+			requested[name] = true
+			err := fetch(&reflectionpb.ServerReflectionRequest{
+				MessageRequest: &reflectionpb.ServerReflectionRequest_FileByFilename{FileByFilename: name},
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	set := &descriptorpb.FileDescriptorSet{}
+	for _, file := range protos {
+		set.File = append(set.File, file)
+	}
+	files, err := protodesc.NewFiles(set)
+	if err != nil {
+		return nil, fmt.Errorf("server reflection: %w", err)
+	}
+	return files, nil
+}
@@ -0,0 +1,223 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	mqtt "github.com/eclipse/paho.mqtt.golang"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// ErrMQTTTimeout is returned by Receive when no message arrives before the deadline
+var ErrMQTTTimeout = errors.New("timed out waiting for an mqtt message")
+
+// MQTTClient is a connection to an MQTT broker, shared by the stages of a test.
+// Subscriptions last until the connection is closed, and their messages are
+// queued until a stage receives them.
+type MQTTClient struct {
+	*BaseClient
+	client  mqtt.Client
+	timeout time.Duration
+
+	mu     sync.Mutex
+	queues map[string]*messageQueue // By topic filter
+}
+
+// MQTTMessage is a message received on a subscription
+type MQTTMessage struct {
+	Topic    string
+	Payload  []byte
+	Retained bool
+}
+
+// messageQueue holds the messages received on a subscription
+type messageQueue struct {
+	mu       sync.Mutex
+	messages []MQTTMessage
+	signal   chan struct{}
+}
+
+// ConnectMQTT connects to the broker of a test's mqtt block
+func ConnectMQTT(config *Config, spec schema.MQTTSpec) (*MQTTClient, error) {
+	c := &MQTTClient{
+		BaseClient: NewBaseClient(config),
+		timeout:    5 * time.Second,
+		queues:     make(map[string]*messageQueue),
+	}
+	if spec.Timeout > 0 {
+		c.timeout = time.Duration(spec.Timeout * float64(time.Second))
+	}
+
+	broker, err := c.format(spec.Broker)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format broker: %w", err)
+	}
+
+	options := mqtt.NewClientOptions().
+		AddBroker(broker).
+		SetConnectTimeout(c.timeout).
+		SetAutoReconnect(false).
+		SetCleanSession(true)
+
+	clientID, err := c.format(spec.ClientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format client_id: %w", err)
+	}
+	if clientID == "" {
+		clientID = fmt.Sprintf("tavern-%08x", rand.Uint32())
+	}
+	options.SetClientID(clientID)
+
+	if spec.Auth != nil {
+		username, err := c.format(spec.Auth.Username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format mqtt username: %w", err)
+		}
+		password, err := c.format(spec.Auth.Password)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format mqtt password: %w", err)
+		}
+		options.SetUsername(username).SetPassword(password)
+	}
+
+	if spec.TLS != nil {
+		tlsConfig, err := NewTLSConfig(spec.TLS)
+		if err != nil {
+			return nil, fmt.Errorf("mqtt tls: %w", err)
+		}
+		options.SetTLSConfig(tlsConfig)
+	}
+
+	c.client = mqtt.NewClient(options)
+	if err := c.wait(c.client.Connect()); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", broker, err)
+	}
+	return c, nil
+}
+
+// wait waits for a token within the timeout
+func (c *MQTTClient) wait(token mqtt.Token) error {
+	if !token.WaitTimeout(c.timeout) {
+		return fmt.Errorf("no reply from broker within %s", c.timeout)
+	}
+	return token.Error()
+}
+
+// Publish publishes a text or JSON message
+func (c *MQTTClient) Publish(spec schema.MQTTPublishSpec) error {
+	topic, err := c.format(spec.Topic)
+	if err != nil {
+		return fmt.Errorf("failed to format topic: %w", err)
+	}
+
+	var payload []byte
+	switch {
+	case spec.Payload != nil && spec.JSON != nil:
+		return fmt.Errorf("an mqtt message cannot have both payload and json")
+	case spec.Payload != nil:
+		text, err := c.format(*spec.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to format payload: %w", err)
+		}
+		payload = []byte(text)
+	case spec.JSON != nil:
+		formatted, err := util.FormatKeys(spec.JSON, c.config.Variables)
+		if err != nil {
+			return fmt.Errorf("failed to format json: %w", err)
+		}
+		if payload, err = json.Marshal(formatted); err != nil {
+			return fmt.Errorf("failed to encode json: %w", err)
+		}
+	}
+
+	if err := c.wait(c.client.Publish(topic, spec.QoS, spec.Retain, payload)); err != nil {
+		return fmt.Errorf("failed to publish to %s: %w", topic, err)
+	}
+	return nil
+}
+
+// Subscribe subscribes to a topic filter, unless it is already subscribed
+func (c *MQTTClient) Subscribe(topic string, qos byte) error {
+	topic, err := c.format(topic)
+	if err != nil {
+		return fmt.Errorf("failed to format topic: %w", err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.queues[topic]; ok {
+		return nil
+	}
+
+	queue := &messageQueue{signal: make(chan struct{}, 1)}
+	handler := func(_ mqtt.Client, msg mqtt.Message) {
+		queue.push(MQTTMessage{Topic: msg.Topic(), Payload: msg.Payload(), Retained: msg.Retained()})
+	}
+	if err := c.wait(c.client.Subscribe(topic, qos, handler)); err != nil {
+		return fmt.Errorf("failed to subscribe to %s: %w", topic, err)
+	}
+	c.queues[topic] = queue
+	return nil
+}
+
+// Receive returns the next message of a subscription, or ErrMQTTTimeout if
+// none arrives before the deadline
+func (c *MQTTClient) Receive(topic string, deadline time.Time) (MQTTMessage, error) {
+	topic, err := c.format(topic)
+	if err != nil {
+		return MQTTMessage{}, fmt.Errorf("failed to format topic: %w", err)
+	}
+
+	c.mu.Lock()
+	queue, ok := c.queues[topic]
+	c.mu.Unlock()
+	if !ok {
+		return MQTTMessage{}, fmt.Errorf("not subscribed to %s", topic)
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	for {
+		if msg, ok := queue.pop(); ok {
+			return msg, nil
+		}
+		select {
+		case <-queue.signal:
+		case <-timer.C:
+			return MQTTMessage{}, ErrMQTTTimeout
+		}
+	}
+}
+
+// Close disconnects from the broker
+func (c *MQTTClient) Close() {
+	c.client.Disconnect(250)
+}
+
+// push queues a message without blocking the client's message router
+func (q *messageQueue) push(msg MQTTMessage) {
+	q.mu.Lock()
+	q.messages = append(q.messages, msg)
+	q.mu.Unlock()
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+// pop takes the oldest queued message
+func (q *messageQueue) pop() (MQTTMessage, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.messages) == 0 {
+		return MQTTMessage{}, false
+	}
+	msg := q.messages[0]
+	q.messages = q.messages[1:]
+	return msg, true
+}
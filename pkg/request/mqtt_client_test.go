@@ -0,0 +1,91 @@
+package request
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	mochi "github.com/mochi-mqtt/server/v2"
+	"github.com/mochi-mqtt/server/v2/hooks/auth"
+	"github.com/mochi-mqtt/server/v2/listeners"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+)
+
+// startBroker starts an embedded MQTT broker and returns its tcp:// URL
+func startBroker(t *testing.T) string {
+	t.Helper()
+	server := mochi.New(&mochi.Options{
+		InlineClient: true,
+		Logger:       slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	require.NoError(t, server.AddHook(new(auth.AllowHook), nil))
+
+	tcp := listeners.NewTCP(listeners.Config{ID: "tcp", Address: "127.0.0.1:0"})
+	require.NoError(t, server.AddListener(tcp))
+	go func() { _ = server.Serve() }()
+	t.Cleanup(func() { _ = server.Close() })
+	return "tcp://" + tcp.Address()
+}
+
+// TestMQTTClient_PublishSubscribe tests publishing text and JSON to a wildcard subscription
+func TestMQTTClient_PublishSubscribe(t *testing.T) {
+	config := &Config{Variables: map[string]interface{}{
+		"broker": startBroker(t),
+		"device": "d1",
+		"level":  3,
+	}}
+	client, err := ConnectMQTT(config, schema.MQTTSpec{Broker: "{broker}", ClientID: "tavern-{device}"})
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, client.Subscribe("devices/+/status", 1))
+	require.NoError(t, client.Subscribe("devices/+/status", 1), "subscribing twice is a no-op")
+
+	text := "online"
+	require.NoError(t, client.Publish(schema.MQTTPublishSpec{Topic: "devices/{device}/status", Payload: &text, QoS: 1}))
+	require.NoError(t, client.Publish(schema.MQTTPublishSpec{
+		Topic: "devices/{device}/status",
+		JSON:  map[string]interface{}{"battery": "{level}"},
+		QoS:   1,
+	}))
+
+	deadline := time.Now().Add(5 * time.Second)
+	msg, err := client.Receive("devices/+/status", deadline)
+	require.NoError(t, err)
+	assert.Equal(t, "devices/d1/status", msg.Topic)
+	assert.Equal(t, "online", string(msg.Payload))
+
+	msg, err = client.Receive("devices/+/status", deadline)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"battery": "3"}`, string(msg.Payload))
+
+	_, err = client.Receive("devices/+/status", time.Now().Add(50*time.Millisecond))
+	assert.ErrorIs(t, err, ErrMQTTTimeout)
+}
+
+// TestMQTTClient_Errors tests connections and messages that fail
+func TestMQTTClient_Errors(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	_, err = ConnectMQTT(&Config{}, schema.MQTTSpec{Broker: "tcp://" + addr, Timeout: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect to tcp://"+addr)
+
+	client, err := ConnectMQTT(&Config{}, schema.MQTTSpec{Broker: startBroker(t)})
+	require.NoError(t, err)
+	defer client.Close()
+
+	text := "x"
+	err = client.Publish(schema.MQTTPublishSpec{Topic: "t", Payload: &text, JSON: "x"})
+	assert.ErrorContains(t, err, "both payload and json")
+
+	_, err = client.Receive("not/subscribed", time.Now().Add(time.Second))
+	assert.ErrorContains(t, err, "not subscribed to not/subscribed")
+}
@@ -0,0 +1,134 @@
+package request
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/systemquest/tavern-go/pkg/resp"
+	"github.com/systemquest/tavern-go/pkg/schema"
+)
+
+// RedisClient sends commands to Redis-compatible servers
+type RedisClient struct {
+	*BaseClient
+}
+
+// RedisResponse represents the decoded reply to a Redis command
+type RedisResponse struct {
+	Reply    interface{}   // string, int64, nil, []interface{} or resp.Error
+	Duration time.Duration // From connecting until the reply was read
+}
+
+// NewRedisClient creates a new Redis client
+func NewRedisClient(config *Config) *RedisClient {
+	return &RedisClient{
+		BaseClient: NewBaseClient(config),
+	}
+}
+
+// Execute connects to the server, authenticates and selects the database if
+// configured, then sends the command and reads its reply. An error reply to
+// the command is returned in the response for the stage to check; a failing
+// AUTH or SELECT is an error.
+func (c *RedisClient) Execute(spec schema.RedisSpec) (*RedisResponse, error) {
+	if len(spec.Command) == 0 {
+		return nil, fmt.Errorf("redis: command is empty")
+	}
+
+	host, err := c.format(spec.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format host: %w", err)
+	}
+
+	args := make([]string, len(spec.Command))
+	for i, arg := range spec.Command {
+		if args[i], err = c.argument(arg); err != nil {
+			return nil, fmt.Errorf("failed to format command[%d]: %w", i, err)
+		}
+	}
+
+	timeout := 5 * time.Second
+	if spec.Timeout > 0 {
+		timeout = time.Duration(spec.Timeout * float64(time.Second))
+	}
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", host, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(start.Add(timeout)); err != nil {
+		return nil, err
+	}
+	r := resp.NewReader(conn)
+
+	if spec.Password != "" {
+		auth := []string{"AUTH", spec.Password}
+		if spec.Username != "" {
+			auth = []string{"AUTH", spec.Username, spec.Password}
+		}
+		for i := 1; i < len(auth); i++ {
+			if auth[i], err = c.format(auth[i]); err != nil {
+				return nil, fmt.Errorf("failed to format credentials: %w", err)
+			}
+		}
+		if err := c.setup(conn, r, host, auth); err != nil {
+			return nil, err
+		}
+	}
+	if spec.DB > 0 {
+		if err := c.setup(conn, r, host, []string{"SELECT", strconv.Itoa(spec.DB)}); err != nil {
+			return nil, err
+		}
+	}
+
+	reply, err := c.do(conn, r, args)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", host, err)
+	}
+
+	return &RedisResponse{
+		Reply:    reply,
+		Duration: time.Since(start),
+	}, nil
+}
+
+// setup sends a connection command, failing on an error reply
+func (c *RedisClient) setup(conn net.Conn, r *resp.Reader, host string, args []string) error {
+	reply, err := c.do(conn, r, args)
+	if err != nil {
+		return fmt.Errorf("%s: %w", host, err)
+	}
+	if replyErr, ok := reply.(resp.Error); ok {
+		return fmt.Errorf("%s: %s failed: %w", host, args[0], replyErr)
+	}
+	return nil
+}
+
+// do sends a command and reads its reply
+func (c *RedisClient) do(conn net.Conn, r *resp.Reader, args []string) (interface{}, error) {
+	if _, err := conn.Write(resp.AppendCommand(nil, args)); err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", args[0], err)
+	}
+	reply, err := r.ReadValue()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reply to %s: %w", args[0], err)
+	}
+	return reply, nil
+}
+
+// argument converts a command argument to text, formatting strings with the
+// test variables
+func (c *RedisClient) argument(arg interface{}) (string, error) {
+	switch v := arg.(type) {
+	case string:
+		return c.format(v)
+	case int, int64, float64, bool:
+		return fmt.Sprint(v), nil
+	default:
+		return "", fmt.Errorf("expected a string or number, got %T", arg)
+	}
+}
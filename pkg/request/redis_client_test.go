@@ -0,0 +1,57 @@
+package request
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/resp"
+	"github.com/systemquest/tavern-go/pkg/resp/resptest"
+	"github.com/systemquest/tavern-go/pkg/schema"
+)
+
+// TestRedisClient_Execute tests formatting commands and decoding replies, with AUTH and SELECT
+func TestRedisClient_Execute(t *testing.T) {
+	server, err := resptest.NewServer()
+	require.NoError(t, err)
+	defer server.Close()
+	server.SetPassword("secret")
+
+	client := NewRedisClient(&Config{Variables: map[string]interface{}{"redis": server.Addr, "id": 7, "password": "secret"}})
+	spec := schema.RedisSpec{Host: "{redis}", Password: "{password}", DB: 2}
+
+	spec.Command = []interface{}{"SET", "user:{id}", 42}
+	reply, err := client.Execute(spec)
+	require.NoError(t, err)
+	assert.Equal(t, "OK", reply.Reply)
+
+	spec.Command = []interface{}{"GET", "user:7"}
+	reply, err = client.Execute(spec)
+	require.NoError(t, err)
+	assert.Equal(t, "42", reply.Reply)
+
+	// The key was set in database 2
+	assert.Nil(t, server.Do("GET", "user:7"))
+}
+
+// TestRedisClient_Errors tests error replies, failed authentication and invalid commands
+func TestRedisClient_Errors(t *testing.T) {
+	server, err := resptest.NewServer()
+	require.NoError(t, err)
+	defer server.Close()
+	client := NewRedisClient(nil)
+
+	reply, err := client.Execute(schema.RedisSpec{Host: server.Addr, Command: []interface{}{"NOPE"}})
+	require.NoError(t, err)
+	assert.Equal(t, resp.Error("ERR unknown command 'NOPE'"), reply.Reply)
+
+	server.SetPassword("secret")
+	_, err = client.Execute(schema.RedisSpec{Host: server.Addr, Password: "wrong", Command: []interface{}{"PING"}})
+	assert.ErrorContains(t, err, "AUTH failed: WRONGPASS")
+
+	_, err = client.Execute(schema.RedisSpec{Host: server.Addr, Command: []interface{}{"SET", "k", []interface{}{1}}})
+	assert.ErrorContains(t, err, "command[2]: expected a string or number")
+
+	_, err = client.Execute(schema.RedisSpec{Host: server.Addr})
+	assert.ErrorContains(t, err, "command is empty")
+}
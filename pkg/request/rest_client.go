@@ -9,6 +9,7 @@ import (
 	"mime/multipart"
 	"net/http"
 	"net/http/cookiejar"
+	"net/http/httptrace"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -26,6 +27,8 @@ type RestClient struct {
 	httpClient  *http.Client
 	config      *Config
 	RequestVars map[string]interface{} // Stores request arguments for access in response validation
+	Timing      *Timing                // Timing breakdown of the last executed request
+	ReadTimeout time.Duration          // Extra time on top of the client timeout for reading the body, such as an event stream
 	// persistentCookies stores cookies that have Expires or Max-Age set (persist across browser restarts)
 	persistentCookies map[string][]*http.Cookie
 }
@@ -36,6 +39,10 @@ type Config struct {
 	Timeout           time.Duration
 	HTTPClient        *http.Client              // Optional: shared HTTP client for session persistence
 	PersistentCookies map[string][]*http.Cookie // Optional: shared map for tracking persistent cookies across stages
+	BaseURL           string                    // Optional: prefix for relative request URLs
+	DefaultAuth       *schema.AuthSpec          // Optional: auth used when a request sets neither auth nor an Authorization header
+	WebSocket         *WebSocketClient          // Optional: websocket connection kept open across stages
+	MQTT              *MQTTClient               // Optional: broker connection from the test's mqtt block
 }
 
 // NewRestClient creates a new REST API client
@@ -114,12 +121,27 @@ func (c *RestClient) Execute(spec schema.RequestSpec) (*http.Response, error) {
 		}
 	}
 
+	// A stream is read for longer than the client timeout allows a whole request
+	if c.ReadTimeout > 0 && client.Timeout > 0 {
+		streaming := *client
+		streaming.Timeout += c.ReadTimeout
+		client = &streaming
+	}
+
+	// Trace the request to get a DNS/connect/TLS/TTFB/transfer breakdown
+	c.Timing = &Timing{}
+	tracer := newTimingTracer(c.Timing)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), tracer.clientTrace()))
+
 	// Execute the request
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
 
+	// Transfer and total times are recorded once the body has been read
+	resp.Body = &timedBody{ReadCloser: resp.Body, tracer: tracer}
+
 	// Track persistent cookies for clear_session_cookies support
 	// Aligned with tavern-py commit 1dcffc6: preserve persistent cookies when clearing session cookies
 	if c.httpClient.Jar != nil && resp != nil {
@@ -133,13 +155,18 @@ func (c *RestClient) Execute(spec schema.RequestSpec) (*http.Response, error) {
 func (c *RestClient) formatRequestSpec(spec schema.RequestSpec) (schema.RequestSpec, error) {
 	formatted := spec
 
+	// A GraphQL operation is the body, so it can't come with another one
+	if spec.GraphQL != nil && (spec.JSON != nil || spec.Data != nil || len(spec.Files) > 0) {
+		return formatted, fmt.Errorf("graphql cannot be combined with json, data or files")
+	}
+
 	// Format URL
 	if spec.URL != "" {
 		formattedURL, err := util.FormatKeys(spec.URL, c.config.Variables)
 		if err != nil {
 			return formatted, err
 		}
-		formatted.URL = formattedURL.(string)
+		formatted.URL = c.resolveURL(formattedURL.(string))
 	}
 
 	// Format headers
@@ -150,7 +177,7 @@ func (c *RestClient) formatRequestSpec(spec schema.RequestSpec) (schema.RequestS
 			if err != nil {
 				return formatted, err
 			}
-			formattedHeaders[k] = formattedVal.(string)
+			formattedHeaders[k] = fmt.Sprintf("%v", formattedVal)
 		}
 		formatted.Headers = formattedHeaders
 	}
@@ -163,7 +190,7 @@ func (c *RestClient) formatRequestSpec(spec schema.RequestSpec) (schema.RequestS
 			if err != nil {
 				return formatted, err
 			}
-			formattedParams[k] = formattedVal.(string)
+			formattedParams[k] = fmt.Sprintf("%v", formattedVal)
 		}
 		formatted.Params = formattedParams
 	}
@@ -177,6 +204,16 @@ func (c *RestClient) formatRequestSpec(spec schema.RequestSpec) (schema.RequestS
 		formatted.JSON = formattedJSON
 	}
 
+	// Resolve !file, !env and !cmd values in data
+	// Data is not formatted with variables, so raw bodies containing braces are sent as-is
+	if spec.Data != nil {
+		resolvedData, err := util.ResolveExternal(spec.Data)
+		if err != nil {
+			return formatted, err
+		}
+		formatted.Data = resolvedData
+	}
+
 	// Check for $ext in JSON
 	if formatted.JSON != nil {
 		if jsonMap, ok := formatted.JSON.(map[string]interface{}); ok {
@@ -190,9 +227,78 @@ func (c *RestClient) formatRequestSpec(spec schema.RequestSpec) (schema.RequestS
 		}
 	}
 
+	// Turn a GraphQL operation into query parameters or a JSON body
+	if spec.GraphQL != nil {
+		if err := c.formatGraphQL(&formatted); err != nil {
+			return formatted, err
+		}
+	}
+
 	return formatted, nil
 }
 
+// formatGraphQL replaces the graphql block of a formatted spec with the
+// request it describes: query parameters for GET, otherwise a JSON body POSTed
+// by default. Variables and the operation name are formatted, the query is not.
+func (c *RestClient) formatGraphQL(spec *schema.RequestSpec) error {
+	op := spec.GraphQL
+	if op.Query == "" {
+		return fmt.Errorf("graphql.query is required")
+	}
+
+	operationName, err := util.FormatKeys(op.OperationName, c.config.Variables)
+	if err != nil {
+		return err
+	}
+	var variables interface{}
+	if op.Variables != nil {
+		if variables, err = util.FormatKeys(op.Variables, c.config.Variables); err != nil {
+			return err
+		}
+	}
+
+	if strings.EqualFold(spec.Method, "GET") {
+		params := make(map[string]string, len(spec.Params)+3)
+		for k, v := range spec.Params {
+			params[k] = v
+		}
+		params["query"] = op.Query
+		if variables != nil {
+			encoded, err := json.Marshal(variables)
+			if err != nil {
+				return fmt.Errorf("failed to encode graphql variables: %w", err)
+			}
+			params["variables"] = string(encoded)
+		}
+		if name := fmt.Sprintf("%v", operationName); name != "" {
+			params["operationName"] = name
+		}
+		spec.Params = params
+		return nil
+	}
+
+	payload := map[string]interface{}{"query": op.Query}
+	if variables != nil {
+		payload["variables"] = variables
+	}
+	if name := fmt.Sprintf("%v", operationName); name != "" {
+		payload["operationName"] = name
+	}
+	spec.JSON = payload
+	if spec.Method == "" {
+		spec.Method = "POST"
+	}
+	return nil
+}
+
+// resolveURL prefixes relative URLs with the configured base URL
+func (c *RestClient) resolveURL(rawURL string) string {
+	if c.config.BaseURL == "" || strings.Contains(rawURL, "://") {
+		return rawURL
+	}
+	return strings.TrimRight(c.config.BaseURL, "/") + "/" + strings.TrimLeft(rawURL, "/")
+}
+
 // generateFromExt generates data using an extension function
 func (c *RestClient) generateFromExt(extSpec interface{}) (interface{}, error) {
 	extMap, ok := extSpec.(map[string]interface{})
@@ -355,9 +461,13 @@ func (c *RestClient) buildRequest(spec schema.RequestSpec) (*http.Request, error
 		}
 	}
 
-	// Set authentication
-	if spec.Auth != nil {
-		if err := c.setAuth(req, spec.Auth); err != nil {
+	// Set authentication, falling back to the environment's default auth
+	auth := spec.Auth
+	if auth == nil && req.Header.Get("Authorization") == "" {
+		auth = c.config.DefaultAuth
+	}
+	if auth != nil {
+		if err := c.setAuth(req, auth); err != nil {
 			return nil, err
 		}
 	}
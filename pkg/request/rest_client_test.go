@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -583,3 +584,91 @@ func TestClient_NestedVariablesInDataArray(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, http.StatusOK, resp.StatusCode)
 }
+
+// TestClient_GraphQL tests that a GraphQL operation is POSTed as a JSON body by default
+func TestClient_GraphQL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "POST", r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+		var payload map[string]interface{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		assert.Equal(t, "query User($id: ID!) { user(id: $id) { name } }", payload["query"])
+		assert.Equal(t, map[string]interface{}{"id": "42"}, payload["variables"])
+		assert.Equal(t, "User", payload["operationName"])
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewRestClient(&Config{Variables: map[string]interface{}{"user_id": "42"}})
+	resp, err := client.Execute(schema.RequestSpec{
+		URL: server.URL,
+		GraphQL: &schema.GraphQLSpec{
+			Query:         "query User($id: ID!) { user(id: $id) { name } }",
+			Variables:     map[string]interface{}{"id": "{user_id}"},
+			OperationName: "User",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+// TestClient_GraphQLGet tests that a GraphQL operation is sent as query parameters with GET
+func TestClient_GraphQLGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "{ user(id: $id) { name } }", r.URL.Query().Get("query"))
+		assert.JSONEq(t, `{"id": "42"}`, r.URL.Query().Get("variables"))
+		assert.Equal(t, "1", r.URL.Query().Get("page"))
+		assert.Empty(t, r.URL.Query().Get("operationName"))
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewRestClient(&Config{})
+	resp, err := client.Execute(schema.RequestSpec{
+		URL:     server.URL,
+		Method:  "GET",
+		Params:  map[string]string{"page": "1"},
+		GraphQL: &schema.GraphQLSpec{Query: "{ user(id: $id) { name } }", Variables: map[string]interface{}{"id": "42"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = client.Execute(schema.RequestSpec{
+		URL:     server.URL,
+		JSON:    map[string]interface{}{"query": "{ user { name } }"},
+		GraphQL: &schema.GraphQLSpec{Query: "{ user { name } }"},
+	})
+	assert.ErrorContains(t, err, "cannot be combined")
+}
+
+// TestClient_ReadTimeout tests that a read timeout extends the client timeout for a slow body
+func TestClient_ReadTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = io.WriteString(w, "data: first\n\n")
+		w.(http.Flusher).Flush()
+		time.Sleep(300 * time.Millisecond)
+		_, _ = io.WriteString(w, "data: second\n\n")
+	}))
+	defer server.Close()
+
+	httpClient := &http.Client{Timeout: 100 * time.Millisecond}
+	client := NewRestClient(&Config{HTTPClient: httpClient})
+	resp, err := client.Execute(schema.RequestSpec{URL: server.URL})
+	require.NoError(t, err)
+	_, err = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Error(t, err)
+
+	client = NewRestClient(&Config{HTTPClient: httpClient})
+	client.ReadTimeout = 5 * time.Second
+	resp, err = client.Execute(schema.RequestSpec{URL: server.URL})
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Equal(t, "data: first\n\ndata: second\n\n", string(body))
+	assert.Equal(t, 100*time.Millisecond, httpClient.Timeout)
+}
@@ -3,7 +3,12 @@ package request
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"os"
 	"os/exec"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/systemquest/tavern-go/pkg/schema"
@@ -36,53 +41,70 @@ func NewShellClient(config *Config) *ShellClient {
 	}
 }
 
-// Execute runs a shell command
-func (c *ShellClient) Execute(spec schema.RequestSpec) (*ShellResponse, error) {
-	// For shell commands, we use the URL field to store the command
-	command := spec.URL
-	if command == "" {
-		command = spec.Method // Fallback to method field
+// Execute runs a command and returns its exit code and output. A non-zero exit
+// code is not an error; failing to start the command or a timeout is.
+func (c *ShellClient) Execute(spec schema.CommandSpec) (*ShellResponse, error) {
+	if len(spec.Argv) == 0 {
+		return nil, fmt.Errorf("command requires argv")
 	}
 
-	// Parse arguments from params
-	var args []string
-	if spec.Params != nil {
-		for k, v := range spec.Params {
-			// For shell commands, all params are treated as arguments
-			args = append(args, "--"+k, v)
+	argv := make([]string, len(spec.Argv))
+	for i, arg := range spec.Argv {
+		formatted, err := c.format(arg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format argv[%d]: %w", i, err)
 		}
+		argv[i] = formatted
 	}
 
-	// Create command with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	timeout := c.timeout
+	if spec.Timeout > 0 {
+		timeout = time.Duration(spec.Timeout * float64(time.Second))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, command, args...)
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+
+	dir, err := c.format(spec.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format dir: %w", err)
+	}
+	cmd.Dir = dir
+
+	env, err := c.environment(spec)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Env = env
+
+	if spec.Stdin != nil {
+		stdin, err := c.format(*spec.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format stdin: %w", err)
+		}
+		cmd.Stdin = strings.NewReader(stdin)
+	}
 
 	// Capture stdout and stderr
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
 
-	// Set environment variables from headers
-	if spec.Headers != nil {
-		env := make([]string, 0, len(spec.Headers))
-		for k, v := range spec.Headers {
-			env = append(env, k+"="+v)
-		}
-		cmd.Env = env
-	}
-
-	// Execute command
 	start := time.Now()
-	err := cmd.Run()
+	err = cmd.Run()
 	duration := time.Since(start)
 
-	// Get exit code
 	exitCode := 0
 	if err != nil {
-		if exitError, ok := err.(*exec.ExitError); ok {
+		var exitError *exec.ExitError
+		switch {
+		case ctx.Err() == context.DeadlineExceeded:
+			return nil, fmt.Errorf("%s timed out after %s", argv[0], timeout)
+		case errors.As(err, &exitError):
 			exitCode = exitError.ExitCode()
+		default:
+			return nil, fmt.Errorf("failed to run %s: %w", argv[0], err)
 		}
 	}
 
@@ -93,3 +115,32 @@ func (c *ShellClient) Execute(spec schema.RequestSpec) (*ShellResponse, error) {
 		Duration: duration,
 	}, nil
 }
+
+// environment builds the command's environment: tavern's own unless
+// inherit_env is false, with the spec's variables set on top
+func (c *ShellClient) environment(spec schema.CommandSpec) ([]string, error) {
+	var env []string
+	if spec.InheritEnv == nil || *spec.InheritEnv {
+		env = os.Environ()
+	}
+
+	// Sorted for a stable order when a variable is listed twice
+	names := make([]string, 0, len(spec.Env))
+	for name := range spec.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, err := c.format(spec.Env[name])
+		if err != nil {
+			return nil, fmt.Errorf("failed to format env %s: %w", name, err)
+		}
+		env = append(env, name+"="+value)
+	}
+
+	// A nil Env would make exec inherit tavern's environment
+	if env == nil {
+		env = []string{}
+	}
+	return env, nil
+}
@@ -0,0 +1,65 @@
+package request
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+)
+
+// TestShellClient_Execute tests argv formatting, stdin, working directory and exit codes
+func TestShellClient_Execute(t *testing.T) {
+	dir := t.TempDir()
+	stdin := "hello {name}"
+	client := NewShellClient(&Config{Variables: map[string]interface{}{"name": "tavern", "code": 3, "dir": dir}})
+
+	resp, err := client.Execute(schema.CommandSpec{
+		Argv:  []string{"sh", "-c", `pwd; cat; echo " {name}" >&2; exit {code}`},
+		Stdin: &stdin,
+		Dir:   "{dir}",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, resp.ExitCode)
+	assert.Equal(t, dir+"\nhello tavern", resp.Stdout)
+	assert.Equal(t, " tavern\n", resp.Stderr)
+}
+
+// TestShellClient_Environment tests env variables with and without the inherited environment
+func TestShellClient_Environment(t *testing.T) {
+	t.Setenv("TAVERN_SHELL_TEST", "inherited")
+	client := NewShellClient(&Config{Variables: map[string]interface{}{"token": "t-1"}})
+	spec := schema.CommandSpec{
+		Argv: []string{"sh", "-c", `echo "$TAVERN_SHELL_TEST:$TOKEN"`},
+		Env:  map[string]string{"TOKEN": "{token}"},
+	}
+
+	resp, err := client.Execute(spec)
+	require.NoError(t, err)
+	assert.Equal(t, "inherited:t-1\n", resp.Stdout)
+
+	inherit := false
+	spec.InheritEnv = &inherit
+	spec.Argv[0] = "/bin/sh"
+	resp, err = client.Execute(spec)
+	require.NoError(t, err)
+	assert.Equal(t, ":t-1\n", resp.Stdout)
+}
+
+// TestShellClient_Errors tests commands that can't run or time out
+func TestShellClient_Errors(t *testing.T) {
+	client := NewShellClient(nil)
+
+	_, err := client.Execute(schema.CommandSpec{})
+	assert.ErrorContains(t, err, "command requires argv")
+
+	_, err = client.Execute(schema.CommandSpec{Argv: []string{"tavern-no-such-command"}})
+	assert.ErrorContains(t, err, "failed to run tavern-no-such-command")
+
+	_, err = client.Execute(schema.CommandSpec{Argv: []string{"sleep", "5"}, Timeout: 0.1})
+	assert.ErrorContains(t, err, "sleep timed out after 100ms")
+
+	_, err = client.Execute(schema.CommandSpec{Argv: []string{"true"}, Dir: os.DevNull})
+	assert.Error(t, err)
+}
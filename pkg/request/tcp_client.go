@@ -0,0 +1,172 @@
+package request
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+
+	"github.com/systemquest/tavern-go/pkg/schema"
+)
+
+// TCPClient exchanges raw bytes with line-protocol and other TCP services
+type TCPClient struct {
+	*BaseClient
+}
+
+// TCPResponse represents the reply read from a TCP connection
+type TCPResponse struct {
+	Data     []byte        // Raw reply, without the delimiter
+	Reply    string        // Reply in the stage's encoding, for assertions
+	Duration time.Duration // From connecting until the reply was read
+}
+
+// NewTCPClient creates a new TCP client
+func NewTCPClient(config *Config) *TCPClient {
+	return &TCPClient{
+		BaseClient: NewBaseClient(config),
+	}
+}
+
+// Execute connects to the host, sends the data and reads the reply. Without
+// until or bytes, everything received before the server closes the connection
+// or the timeout passes is the reply.
+func (c *TCPClient) Execute(spec schema.TCPSpec) (*TCPResponse, error) {
+	if spec.Until != nil && spec.Bytes > 0 {
+		return nil, fmt.Errorf("tcp: until and bytes cannot both be set")
+	}
+	switch spec.Encoding {
+	case "", "text", "hex", "base64":
+	default:
+		return nil, fmt.Errorf("tcp: unknown encoding %q (must be text, hex or base64)", spec.Encoding)
+	}
+
+	host, err := c.format(spec.Host)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format host: %w", err)
+	}
+
+	var send, delimiter []byte
+	if spec.Send != nil {
+		if send, err = c.decode(*spec.Send, spec.Encoding); err != nil {
+			return nil, fmt.Errorf("send: %w", err)
+		}
+	}
+	if spec.Until != nil {
+		if delimiter, err = c.decode(*spec.Until, spec.Encoding); err != nil {
+			return nil, fmt.Errorf("until: %w", err)
+		}
+		if len(delimiter) == 0 {
+			return nil, fmt.Errorf("until: delimiter is empty")
+		}
+	}
+
+	timeout := 5 * time.Second
+	if spec.Timeout > 0 {
+		timeout = time.Duration(spec.Timeout * float64(time.Second))
+	}
+
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", host, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", host, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(start.Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	if len(send) > 0 {
+		if _, err := conn.Write(send); err != nil {
+			return nil, fmt.Errorf("failed to send to %s: %w", host, err)
+		}
+	}
+
+	data, err := readReply(conn, delimiter, spec.Bytes, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", host, err)
+	}
+
+	return &TCPResponse{
+		Data:     data,
+		Reply:    encodeTCP(data, spec.Encoding),
+		Duration: time.Since(start),
+	}, nil
+}
+
+// readReply reads until the delimiter or n bytes, or, if neither is set, until
+// the connection is closed or times out
+func readReply(conn net.Conn, delimiter []byte, n int, timeout time.Duration) ([]byte, error) {
+	var buf []byte
+	chunk := make([]byte, 4096)
+	for {
+		if delimiter != nil {
+			if i := bytes.Index(buf, delimiter); i >= 0 {
+				return buf[:i], nil
+			}
+		} else if n > 0 && len(buf) >= n {
+			return buf[:n], nil
+		}
+
+		read, err := conn.Read(chunk)
+		buf = append(buf, chunk[:read]...)
+		if err == nil {
+			continue
+		}
+
+		var netErr net.Error
+		closed := errors.Is(err, io.EOF)
+		timedOut := errors.As(err, &netErr) && netErr.Timeout()
+		if delimiter == nil && n == 0 && (closed || timedOut) {
+			return buf, nil
+		}
+		if (delimiter != nil && bytes.Contains(buf, delimiter)) || (n > 0 && len(buf) >= n) {
+			continue
+		}
+
+		reason := err.Error()
+		switch {
+		case timedOut:
+			reason = fmt.Sprintf("timed out after %s", timeout)
+		case closed:
+			reason = "connection closed"
+		}
+		if delimiter != nil {
+			return nil, fmt.Errorf("no delimiter %q in reply: %s (received %q)", delimiter, reason, buf)
+		}
+		return nil, fmt.Errorf("expected %d bytes, got %d: %s (received %q)", n, len(buf), reason, buf)
+	}
+}
+
+// decode converts stage data in an encoding to bytes, after formatting variables
+func (c *TCPClient) decode(s, encoding string) ([]byte, error) {
+	formatted, err := c.format(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format: %w", err)
+	}
+
+	switch encoding {
+	case "hex":
+		return hex.DecodeString(formatted)
+	case "base64":
+		return base64.StdEncoding.DecodeString(formatted)
+	default:
+		return []byte(formatted), nil
+	}
+}
+
+// encodeTCP converts a reply to the stage's encoding
+func encodeTCP(data []byte, encoding string) string {
+	switch encoding {
+	case "hex":
+		return hex.EncodeToString(data)
+	case "base64":
+		return base64.StdEncoding.EncodeToString(data)
+	default:
+		return string(data)
+	}
+}
@@ -0,0 +1,113 @@
+package request
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+)
+
+// startLineServer starts a TCP server that handles each connection with
+// handle, and returns its host:port
+func startLineServer(t *testing.T, handle func(conn net.Conn)) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+// echoLines answers each line with "+<LINE IN UPPER CASE>\r\n"
+func echoLines(conn net.Conn) {
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		_, _ = conn.Write([]byte("+" + strings.ToUpper(scanner.Text()) + "\r\n"))
+	}
+}
+
+// TestTCPClient_Text tests sending text and reading until a delimiter
+func TestTCPClient_Text(t *testing.T) {
+	host := startLineServer(t, echoLines)
+	client := NewTCPClient(&Config{Variables: map[string]interface{}{"host": host, "key": "k1"}})
+	send, until := "get {key}\n", "\r\n"
+
+	resp, err := client.Execute(schema.TCPSpec{Host: "{host}", Send: &send, Until: &until})
+	require.NoError(t, err)
+	assert.Equal(t, "+GET K1", resp.Reply)
+	assert.Equal(t, []byte("+GET K1"), resp.Data)
+}
+
+// TestTCPClient_Encodings tests hex and base64 data with a byte count, and reading until the server closes
+func TestTCPClient_Encodings(t *testing.T) {
+	host := startLineServer(t, func(conn net.Conn) {
+		buf := make([]byte, 2)
+		if _, err := conn.Read(buf); err != nil {
+			return
+		}
+		_, _ = conn.Write(append([]byte{0x02, buf[0] + buf[1]}, "trailing"...))
+	})
+	client := NewTCPClient(nil)
+
+	send := "0104"
+	resp, err := client.Execute(schema.TCPSpec{Host: host, Send: &send, Encoding: "hex", Bytes: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "0205", resp.Reply)
+
+	send = "AQQ="
+	resp, err = client.Execute(schema.TCPSpec{Host: host, Send: &send, Encoding: "base64"})
+	require.NoError(t, err)
+	assert.Equal(t, "AgV0cmFpbGluZw==", resp.Reply)
+
+	banner := startLineServer(t, func(conn net.Conn) { _, _ = conn.Write([]byte("220 ready\r\n")) })
+	resp, err = client.Execute(schema.TCPSpec{Host: banner})
+	require.NoError(t, err)
+	assert.Equal(t, "220 ready\r\n", resp.Reply)
+}
+
+// TestTCPClient_Errors tests invalid stages, timeouts and closed connections
+func TestTCPClient_Errors(t *testing.T) {
+	host := startLineServer(t, echoLines)
+	client := NewTCPClient(nil)
+	send, until := "ping\n", "\n\n"
+
+	_, err := client.Execute(schema.TCPSpec{Host: host, Until: &until, Bytes: 1})
+	assert.ErrorContains(t, err, "until and bytes cannot both be set")
+
+	_, err = client.Execute(schema.TCPSpec{Host: host, Encoding: "utf-16"})
+	assert.ErrorContains(t, err, `unknown encoding "utf-16"`)
+
+	bad := "zz"
+	_, err = client.Execute(schema.TCPSpec{Host: host, Send: &bad, Encoding: "hex"})
+	assert.ErrorContains(t, err, "send: encoding/hex")
+
+	_, err = client.Execute(schema.TCPSpec{Host: host, Send: &send, Until: &until, Timeout: 0.2})
+	assert.ErrorContains(t, err, `no delimiter "\n\n" in reply: timed out after 200ms (received "+PING\r\n")`)
+
+	closing := startLineServer(t, func(conn net.Conn) { _, _ = conn.Write([]byte("bye")) })
+	_, err = client.Execute(schema.TCPSpec{Host: closing, Bytes: 10})
+	assert.ErrorContains(t, err, `expected 10 bytes, got 3: connection closed (received "bye")`)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+	_, err = client.Execute(schema.TCPSpec{Host: addr})
+	assert.ErrorContains(t, err, "failed to connect to "+addr)
+}
@@ -0,0 +1,155 @@
+package request
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptrace"
+	"sync"
+	"time"
+)
+
+// Timing holds a breakdown of where time was spent during an HTTP request
+// Phases that did not happen (e.g. DNS and connect on a reused connection) are zero
+type Timing struct {
+	DNSLookup       time.Duration // Resolving the host name
+	TCPConnect      time.Duration // Establishing the TCP connection
+	TLSHandshake    time.Duration // TLS handshake (HTTPS only)
+	TimeToFirstByte time.Duration // From request start until the first response byte
+	ContentTransfer time.Duration // From the first response byte until the body was fully read
+	Total           time.Duration // From request start until the body was fully read
+	ConnReused      bool          // Whether an idle keep-alive connection was reused
+}
+
+// String returns a compact one-line summary suitable for logging
+func (t *Timing) String() string {
+	return fmt.Sprintf("dns=%s connect=%s tls=%s ttfb=%s transfer=%s total=%s reused=%t",
+		formatMillis(t.DNSLookup), formatMillis(t.TCPConnect), formatMillis(t.TLSHandshake),
+		formatMillis(t.TimeToFirstByte), formatMillis(t.ContentTransfer), formatMillis(t.Total),
+		t.ConnReused)
+}
+
+// MarshalJSON reports durations as fractional milliseconds
+func (t *Timing) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"dns_ms":      millis(t.DNSLookup),
+		"connect_ms":  millis(t.TCPConnect),
+		"tls_ms":      millis(t.TLSHandshake),
+		"ttfb_ms":     millis(t.TimeToFirstByte),
+		"transfer_ms": millis(t.ContentTransfer),
+		"total_ms":    millis(t.Total),
+		"conn_reused": t.ConnReused,
+	})
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func formatMillis(d time.Duration) string {
+	return fmt.Sprintf("%.2fms", millis(d))
+}
+
+// timingTracer collects httptrace events for a single request
+type timingTracer struct {
+	mu         sync.Mutex
+	timing     *Timing
+	start      time.Time
+	dnsStart   time.Time
+	connStart  time.Time
+	tlsStart   time.Time
+	firstByte  time.Time
+	bodyClosed bool
+}
+
+// newTimingTracer creates a tracer that records into timing, starting now
+func newTimingTracer(timing *Timing) *timingTracer {
+	return &timingTracer{
+		timing: timing,
+		start:  time.Now(),
+	}
+}
+
+// clientTrace returns the httptrace hooks that feed this tracer
+func (t *timingTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.dnsStart = time.Now()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.DNSLookup = time.Since(t.dnsStart)
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.connStart = time.Now()
+		},
+		ConnectDone: func(string, string, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.TCPConnect = time.Since(t.connStart)
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.tlsStart = time.Now()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.TLSHandshake = time.Since(t.tlsStart)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.timing.ConnReused = info.Reused
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			t.firstByte = time.Now()
+			t.timing.TimeToFirstByte = t.firstByte.Sub(t.start)
+		},
+	}
+}
+
+// finish records transfer and total times once the body has been consumed
+func (t *timingTracer) finish() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.bodyClosed {
+		return
+	}
+	t.bodyClosed = true
+
+	now := time.Now()
+	if !t.firstByte.IsZero() {
+		t.timing.ContentTransfer = now.Sub(t.firstByte)
+	}
+	t.timing.Total = now.Sub(t.start)
+}
+
+// timedBody wraps a response body so the transfer time is recorded when it is drained or closed
+type timedBody struct {
+	io.ReadCloser
+	tracer *timingTracer
+}
+
+func (b *timedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	if err == io.EOF {
+		b.tracer.finish()
+	}
+	return n, err
+}
+
+func (b *timedBody) Close() error {
+	b.tracer.finish()
+	return b.ReadCloser.Close()
+}
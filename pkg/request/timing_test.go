@@ -0,0 +1,82 @@
+package request
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+)
+
+// TestClient_TimingBreakdown tests that Execute records a timing breakdown for the request
+func TestClient_TimingBreakdown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	client := NewRestClient(&Config{})
+
+	resp, err := client.Execute(schema.RequestSpec{URL: server.URL})
+	require.NoError(t, err)
+	require.NotNil(t, client.Timing)
+
+	// Transfer and total are only known once the body is consumed
+	_, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+
+	timing := client.Timing
+	assert.False(t, timing.ConnReused, "First request should open a new connection")
+	assert.Greater(t, timing.TCPConnect, time.Duration(0))
+	assert.Greater(t, timing.TimeToFirstByte, time.Duration(0))
+	assert.GreaterOrEqual(t, timing.Total, timing.TimeToFirstByte)
+	assert.Zero(t, timing.TLSHandshake, "Plain HTTP has no TLS handshake")
+}
+
+// TestClient_TimingConnectionReuse tests that keep-alive reuse is reported
+func TestClient_TimingConnectionReuse(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// Share one HTTP client between stages like the runner does
+	config := &Config{HTTPClient: &http.Client{}}
+
+	for i := 0; i < 2; i++ {
+		client := NewRestClient(config)
+		resp, err := client.Execute(schema.RequestSpec{URL: server.URL})
+		require.NoError(t, err)
+		_, _ = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+
+		if i == 1 {
+			assert.True(t, client.Timing.ConnReused, "Second request should reuse the connection")
+			assert.Zero(t, client.Timing.TCPConnect)
+		}
+	}
+}
+
+// TestTiming_MarshalJSON tests that durations are reported in milliseconds
+func TestTiming_MarshalJSON(t *testing.T) {
+	timing := &Timing{
+		TimeToFirstByte: 1500000, // 1.5ms
+		ConnReused:      true,
+	}
+
+	data, err := json.Marshal(timing)
+	require.NoError(t, err)
+
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, 1.5, decoded["ttfb_ms"])
+	assert.Equal(t, true, decoded["conn_reused"])
+	assert.Equal(t, 0.0, decoded["dns_ms"])
+}
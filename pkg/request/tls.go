@@ -0,0 +1,51 @@
+package request
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/systemquest/tavern-go/pkg/schema"
+)
+
+// NewTLSConfig builds a tls.Config from TLS settings
+// Extra CAs are added to the system pool; a client certificate enables mutual TLS
+func NewTLSConfig(spec *schema.TLSSpec) (*tls.Config, error) {
+	config := &tls.Config{}
+	if spec == nil {
+		return config, nil
+	}
+
+	if spec.Verify != nil && !*spec.Verify {
+		config.InsecureSkipVerify = true
+	}
+
+	if spec.CACert != "" {
+		pem, err := os.ReadFile(spec.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read ca_cert: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in ca_cert %s", spec.CACert)
+		}
+		config.RootCAs = pool
+	}
+
+	if spec.ClientCert != "" || spec.ClientKey != "" {
+		if spec.ClientCert == "" || spec.ClientKey == "" {
+			return nil, fmt.Errorf("client_cert and client_key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(spec.ClientCert, spec.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	return config, nil
+}
@@ -0,0 +1,172 @@
+package request
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// ErrWebSocketTimeout is returned by Receive when no message arrives before the deadline
+var ErrWebSocketTimeout = errors.New("timed out waiting for a websocket message")
+
+// WebSocketClient is an open WebSocket connection. Frames are read in the
+// background and queued, so a receive timeout leaves the connection usable
+// and messages not consumed by one stage are left for the next.
+type WebSocketClient struct {
+	*BaseClient
+	conn     *websocket.Conn
+	messages chan WebSocketMessage
+	readErr  error
+	done     chan struct{} // Closed by Close to stop the read loop
+	stopped  chan struct{} // Closed when the read loop returns
+	closeMu  sync.Mutex
+	closed   bool
+}
+
+// WebSocketMessage is a received text or binary frame
+type WebSocketMessage struct {
+	Binary bool
+	Data   []byte
+}
+
+// DialWebSocket opens a connection, sending the handshake headers along with
+// the cookies of the shared HTTP client
+func DialWebSocket(config *Config, spec schema.WebSocketConnectSpec) (*WebSocketClient, error) {
+	c := &WebSocketClient{BaseClient: NewBaseClient(config)}
+
+	url, err := util.FormatKeys(spec.URL, c.config.Variables)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format url: %w", err)
+	}
+
+	header := http.Header{}
+	for k, v := range spec.Headers {
+		formatted, err := util.FormatKeys(v, c.config.Variables)
+		if err != nil {
+			return nil, fmt.Errorf("failed to format headers: %w", err)
+		}
+		header.Set(k, fmt.Sprintf("%v", formatted))
+	}
+
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: 30 * time.Second,
+		Subprotocols:     spec.Subprotocols,
+	}
+	if c.config.Timeout > 0 {
+		dialer.HandshakeTimeout = c.config.Timeout
+	}
+	if spec.Timeout > 0 {
+		dialer.HandshakeTimeout = time.Duration(spec.Timeout * float64(time.Second))
+	}
+	if c.config.HTTPClient != nil {
+		// The dialer reads cookies for ws:// URLs as for http://, and stores any it is sent
+		dialer.Jar = c.config.HTTPClient.Jar
+		if transport, ok := c.config.HTTPClient.Transport.(*http.Transport); ok {
+			dialer.TLSClientConfig = transport.TLSClientConfig
+		}
+	}
+
+	conn, resp, err := dialer.Dial(fmt.Sprintf("%v", url), header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("failed to connect to %v: %w (status %d)", url, err, resp.StatusCode)
+		}
+		return nil, fmt.Errorf("failed to connect to %v: %w", url, err)
+	}
+
+	c.conn = conn
+	c.messages = make(chan WebSocketMessage, 256)
+	c.done = make(chan struct{})
+	c.stopped = make(chan struct{})
+	go c.readLoop()
+	return c, nil
+}
+
+// readLoop queues received frames until the connection fails or is closed.
+// A full queue blocks it only until Close.
+func (c *WebSocketClient) readLoop() {
+	defer close(c.stopped)
+	defer close(c.messages)
+	for {
+		messageType, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.readErr = err
+			return
+		}
+		select {
+		case c.messages <- WebSocketMessage{Binary: messageType == websocket.BinaryMessage, Data: data}:
+		case <-c.done:
+			c.readErr = net.ErrClosed
+			return
+		}
+	}
+}
+
+// Send sends a text or JSON frame
+func (c *WebSocketClient) Send(spec schema.WebSocketMessageSpec) error {
+	switch {
+	case spec.Text != nil && spec.JSON != nil:
+		return fmt.Errorf("a websocket message cannot have both text and json")
+	case spec.Text != nil:
+		text, err := util.FormatKeys(*spec.Text, c.config.Variables)
+		if err != nil {
+			return fmt.Errorf("failed to format text: %w", err)
+		}
+		return c.conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("%v", text)))
+	case spec.JSON != nil:
+		payload, err := util.FormatKeys(spec.JSON, c.config.Variables)
+		if err != nil {
+			return fmt.Errorf("failed to format json: %w", err)
+		}
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to encode json: %w", err)
+		}
+		return c.conn.WriteMessage(websocket.TextMessage, data)
+	}
+	return fmt.Errorf("a websocket message needs text or json")
+}
+
+// Receive returns the next message, or ErrWebSocketTimeout if none arrives
+// before the deadline
+func (c *WebSocketClient) Receive(deadline time.Time) (WebSocketMessage, error) {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	select {
+	case msg, ok := <-c.messages:
+		if !ok {
+			return WebSocketMessage{}, fmt.Errorf("websocket connection closed: %w", c.readErr)
+		}
+		return msg, nil
+	case <-timer.C:
+		return WebSocketMessage{}, ErrWebSocketTimeout
+	}
+}
+
+// Close sends a close frame, closes the connection and waits for the read
+// loop to stop
+func (c *WebSocketClient) Close() error {
+	c.closeMu.Lock()
+	defer c.closeMu.Unlock()
+	if c.closed {
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+
+	msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+	_ = c.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+	err := c.conn.Close()
+	<-c.stopped
+	return err
+}
@@ -0,0 +1,167 @@
+package request
+
+import (
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+)
+
+// startEcho starts a websocket server that greets with the session cookie and
+// X-Client header of the handshake, then echoes every frame back
+func startEcho(t *testing.T) string {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		session := ""
+		if cookie, err := r.Cookie("session"); err == nil {
+			session = cookie.Value
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		greeting := "hello session=" + session + " client=" + r.Header.Get("X-Client")
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(greeting)); err != nil {
+			return
+		}
+		for {
+			messageType, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+			if err := conn.WriteMessage(messageType, data); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// TestWebSocketClient_SendReceive tests the handshake headers, shared cookies and frames
+func TestWebSocketClient_SendReceive(t *testing.T) {
+	wsURL := startEcho(t)
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	httpURL, err := url.Parse("http" + strings.TrimPrefix(wsURL, "ws"))
+	require.NoError(t, err)
+	jar.SetCookies(httpURL, []*http.Cookie{{Name: "session", Value: "abc"}})
+
+	client, err := DialWebSocket(&Config{
+		Variables:  map[string]interface{}{"url": wsURL, "client": "tavern", "id": 7},
+		HTTPClient: &http.Client{Jar: jar},
+	}, schema.WebSocketConnectSpec{
+		URL:     "{url}",
+		Headers: map[string]string{"X-Client": "{client}"},
+	})
+	require.NoError(t, err)
+	defer client.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	msg, err := client.Receive(deadline)
+	require.NoError(t, err)
+	assert.Equal(t, "hello session=abc client=tavern", string(msg.Data))
+
+	text := "ping {id}"
+	require.NoError(t, client.Send(schema.WebSocketMessageSpec{Text: &text}))
+	msg, err = client.Receive(deadline)
+	require.NoError(t, err)
+	assert.Equal(t, "ping 7", string(msg.Data))
+	assert.False(t, msg.Binary)
+
+	require.NoError(t, client.Send(schema.WebSocketMessageSpec{JSON: map[string]interface{}{"id": "{id}"}}))
+	msg, err = client.Receive(deadline)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id": "7"}`, string(msg.Data))
+}
+
+// TestWebSocketClient_Timeout tests that a receive timeout leaves the connection usable
+func TestWebSocketClient_Timeout(t *testing.T) {
+	client, err := DialWebSocket(&Config{}, schema.WebSocketConnectSpec{URL: startEcho(t)})
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.Receive(time.Now().Add(5 * time.Second))
+	require.NoError(t, err)
+
+	_, err = client.Receive(time.Now().Add(50 * time.Millisecond))
+	assert.ErrorIs(t, err, ErrWebSocketTimeout)
+
+	text := "still open"
+	require.NoError(t, client.Send(schema.WebSocketMessageSpec{Text: &text}))
+	msg, err := client.Receive(time.Now().Add(5 * time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, "still open", string(msg.Data))
+}
+
+// TestWebSocketClient_Errors tests connections and messages that fail
+func TestWebSocketClient_Errors(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	_, err := DialWebSocket(&Config{}, schema.WebSocketConnectSpec{URL: "ws" + strings.TrimPrefix(server.URL, "http")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "status 404")
+
+	client, err := DialWebSocket(&Config{}, schema.WebSocketConnectSpec{URL: startEcho(t)})
+	require.NoError(t, err)
+	defer client.Close()
+
+	assert.ErrorContains(t, client.Send(schema.WebSocketMessageSpec{}), "needs text or json")
+	text := "hi"
+	assert.ErrorContains(t, client.Send(schema.WebSocketMessageSpec{Text: &text, JSON: "hi"}), "both text and json")
+}
+
+// TestWebSocketClient_CloseWithFullQueue tests that Close stops a read loop
+// blocked on a full message queue
+func TestWebSocketClient_CloseWithFullQueue(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for i := 0; i < 300; i++ {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte("flood")); err != nil {
+				return
+			}
+		}
+		_, _, _ = conn.ReadMessage()
+	}))
+	defer server.Close()
+
+	client, err := DialWebSocket(&Config{}, schema.WebSocketConnectSpec{URL: "ws" + strings.TrimPrefix(server.URL, "http")})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		return len(client.messages) == cap(client.messages)
+	}, 5*time.Second, 10*time.Millisecond)
+
+	closed := make(chan error, 1)
+	go func() { closed <- client.Close() }()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close blocked on the read loop")
+	}
+
+	count := 0
+	for range client.messages {
+		count++
+	}
+	assert.Equal(t, cap(client.messages), count)
+	_, err = client.Receive(time.Now().Add(time.Second))
+	assert.ErrorContains(t, err, "websocket connection closed")
+}
@@ -0,0 +1,193 @@
+// Package resp reads and writes the Redis serialization protocol (RESP2)
+package resp
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Error is an error reply, such as "ERR unknown command"
+type Error string
+
+// Error returns the error reply text
+func (e Error) Error() string {
+	return string(e)
+}
+
+// SimpleString is a status reply, such as "OK". Plain strings are written as
+// bulk strings.
+type SimpleString string
+
+// ErrProtocol is returned when the data read is not valid RESP
+var ErrProtocol = errors.New("resp: protocol error")
+
+// maxLength is the largest bulk string or array length accepted, the same
+// limit Redis applies to bulk strings
+const maxLength = 512 << 20
+
+// Reader reads RESP values
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader creates a reader
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// ReadValue reads one value. Simple and bulk strings are returned as string,
+// integers as int64, arrays as []interface{}, error replies as Error, and null
+// bulk strings and arrays as nil.
+func (r *Reader) ReadValue() (interface{}, error) {
+	line, err := r.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, fmt.Errorf("%w: empty line", ErrProtocol)
+	}
+
+	switch line[0] {
+	case '+':
+		return string(line[1:]), nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		n, err := strconv.ParseInt(string(line[1:]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid integer %q", ErrProtocol, line[1:])
+		}
+		return n, nil
+	case '$':
+		n, err := parseLength(line)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r.r, buf); err != nil {
+			return nil, err
+		}
+		if buf[n] != '\r' || buf[n+1] != '\n' {
+			return nil, fmt.Errorf("%w: bulk string not terminated by CRLF", ErrProtocol)
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := parseLength(line)
+		if err != nil || n < 0 {
+			return nil, err
+		}
+		values := make([]interface{}, n)
+		for i := range values {
+			if values[i], err = r.ReadValue(); err != nil {
+				return nil, err
+			}
+		}
+		return values, nil
+	default:
+		return nil, fmt.Errorf("%w: unexpected type %q", ErrProtocol, line[0])
+	}
+}
+
+// ReadCommand reads a command sent by a client, as an array of bulk strings
+func (r *Reader) ReadCommand() ([]string, error) {
+	value, err := r.ReadValue()
+	if err != nil {
+		return nil, err
+	}
+	values, ok := value.([]interface{})
+	if !ok || len(values) == 0 {
+		return nil, fmt.Errorf("%w: command must be a non-empty array", ErrProtocol)
+	}
+	args := make([]string, len(values))
+	for i, v := range values {
+		if args[i], ok = v.(string); !ok {
+			return nil, fmt.Errorf("%w: command arguments must be strings", ErrProtocol)
+		}
+	}
+	return args, nil
+}
+
+// readLine reads a line without its CRLF
+func (r *Reader) readLine() ([]byte, error) {
+	line, err := r.r.ReadBytes('\n')
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(line) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("%w: line not terminated by CRLF", ErrProtocol)
+	}
+	return line[:len(line)-2], nil
+}
+
+// parseLength parses the length of a bulk string or array; -1 means null
+func parseLength(line []byte) (int, error) {
+	n, err := strconv.Atoi(string(line[1:]))
+	if err != nil || n < -1 {
+		return 0, fmt.Errorf("%w: invalid length %q", ErrProtocol, line[1:])
+	}
+	if n > maxLength {
+		return 0, fmt.Errorf("%w: length %d exceeds the %d byte limit", ErrProtocol, n, maxLength)
+	}
+	return n, nil
+}
+
+// AppendCommand appends a command as an array of bulk strings
+func AppendCommand(buf []byte, args []string) []byte {
+	buf = appendHeader(buf, '*', len(args))
+	for _, arg := range args {
+		buf = appendBulk(buf, arg)
+	}
+	return buf
+}
+
+// AppendValue appends a reply. It accepts SimpleString, Error, string (as a
+// bulk string), int, int64, nil (as a null bulk string), []string and
+// []interface{} of these.
+func AppendValue(buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case SimpleString:
+		return append(append(append(buf, '+'), v...), '\r', '\n'), nil
+	case Error:
+		return append(append(append(buf, '-'), v...), '\r', '\n'), nil
+	case string:
+		return appendBulk(buf, v), nil
+	case int:
+		return appendHeader(buf, ':', v), nil
+	case int64:
+		buf = strconv.AppendInt(append(buf, ':'), v, 10)
+		return append(buf, '\r', '\n'), nil
+	case nil:
+		return append(buf, "$-1\r\n"...), nil
+	case []string:
+		return AppendCommand(buf, v), nil
+	case []interface{}:
+		buf = appendHeader(buf, '*', len(v))
+		var err error
+		for _, item := range v {
+			if buf, err = AppendValue(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	default:
+		return nil, fmt.Errorf("resp: cannot encode %T", value)
+	}
+}
+
+// appendHeader appends a type byte, a number and CRLF
+func appendHeader(buf []byte, kind byte, n int) []byte {
+	buf = strconv.AppendInt(append(buf, kind), int64(n), 10)
+	return append(buf, '\r', '\n')
+}
+
+// appendBulk appends a bulk string
+func appendBulk(buf []byte, s string) []byte {
+	buf = appendHeader(buf, '$', len(s))
+	return append(append(buf, s...), '\r', '\n')
+}
@@ -0,0 +1,72 @@
+package resp
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReader_ReadValue tests decoding each reply type
+func TestReader_ReadValue(t *testing.T) {
+	r := NewReader(strings.NewReader("+OK\r\n-WRONGTYPE wrong kind\r\n:42\r\n$5\r\nhe\r\no\r\n$0\r\n\r\n$-1\r\n*-1\r\n*2\r\n$1\r\na\r\n*1\r\n:-1\r\n"))
+
+	var values []interface{}
+	for {
+		value, err := r.ReadValue()
+		if err != nil {
+			assert.ErrorIs(t, err, io.EOF)
+			break
+		}
+		values = append(values, value)
+	}
+
+	assert.Equal(t, []interface{}{
+		"OK",
+		Error("WRONGTYPE wrong kind"),
+		int64(42),
+		"he\r\no",
+		"",
+		nil,
+		nil,
+		[]interface{}{"a", []interface{}{int64(-1)}},
+	}, values)
+}
+
+// TestReader_Errors tests malformed input
+func TestReader_Errors(t *testing.T) {
+	for _, input := range []string{"OK\r\n", "+OK\n", ":x\r\n", "$-2\r\n", "$3\r\nabcd\r\n", "*1\r\n"} {
+		_, err := NewReader(strings.NewReader(input)).ReadValue()
+		assert.Error(t, err, "input %q", input)
+	}
+
+	_, err := NewReader(strings.NewReader("+PING\r\n")).ReadCommand()
+	assert.ErrorIs(t, err, ErrProtocol)
+
+	for _, input := range []string{"$-5\r\n", "*-2\r\n", "$9999999999\r\n", "*9999999999\r\n"} {
+		_, err := NewReader(strings.NewReader(input)).ReadValue()
+		assert.ErrorIs(t, err, ErrProtocol, "input %q", input)
+	}
+}
+
+// TestAppend tests that encoded commands and replies read back
+func TestAppend(t *testing.T) {
+	buf := AppendCommand(nil, []string{"SET", "key", "a value"})
+	assert.Equal(t, "*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$7\r\na value\r\n", string(buf))
+
+	args, err := NewReader(strings.NewReader(string(buf))).ReadCommand()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SET", "key", "a value"}, args)
+
+	reply := []interface{}{SimpleString("OK"), Error("ERR no"), "bulk", 7, int64(8), nil, []string{"x"}}
+	buf, err = AppendValue(nil, reply)
+	require.NoError(t, err)
+	value, err := NewReader(strings.NewReader(string(buf))).ReadValue()
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"OK", Error("ERR no"), "bulk", int64(7), int64(8), nil, []interface{}{"x"}}, value)
+
+	_, err = AppendValue(nil, 1.5)
+	assert.ErrorContains(t, err, "cannot encode float64")
+}
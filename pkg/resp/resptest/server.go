@@ -0,0 +1,326 @@
+// Package resptest provides an in-memory RESP server for tests
+package resptest
+
+import (
+	"errors"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/systemquest/tavern-go/pkg/resp"
+)
+
+// Server is a small Redis-like server keeping strings, lists and hashes in
+// memory. It supports PING, ECHO, AUTH, SELECT, GET, SET, DEL, EXISTS, INCR,
+// RPUSH, LRANGE, HSET, HGET and HGETALL.
+type Server struct {
+	Addr string // host:port the server listens on
+
+	listener net.Listener
+	password string
+	mu       sync.Mutex
+	dbs      map[int]map[string]interface{}
+	conns    map[net.Conn]struct{}
+	wg       sync.WaitGroup
+}
+
+// NewServer starts a server on a random local port
+func NewServer() (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Server{
+		Addr:     listener.Addr().String(),
+		listener: listener,
+		dbs:      make(map[int]map[string]interface{}),
+		conns:    make(map[net.Conn]struct{}),
+	}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Close stops the server and closes open connections
+func (s *Server) Close() {
+	s.listener.Close()
+	s.mu.Lock()
+	for conn := range s.conns {
+		conn.Close()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// SetPassword requires new connections to send AUTH with the password before
+// other commands
+func (s *Server) SetPassword(password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.password = password
+}
+
+// Do runs a command against database 0 and returns its reply, for seeding
+// and inspecting data from tests
+func (s *Server) Do(args ...string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.exec(0, args)
+}
+
+// serve accepts connections until the listener is closed
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.handle(conn)
+	}
+}
+
+// handle answers the commands sent on a connection
+func (s *Server) handle(conn net.Conn) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		delete(s.conns, conn)
+		s.mu.Unlock()
+		conn.Close()
+	}()
+
+	s.mu.Lock()
+	password := s.password
+	s.mu.Unlock()
+
+	r := resp.NewReader(conn)
+	db := 0
+	authed := password == ""
+	for {
+		args, err := r.ReadCommand()
+		if err != nil {
+			if errors.Is(err, resp.ErrProtocol) {
+				reply, _ := resp.AppendValue(nil, resp.Error("ERR "+err.Error()))
+				_, _ = conn.Write(reply)
+			}
+			return
+		}
+
+		var value interface{}
+		name := strings.ToUpper(args[0])
+		switch {
+		case name == "AUTH":
+			value, authed = auth(args, password)
+		case !authed:
+			value = resp.Error("NOAUTH Authentication required.")
+		case name == "SELECT":
+			value = selectDB(args, &db)
+		default:
+			s.mu.Lock()
+			value = s.exec(db, args)
+			s.mu.Unlock()
+		}
+
+		reply, err := resp.AppendValue(nil, value)
+		if err != nil {
+			return
+		}
+		if _, err := conn.Write(reply); err != nil {
+			return
+		}
+	}
+}
+
+// auth checks AUTH password or AUTH username password
+func auth(args []string, password string) (interface{}, bool) {
+	if len(args) < 2 || len(args) > 3 {
+		return wrongArgs(args[0]), false
+	}
+	if password == "" {
+		return resp.Error("ERR AUTH called without any password configured"), true
+	}
+	if args[len(args)-1] != password {
+		return resp.Error("WRONGPASS invalid username-password pair"), false
+	}
+	return resp.SimpleString("OK"), true
+}
+
+// selectDB switches the connection to another of the 16 databases
+func selectDB(args []string, db *int) interface{} {
+	if len(args) != 2 {
+		return wrongArgs(args[0])
+	}
+	n, err := strconv.Atoi(args[1])
+	if err != nil || n < 0 || n > 15 {
+		return resp.Error("ERR DB index is out of range")
+	}
+	*db = n
+	return resp.SimpleString("OK")
+}
+
+// exec runs a data command; the caller holds the lock
+func (s *Server) exec(db int, args []string) interface{} {
+	data := s.dbs[db]
+	if data == nil {
+		data = make(map[string]interface{})
+		s.dbs[db] = data
+	}
+
+	name := strings.ToUpper(args[0])
+	arity := map[string]int{
+		"PING": -1, "ECHO": 2, "GET": 2, "SET": 3, "DEL": -2, "EXISTS": -2, "INCR": 2,
+		"RPUSH": -3, "LRANGE": 4, "HSET": -4, "HGET": 3, "HGETALL": 2,
+	}
+	n, ok := arity[name]
+	if !ok {
+		return resp.Error("ERR unknown command '" + args[0] + "'")
+	}
+	if (n > 0 && len(args) != n) || (n < 0 && len(args) < -n) {
+		return wrongArgs(args[0])
+	}
+
+	switch name {
+	case "PING":
+		if len(args) > 1 {
+			return args[1]
+		}
+		return resp.SimpleString("PONG")
+	case "ECHO":
+		return args[1]
+	case "GET":
+		switch v := data[args[1]].(type) {
+		case nil:
+			return nil
+		case string:
+			return v
+		default:
+			return wrongType()
+		}
+	case "SET":
+		data[args[1]] = args[2]
+		return resp.SimpleString("OK")
+	case "DEL", "EXISTS":
+		count := 0
+		for _, key := range args[1:] {
+			if _, ok := data[key]; ok {
+				count++
+				if name == "DEL" {
+					delete(data, key)
+				}
+			}
+		}
+		return count
+	case "INCR":
+		current := int64(0)
+		switch v := data[args[1]].(type) {
+		case nil:
+		case string:
+			var err error
+			if current, err = strconv.ParseInt(v, 10, 64); err != nil {
+				return resp.Error("ERR value is not an integer or out of range")
+			}
+		default:
+			return wrongType()
+		}
+		current++
+		data[args[1]] = strconv.FormatInt(current, 10)
+		return current
+	case "RPUSH":
+		list, ok := data[args[1]].([]string)
+		if !ok && data[args[1]] != nil {
+			return wrongType()
+		}
+		list = append(list, args[2:]...)
+		data[args[1]] = list
+		return len(list)
+	case "LRANGE":
+		return lrange(data, args)
+	case "HSET":
+		if len(args)%2 != 0 {
+			return wrongArgs(args[0])
+		}
+		hash, ok := data[args[1]].(map[string]string)
+		if !ok {
+			if data[args[1]] != nil {
+				return wrongType()
+			}
+			hash = make(map[string]string)
+			data[args[1]] = hash
+		}
+		added := 0
+		for i := 2; i < len(args); i += 2 {
+			if _, exists := hash[args[i]]; !exists {
+				added++
+			}
+			hash[args[i]] = args[i+1]
+		}
+		return added
+	case "HGET":
+		hash, ok := data[args[1]].(map[string]string)
+		if !ok && data[args[1]] != nil {
+			return wrongType()
+		}
+		if value, ok := hash[args[2]]; ok {
+			return value
+		}
+		return nil
+	default: // HGETALL
+		hash, ok := data[args[1]].(map[string]string)
+		if !ok && data[args[1]] != nil {
+			return wrongType()
+		}
+		fields := make([]string, 0, len(hash))
+		for field := range hash {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		pairs := make([]string, 0, 2*len(hash))
+		for _, field := range fields {
+			pairs = append(pairs, field, hash[field])
+		}
+		return pairs
+	}
+}
+
+// lrange returns a range of a list, with negative indexes counting from the end
+func lrange(data map[string]interface{}, args []string) interface{} {
+	list, ok := data[args[1]].([]string)
+	if !ok && data[args[1]] != nil {
+		return wrongType()
+	}
+	start, err1 := strconv.Atoi(args[2])
+	stop, err2 := strconv.Atoi(args[3])
+	if err1 != nil || err2 != nil {
+		return resp.Error("ERR value is not an integer or out of range")
+	}
+	if start < 0 {
+		start = max(len(list)+start, 0)
+	}
+	if stop < 0 {
+		stop = len(list) + stop
+	}
+	stop = min(stop, len(list)-1)
+	if start > stop {
+		return []string{}
+	}
+	return list[start : stop+1]
+}
+
+// wrongArgs is the error reply for a command with the wrong number of arguments
+func wrongArgs(command string) resp.Error {
+	return resp.Error("ERR wrong number of arguments for '" + strings.ToLower(command) + "' command")
+}
+
+// wrongType is the error reply for a command on a key holding another type
+func wrongType() resp.Error {
+	return resp.Error("WRONGTYPE Operation against a key holding the wrong kind of value")
+}
@@ -0,0 +1,54 @@
+package resptest
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/resp"
+)
+
+// TestServer_Commands tests the supported commands over a connection
+func TestServer_Commands(t *testing.T) {
+	server, err := NewServer()
+	require.NoError(t, err)
+	defer server.Close()
+	server.SetPassword("secret")
+
+	conn, err := net.Dial("tcp", server.Addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	r := resp.NewReader(conn)
+
+	do := func(args ...string) interface{} {
+		_, err := conn.Write(resp.AppendCommand(nil, args))
+		require.NoError(t, err)
+		reply, err := r.ReadValue()
+		require.NoError(t, err)
+		return reply
+	}
+
+	assert.Equal(t, resp.Error("NOAUTH Authentication required."), do("GET", "k"))
+	assert.Equal(t, resp.Error("WRONGPASS invalid username-password pair"), do("AUTH", "wrong"))
+	assert.Equal(t, "OK", do("AUTH", "default", "secret"))
+
+	assert.Equal(t, "PONG", do("PING"))
+	assert.Equal(t, "OK", do("set", "k", "v"))
+	assert.Equal(t, "v", do("GET", "k"))
+	assert.Nil(t, do("GET", "missing"))
+	assert.Equal(t, int64(1), do("INCR", "n"))
+	assert.Equal(t, int64(2), do("RPUSH", "l", "a", "b"))
+	assert.Equal(t, []interface{}{"b"}, do("LRANGE", "l", "-1", "-1"))
+	assert.Equal(t, int64(2), do("HSET", "h", "b", "2", "a", "1"))
+	assert.Equal(t, []interface{}{"a", "1", "b", "2"}, do("HGETALL", "h"))
+	assert.Equal(t, int64(2), do("EXISTS", "k", "l", "missing"))
+	assert.Equal(t, resp.Error("WRONGTYPE Operation against a key holding the wrong kind of value"), do("GET", "l"))
+	assert.Equal(t, resp.Error("ERR wrong number of arguments for 'get' command"), do("GET"))
+	assert.Equal(t, resp.Error("ERR unknown command 'FLUSHALL'"), do("FLUSHALL"))
+
+	// Databases are separate, and Do uses database 0
+	assert.Equal(t, "OK", do("SELECT", "1"))
+	assert.Nil(t, do("GET", "k"))
+	assert.Equal(t, "v", server.Do("GET", "k"))
+}
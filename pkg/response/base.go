@@ -1,7 +1,10 @@
 package response
 
 import (
+	"fmt"
+
 	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
 )
 
 // Verifier defines the interface for verifying responses
@@ -13,10 +16,10 @@ type Verifier interface {
 
 // BaseVerifier provides common functionality for all response verifiers
 type BaseVerifier struct {
-	name   string
-	spec   schema.ResponseSpec
-	config *Config
-	errors []string
+	name     string
+	spec     schema.ResponseSpec
+	config   *Config
+	failures []util.AssertionFailure
 }
 
 // NewBaseVerifier creates a new base verifier
@@ -28,10 +31,10 @@ func NewBaseVerifier(name string, spec schema.ResponseSpec, config *Config) *Bas
 	}
 
 	return &BaseVerifier{
-		name:   name,
-		spec:   spec,
-		config: config,
-		errors: make([]string, 0),
+		name:     name,
+		spec:     spec,
+		config:   config,
+		failures: make([]util.AssertionFailure, 0),
 	}
 }
 
@@ -50,17 +53,39 @@ func (v *BaseVerifier) GetConfig() *Config {
 	return v.config
 }
 
-// AddError adds an error message
+// AddError adds an error message without structured details
 func (v *BaseVerifier) AddError(err string) {
-	v.errors = append(v.errors, err)
+	v.failures = append(v.failures, util.AssertionFailure{Message: err})
+}
+
+// AddFailure adds a structured assertion failure
+func (v *BaseVerifier) AddFailure(failure util.AssertionFailure) {
+	v.failures = append(v.failures, failure)
 }
 
 // GetErrors returns all error messages
 func (v *BaseVerifier) GetErrors() []string {
-	return v.errors
+	errors := make([]string, len(v.failures))
+	for i, f := range v.failures {
+		errors[i] = f.Message
+	}
+	return errors
+}
+
+// GetFailures returns all structured assertion failures
+func (v *BaseVerifier) GetFailures() []util.AssertionFailure {
+	return v.failures
 }
 
 // HasErrors returns true if there are any errors
 func (v *BaseVerifier) HasErrors() bool {
-	return len(v.errors) > 0
+	return len(v.failures) > 0
+}
+
+// FailError returns a TestFailError describing all failures, or nil if there are none
+func (v *BaseVerifier) FailError() error {
+	if !v.HasErrors() {
+		return nil
+	}
+	return util.NewAssertionFailError(fmt.Sprintf("test '%s' failed", v.name), v.failures)
 }
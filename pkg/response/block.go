@@ -0,0 +1,445 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"github.com/systemquest/tavern-go/pkg/regex"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// blockValidator checks decoded response blocks (body, GraphQL data, gRPC
+// messages) against expected values with the response matchers, and collects
+// typed failures. Protocol validators embed it.
+type blockValidator struct {
+	name     string
+	config   *Config
+	failures []util.AssertionFailure
+	logger   *logrus.Logger
+}
+
+// newBlockValidator creates a block validator for the named test using the
+// given configuration
+func newBlockValidator(name string, config *Config) blockValidator {
+	return blockValidator{
+		name:     name,
+		config:   config,
+		failures: make([]util.AssertionFailure, 0),
+		logger:   logrus.StandardLogger(),
+	}
+}
+
+// validateBlock validates a block (body, data, ...) against the expected values
+func (v *blockValidator) validateBlock(blockName string, actual interface{}, expected interface{}) {
+	// Check if expected is an array (support list validation like tavern-py)
+	if expectedList, ok := expected.([]interface{}); ok {
+		v.validateList(blockName, actual, expectedList)
+		return
+	}
+
+	expectedMap, ok := expected.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	// Determine block-level strictness (aligned with tavern-py commit 3838566)
+	// 'strict' could be a list, in which case we only want to enable strict
+	// key checking for that specific bit of the response
+	var blockStrictness bool
+	if v.config != nil && v.config.Strict != nil {
+		blockStrictness = v.config.Strict.ShouldCheckStrictly(blockName)
+		v.logger.Debugf("Strict key checking for %s: %v", blockName, blockStrictness)
+	}
+
+	// Handle $ext validation before processing other keys
+	if extSpec, hasExt := expectedMap["$ext"]; hasExt {
+		extMap, ok := extSpec.(map[string]interface{})
+		if ok {
+			functionName, _ := extMap["function"].(string)
+			extraKwargs, _ := extMap["extra_kwargs"].(map[string]interface{})
+
+			// For inline regex validation in validateBlock
+			if functionName == "tavern.testutils.helpers:validate_regex" {
+				expression, _ := extraKwargs["expression"].(string)
+				if expression != "" {
+					var dataStr string
+					switch actualData := actual.(type) {
+					case string:
+						dataStr = actualData
+					case []byte:
+						dataStr = string(actualData)
+					default:
+						// Convert to JSON string for matching
+						jsonBytes, jsonErr := json.Marshal(actualData)
+						if jsonErr == nil {
+							dataStr = string(jsonBytes)
+						}
+					}
+
+					// Use shared regex validator
+					_, err := regex.Validate(dataStr, expression)
+					if err != nil {
+						v.addPathFailure(util.MatchRegex, blockName, expression, dataStr,
+							fmt.Sprintf("%s: %v", blockName, err))
+					}
+				}
+			}
+		}
+	}
+
+	// Remove special keys
+	for key := range expectedMap {
+		if key == "$ext" {
+			delete(expectedMap, key)
+		}
+	}
+
+	if len(expectedMap) == 0 {
+		return
+	}
+
+	// Format expected values with variables
+	formattedExpected, err := util.FormatKeys(expectedMap, v.config.Variables)
+	if err != nil {
+		v.addPathFailure(util.MatchFormat, blockName, nil, nil,
+			fmt.Sprintf("failed to format %s: %v", blockName, err))
+		return
+	}
+
+	expectedMap, ok = formattedExpected.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	// Validate each key
+	for key, expectedVal := range expectedMap {
+		actualVal, err := v.extractValue(actual, key)
+		if err != nil {
+			v.addPathFailure(util.MatchExists, fmt.Sprintf("%s.%s", blockName, key), expectedVal, nil,
+				fmt.Sprintf("%s.%s: %v", blockName, key, err))
+			continue
+		}
+
+		// If expected value is nil, just check existence
+		if expectedVal == nil {
+			continue
+		}
+
+		// Check for !anything marker - accept any value
+		if expectedStr, ok := expectedVal.(string); ok && expectedStr == "<<ANYTHING>>" {
+			v.logger.Debugf("Key %s.%s: actual value = '%v' - matches !anything", blockName, key, actualVal)
+			continue
+		}
+
+		// Check for type matchers (aligned with tavern-py commit 3ff6b3c)
+		if expectedStr, ok := expectedVal.(string); ok {
+			// Check for !anybool matcher
+			if expectedStr == "<<BOOL>>" {
+				if _, ok := actualVal.(bool); !ok {
+					v.addPathFailure(util.MatchAnyBool, fmt.Sprintf("%s.%s", blockName, key), expectedVal, actualVal,
+						fmt.Sprintf("%s.%s: expected boolean type (from !anybool), got '%v' (type: %T)",
+							blockName, key, actualVal, actualVal))
+				} else {
+					v.logger.Debugf("%s.%s: actual value = '%v' - matches !anybool", blockName, key, actualVal)
+				}
+				continue
+			}
+			// Check for !anyint matcher
+			if expectedStr == "<<INT>>" || strings.HasPrefix(expectedStr, "<<INT>>") {
+				switch val := actualVal.(type) {
+				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+					v.logger.Debugf("%s.%s: actual value = '%v' - matches !anyint", blockName, key, actualVal)
+				case float64:
+					if val == float64(int64(val)) {
+						v.logger.Debugf("%s.%s: actual value = '%v' - matches !anyint", blockName, key, actualVal)
+					} else {
+						v.addPathFailure(util.MatchAnyInt, fmt.Sprintf("%s.%s", blockName, key), expectedVal, actualVal,
+							fmt.Sprintf("%s.%s: expected integer type (from !anyint), got '%v' (type: %T with decimal part)",
+								blockName, key, actualVal, actualVal))
+					}
+				default:
+					v.addPathFailure(util.MatchAnyInt, fmt.Sprintf("%s.%s", blockName, key), expectedVal, actualVal,
+						fmt.Sprintf("%s.%s: expected integer type (from !anyint), got '%v' (type: %T)",
+							blockName, key, actualVal, actualVal))
+				}
+				continue
+			}
+			// Check for !anyfloat matcher
+			if expectedStr == "<<FLOAT>>" || strings.HasPrefix(expectedStr, "<<FLOAT>>") {
+				switch actualVal.(type) {
+				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+					v.logger.Debugf("%s.%s: actual value = '%v' - matches !anyfloat", blockName, key, actualVal)
+				default:
+					v.addPathFailure(util.MatchAnyFloat, fmt.Sprintf("%s.%s", blockName, key), expectedVal, actualVal,
+						fmt.Sprintf("%s.%s: expected numeric type (from !anyfloat), got '%v' (type: %T)",
+							blockName, key, actualVal, actualVal))
+				}
+				continue
+			}
+			// Check for !anystr matcher
+			if expectedStr == "<<STR>>" || strings.HasPrefix(expectedStr, "<<STR>>") {
+				if _, ok := actualVal.(string); !ok {
+					v.addPathFailure(util.MatchAnyStr, fmt.Sprintf("%s.%s", blockName, key), expectedVal, actualVal,
+						fmt.Sprintf("%s.%s: expected string type (from !anystr), got '%v' (type: %T)",
+							blockName, key, actualVal, actualVal))
+				} else {
+					v.logger.Debugf("%s.%s: actual value = '%v' - matches !anystr", blockName, key, actualVal)
+				}
+				continue
+			}
+			// Check for !approx matcher - approximate float comparison
+			// Aligned with tavern-py commit 53690cf: Feature/approx numbers (#101)
+			if strings.HasPrefix(expectedStr, "<<APPROX>>") {
+				expectedValueStr := strings.TrimPrefix(expectedStr, "<<APPROX>>")
+				expectedFloat, err := strconv.ParseFloat(expectedValueStr, 64)
+				if err != nil {
+					v.addPathFailure(util.MatchApprox, fmt.Sprintf("%s.%s", blockName, key), expectedVal, actualVal,
+						fmt.Sprintf("%s.%s: invalid !approx value '%s': %v", blockName, key, expectedValueStr, err))
+					continue
+				}
+
+				// Convert actual value to float64
+				var actualFloat float64
+				switch val := actualVal.(type) {
+				case float64:
+					actualFloat = val
+				case float32:
+					actualFloat = float64(val)
+				case int:
+					actualFloat = float64(val)
+				case int64:
+					actualFloat = float64(val)
+				case int32:
+					actualFloat = float64(val)
+				default:
+					v.addPathFailure(util.MatchApprox, fmt.Sprintf("%s.%s", blockName, key), expectedVal, actualVal,
+						fmt.Sprintf("%s.%s: expected numeric type for !approx, got '%v' (type: %T)",
+							blockName, key, actualVal, actualVal))
+					continue
+				}
+
+				// Use relative and absolute tolerance (similar to pytest.approx defaults)
+				// Default: rel_tol=1e-6, abs_tol=1e-12
+				relTol := 1e-6
+				absTol := 1e-12
+				tolerance := math.Max(relTol*math.Abs(expectedFloat), absTol)
+
+				if math.Abs(actualFloat-expectedFloat) <= tolerance {
+					v.logger.Debugf("%s.%s: actual value = '%v' approximately matches expected '%v' (tolerance: %e)",
+						blockName, key, actualFloat, expectedFloat, tolerance)
+				} else {
+					v.addPathFailure(util.MatchApprox, fmt.Sprintf("%s.%s", blockName, key), expectedVal, actualVal,
+						fmt.Sprintf("%s.%s: expected approximately '%v', got '%v' (difference: %e, tolerance: %e)",
+							blockName, key, expectedFloat, actualFloat, math.Abs(actualFloat-expectedFloat), tolerance))
+				}
+				continue
+			}
+		}
+
+		// If expected is an array, use validateList for element-by-element comparison
+		if expectedList, ok := expectedVal.([]interface{}); ok {
+			v.validateList(fmt.Sprintf("%s.%s", blockName, key), actualVal, expectedList)
+			continue
+		}
+
+		// If expected is a map, recursively validate
+		if expectedMap, ok := expectedVal.(map[string]interface{}); ok {
+			v.validateBlock(fmt.Sprintf("%s.%s", blockName, key), actualVal, expectedMap)
+			continue
+		}
+
+		// Compare values with type conversion for numbers
+		if !compareValues(actualVal, expectedVal) {
+			v.addPathFailure(util.MatchEquals, fmt.Sprintf("%s.%s", blockName, key), expectedVal, actualVal,
+				fmt.Sprintf("%s.%s: expected '%v' (type: %T), got '%v' (type: %T)",
+					blockName, key, expectedVal, expectedVal, actualVal, actualVal))
+		}
+	}
+
+	// Strict key checking (aligned with tavern-py commit 3838566)
+	// Check if there are extra keys in actual that are not in expected
+	actualMap, ok := actual.(map[string]interface{})
+	if ok {
+		expectedKeys := make(map[string]bool)
+		for key := range expectedMap {
+			expectedKeys[key] = true
+		}
+
+		var extraKeys []string
+		for key := range actualMap {
+			if !expectedKeys[key] {
+				extraKeys = append(extraKeys, key)
+			}
+		}
+
+		if len(extraKeys) > 0 {
+			if blockStrictness {
+				// In strict mode, extra keys are an error
+				extra := make(map[string]interface{}, len(extraKeys))
+				for _, key := range extraKeys {
+					extra[key] = actualMap[key]
+				}
+				v.addPathFailure(util.MatchStrict, blockName, nil, extra,
+					fmt.Sprintf("%s: extra keys in response (strict mode): %v", blockName, extraKeys))
+			} else if v.config != nil && v.config.Strict != nil && !v.config.Strict.IsLegacy {
+				// If strict is explicitly set to false (not legacy), log a warning
+				// This aligns with tavern-py's behavior in check_keys_match_recursive
+				v.logger.Warnf("%s: extra keys in response: %v", blockName, extraKeys)
+			}
+			// In legacy mode (default), extra keys at top level are silently ignored
+		}
+	}
+}
+
+// validateList validates array responses (aligned with tavern-py commit 95ae722)
+func (v *blockValidator) validateList(blockName string, actual interface{}, expected []interface{}) {
+	// Type check: actual must be an array
+	actualList, ok := actual.([]interface{})
+	if !ok {
+		v.addPathFailure(util.MatchType, blockName, expected, actual,
+			fmt.Sprintf("%s: expected array, got %T", blockName, actual))
+		return
+	}
+
+	// Strict length check (aligned with tavern-py commit 95ae722)
+	// tavern-py requires exact length match for lists
+	if len(expected) != len(actualList) {
+		v.addPathFailure(util.MatchLength, blockName, expected, actualList, fmt.Sprintf(
+			"%s: length of returned list was different than expected - expected %d items, got %d",
+			blockName, len(expected), len(actualList)))
+		return
+	}
+
+	// Validate each expected element
+	for idx, expectedVal := range expected {
+
+		actualVal := actualList[idx]
+		indexName := fmt.Sprintf("%s[%d]", blockName, idx)
+
+		// Handle nested structures recursively
+		switch exp := expectedVal.(type) {
+		case map[string]interface{}:
+			// Nested object: use validateBlock
+			v.validateBlock(indexName, actualVal, exp)
+		case []interface{}:
+			// Nested array: recursive call
+			v.validateList(indexName, actualVal, exp)
+		case string:
+			// Check for type markers
+			if exp == "<<ANYTHING>>" {
+				v.logger.Debugf("%s: actual value = '%v' - matches !anything", indexName, actualVal)
+				continue
+			}
+			if exp == "<<STR>>" || strings.HasPrefix(exp, "<<STR>>") {
+				// Check if actual value is a string
+				if _, ok := actualVal.(string); !ok {
+					v.addPathFailure(util.MatchAnyStr, indexName, exp, actualVal,
+						fmt.Sprintf("%s: expected string type (from !anystr), got '%v' (type: %T)",
+							indexName, actualVal, actualVal))
+				} else {
+					v.logger.Debugf("%s: actual value = '%v' - matches !anystr", indexName, actualVal)
+				}
+				continue
+			}
+			if exp == "<<INT>>" || strings.HasPrefix(exp, "<<INT>>") {
+				// Check if actual value is an integer (in JSON it could be float64 without decimal part)
+				switch val := actualVal.(type) {
+				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+					v.logger.Debugf("%s: actual value = '%v' - matches !anyint", indexName, actualVal)
+				case float64:
+					if val == float64(int64(val)) {
+						v.logger.Debugf("%s: actual value = '%v' - matches !anyint", indexName, actualVal)
+					} else {
+						v.addPathFailure(util.MatchAnyInt, indexName, exp, actualVal,
+							fmt.Sprintf("%s: expected integer type (from !anyint), got '%v' (type: %T with decimal part)",
+								indexName, actualVal, actualVal))
+					}
+				default:
+					v.addPathFailure(util.MatchAnyInt, indexName, exp, actualVal,
+						fmt.Sprintf("%s: expected integer type (from !anyint), got '%v' (type: %T)",
+							indexName, actualVal, actualVal))
+				}
+				continue
+			}
+			if exp == "<<FLOAT>>" || strings.HasPrefix(exp, "<<FLOAT>>") {
+				// Check if actual value is a numeric type (float or int)
+				switch actualVal.(type) {
+				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+					v.logger.Debugf("%s: actual value = '%v' - matches !anyfloat", indexName, actualVal)
+				default:
+					v.addPathFailure(util.MatchAnyFloat, indexName, exp, actualVal,
+						fmt.Sprintf("%s: expected numeric type (from !anyfloat), got '%v' (type: %T)",
+							indexName, actualVal, actualVal))
+				}
+				continue
+			}
+			if exp == "<<BOOL>>" {
+				// Check if actual value is a boolean (aligned with tavern-py commit 3ff6b3c)
+				if _, ok := actualVal.(bool); !ok {
+					v.addPathFailure(util.MatchAnyBool, indexName, exp, actualVal,
+						fmt.Sprintf("%s: expected boolean type (from !anybool), got '%v' (type: %T)",
+							indexName, actualVal, actualVal))
+				} else {
+					v.logger.Debugf("%s: actual value = '%v' - matches !anybool", indexName, actualVal)
+				}
+				continue
+			}
+			// Primitive value: direct comparison
+			if !compareValues(actualVal, exp) {
+				v.addPathFailure(util.MatchEquals, indexName, exp, actualVal,
+					fmt.Sprintf("%s: expected '%v' (type: %T), got '%v' (type: %T)",
+						indexName, exp, exp, actualVal, actualVal))
+			}
+		default:
+			// Primitive value: direct comparison
+			if !compareValues(actualVal, exp) {
+				v.addPathFailure(util.MatchEquals, indexName, exp, actualVal,
+					fmt.Sprintf("%s: expected '%v' (type: %T), got '%v' (type: %T)",
+						indexName, exp, exp, actualVal, actualVal))
+			}
+		}
+	}
+}
+
+// extractValue extracts a value from data using dot notation
+func (v *blockValidator) extractValue(data interface{}, key string) (interface{}, error) {
+	// Always use manual traversal for consistent behavior
+	return util.RecurseAccessKey(data, key)
+}
+
+// addFailure records a failed assertion
+func (v *blockValidator) addFailure(block, path string, expected, actual interface{}, matcher, msg string) {
+	v.failures = append(v.failures, util.AssertionFailure{
+		Block:    block,
+		Path:     path,
+		Expected: expected,
+		Actual:   actual,
+		Matcher:  matcher,
+		Message:  msg,
+	})
+}
+
+// addPathFailure records a failed assertion at a block-qualified path such as "body.items[0].id"
+func (v *blockValidator) addPathFailure(matcher, fullPath string, expected, actual interface{}, msg string) {
+	block, path := util.SplitBlockPath(fullPath)
+	v.addFailure(block, path, expected, actual, matcher, msg)
+}
+
+// formatErrors formats all failures into a single error, or returns nil if
+// there are none
+func (v *blockValidator) formatErrors() error {
+	if len(v.failures) == 0 {
+		return nil
+	}
+
+	err := util.NewAssertionFailError(
+		fmt.Sprintf("test '%s' failed", v.name),
+		v.failures,
+	)
+	err.Diff = renderBodyDiff(v.failures)
+	return err
+}
@@ -0,0 +1,190 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// maxDiffCellWidth is the maximum width of a rendered value before it is truncated
+const maxDiffCellWidth = 50
+
+// diffRow is a single rendered line of the diff table
+type diffRow struct {
+	marker   string
+	path     string
+	expected string
+	actual   string
+	matcher  string
+}
+
+// diffMatchers are the matchers whose failures compare an expected value to an actual one
+var diffMatchers = map[string]bool{
+	util.MatchEquals:   true,
+	util.MatchExists:   true,
+	util.MatchAnyInt:   true,
+	util.MatchAnyFloat: true,
+	util.MatchAnyStr:   true,
+	util.MatchAnyBool:  true,
+	util.MatchApprox:   true,
+	util.MatchType:     true,
+	util.MatchLength:   true,
+	util.MatchStrict:   true,
+}
+
+// renderBodyDiff renders body failures as a side-by-side table of expected vs actual values
+// Only mismatching paths are shown, so large payloads stay readable
+func renderBodyDiff(failures []util.AssertionFailure) string {
+	var sorted []util.AssertionFailure
+	for _, f := range failures {
+		if f.Block == util.BlockBody && diffMatchers[f.Matcher] {
+			sorted = append(sorted, f)
+		}
+	}
+	if len(sorted) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].FullPath() < sorted[j].FullPath()
+	})
+
+	var rows []diffRow
+	for _, f := range sorted {
+		path := f.FullPath()
+		switch f.Matcher {
+		case util.MatchExists:
+			rows = append(rows, diffRow{marker: "-", path: path, expected: displayValue(f.Expected), actual: "<missing>", matcher: f.Matcher})
+		case util.MatchStrict:
+			// One row per extra key
+			extra, _ := f.Actual.(map[string]interface{})
+			keys := make([]string, 0, len(extra))
+			for key := range extra {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				rows = append(rows, diffRow{marker: "+", path: path + "." + key, expected: "<absent>", actual: displayValue(extra[key]), matcher: f.Matcher})
+			}
+		case util.MatchLength:
+			expectedList, _ := f.Expected.([]interface{})
+			actualList, _ := f.Actual.([]interface{})
+			rows = append(rows, diffRow{
+				marker:   "#",
+				path:     path,
+				expected: fmt.Sprintf("%d items", len(expectedList)),
+				actual:   fmt.Sprintf("%d items", len(actualList)),
+				matcher:  f.Matcher,
+			})
+			// Show the two lists side by side, index by index
+			for i := 0; i < len(expectedList) || i < len(actualList); i++ {
+				row := diffRow{path: fmt.Sprintf("  [%d]", i), expected: "<absent>", actual: "<absent>", matcher: util.MatchEquals}
+				if i < len(expectedList) {
+					row.expected = displayValue(expectedList[i])
+				}
+				if i < len(actualList) {
+					row.actual = displayValue(actualList[i])
+				}
+				row.marker = " "
+				if row.expected != row.actual {
+					row.marker = "~"
+				}
+				rows = append(rows, row)
+			}
+		default:
+			rows = append(rows, diffRow{marker: "~", path: path, expected: displayValue(f.Expected), actual: displayValue(f.Actual), matcher: f.Matcher})
+		}
+	}
+
+	// Column widths are computed on the plain text so colors don't break alignment
+	pathWidth, expectedWidth := len("PATH"), len("EXPECTED")
+	for _, row := range rows {
+		pathWidth = max(pathWidth, utf8.RuneCountInString(row.path))
+		expectedWidth = max(expectedWidth, utf8.RuneCountInString(row.expected))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "Body diff (%d mismatching path(s)):\n", len(sorted))
+	fmt.Fprintf(&b, "  %s %s  %s  %s\n", " ", pad("PATH", pathWidth), pad("EXPECTED", expectedWidth), "ACTUAL")
+	for _, row := range rows {
+		path := pad(row.path, pathWidth)
+		expected := pad(row.expected, expectedWidth)
+		actual := row.actual
+		if row.marker != " " {
+			switch row.matcher {
+			case util.MatchExists:
+				expected = util.Colorize(util.ColorRed, expected)
+			case util.MatchStrict:
+				actual = util.Colorize(util.ColorGreen, actual)
+			case util.MatchLength:
+				path = util.Colorize(util.ColorYellow, path)
+			default:
+				expected = util.Colorize(util.ColorRed, expected)
+				actual = util.Colorize(util.ColorGreen, actual)
+			}
+		}
+		fmt.Fprintf(&b, "  %s %s  %s  %s\n", row.marker, path, expected, actual)
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// displayValue renders a value compactly, showing matcher markers as their YAML tags
+func displayValue(val interface{}) string {
+	var s string
+	switch v := val.(type) {
+	case string:
+		s = displayMarker(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			s = fmt.Sprintf("%v", v)
+		} else {
+			s = string(data)
+		}
+	}
+
+	if utf8.RuneCountInString(s) > maxDiffCellWidth {
+		runes := []rune(s)
+		s = string(runes[:maxDiffCellWidth-1]) + "…"
+	}
+	return s
+}
+
+// displayMarker turns internal matcher markers back into their YAML tag form
+func displayMarker(s string) string {
+	markers := []struct {
+		prefix string
+		tag    string
+	}{
+		{"<<ANYTHING>>", "!anything"},
+		{"<<APPROX>>", "!approx"},
+		{"<<INT>>", "!anyint"},
+		{"<<FLOAT>>", "!anyfloat"},
+		{"<<STR>>", "!anystr"},
+		{"<<BOOL>>", "!anybool"},
+	}
+	for _, m := range markers {
+		if strings.HasPrefix(s, m.prefix) {
+			if rest := strings.TrimPrefix(s, m.prefix); rest != "" {
+				return m.tag + " " + rest
+			}
+			return m.tag
+		}
+	}
+
+	data, _ := json.Marshal(s)
+	return string(data)
+}
+
+// pad right-pads s with spaces to width runes
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
@@ -0,0 +1,117 @@
+package response
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+func init() {
+	// Keep diff output deterministic regardless of the terminal running the tests
+	util.SetColor(false)
+}
+
+// TestValidator_BodyDiff tests that body mismatches produce a diff scoped to the failing paths
+func TestValidator_BodyDiff(t *testing.T) {
+	spec := schema.ResponseSpec{
+		StatusCode: &schema.StatusCode{Single: 200},
+		Body: map[string]interface{}{
+			"name":  "alice",
+			"id":    1,
+			"email": "alice@example.com",
+			"tags":  []interface{}{"a", "b"},
+			"count": "<<INT>>",
+			"same":  "unchanged",
+		},
+	}
+
+	validator := NewRestValidator("test", spec, &Config{Variables: map[string]interface{}{}})
+
+	resp := createMockResponse(200, nil, map[string]interface{}{
+		"name":  "bob",
+		"id":    1,
+		"tags":  []interface{}{"a", "x", "c"},
+		"count": "three",
+		"same":  "unchanged",
+	})
+
+	_, err := validator.Verify(resp)
+	require.Error(t, err)
+
+	failErr, ok := err.(*util.TestFailError)
+	require.True(t, ok)
+
+	diff := failErr.Diff
+	assert.Contains(t, diff, "Body diff (4 mismatching path(s))")
+	assert.Contains(t, diff, "~ body.name")
+	assert.Contains(t, diff, `"alice"`)
+	assert.Contains(t, diff, `"bob"`)
+	assert.Contains(t, diff, "- body.email")
+	assert.Contains(t, diff, "<missing>")
+	assert.Contains(t, diff, "# body.tags")
+	assert.Contains(t, diff, "2 items")
+	assert.Contains(t, diff, "3 items")
+	assert.Contains(t, diff, "!anyint")
+	assert.NotContains(t, diff, "body.id", "Matching paths should not be shown")
+	assert.NotContains(t, diff, "body.same", "Matching paths should not be shown")
+
+	// The diff is part of the error message
+	assert.Contains(t, err.Error(), "Body diff")
+}
+
+// TestValidator_BodyDiffStrictExtraKeys tests that extra keys are shown in strict mode
+func TestValidator_BodyDiffStrictExtraKeys(t *testing.T) {
+	spec := schema.ResponseSpec{
+		Body: map[string]interface{}{
+			"name": "alice",
+		},
+	}
+
+	validator := NewRestValidator("test", spec, &Config{
+		Variables: map[string]interface{}{},
+		Strict:    schema.NewStrictFromBool(true),
+	})
+
+	resp := createMockResponse(200, nil, map[string]interface{}{
+		"name":  "alice",
+		"admin": true,
+	})
+
+	_, err := validator.Verify(resp)
+	require.Error(t, err)
+
+	diff := err.(*util.TestFailError).Diff
+	assert.Contains(t, diff, "+ body.admin")
+	assert.Contains(t, diff, "<absent>")
+	assert.Contains(t, diff, "true")
+}
+
+// TestRenderBodyDiff_SideBySideLists tests the index-by-index rendering of list length differences
+func TestRenderBodyDiff_SideBySideLists(t *testing.T) {
+	diff := renderBodyDiff([]util.AssertionFailure{{
+		Block:    util.BlockBody,
+		Path:     "items",
+		Matcher:  util.MatchLength,
+		Expected: []interface{}{"a", "b"},
+		Actual:   []interface{}{"a", "c", "d"},
+	}})
+
+	lines := strings.Split(diff, "\n")
+	require.Len(t, lines, 6)
+	assert.Contains(t, lines[2], "# body.items")
+	assert.Regexp(t, `^\s+\[0\]\s+"a"\s+"a"$`, lines[3])
+	assert.Regexp(t, `^\s+~\s+\[1\]\s+"b"\s+"c"$`, lines[4])
+	assert.Regexp(t, `^\s+~\s+\[2\]\s+<absent>\s+"d"$`, lines[5])
+}
+
+// TestDisplayValue_Truncates tests that long values are truncated
+func TestDisplayValue_Truncates(t *testing.T) {
+	long := strings.Repeat("x", 200)
+	display := displayValue(long)
+	assert.LessOrEqual(t, len([]rune(display)), maxDiffCellWidth)
+	assert.True(t, strings.HasSuffix(display, "…"))
+}
@@ -0,0 +1,247 @@
+package response
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// serverEvent is an event of a text/event-stream response
+type serverEvent struct {
+	Event string `json:"event"`
+	ID    string `json:"id,omitempty"`
+	Data  string `json:"data"`
+}
+
+// eventStream holds the events read from a response
+type eventStream struct {
+	events   []serverEvent
+	until    int  // Index of the event matching until, or -1
+	timedOut bool // Reading stopped at the timeout
+	timeout  time.Duration
+}
+
+// isEventStream reports whether a response is a text/event-stream
+func isEventStream(header http.Header) bool {
+	mediaType, _, _ := mime.ParseMediaType(header.Get("Content-Type"))
+	return mediaType == "text/event-stream"
+}
+
+// eventReader parses events from a text/event-stream body
+type eventReader struct {
+	reader *bufio.Reader
+	lastID string
+}
+
+// next returns the next event. An event cut off by the end of the stream is discarded.
+func (r *eventReader) next() (serverEvent, error) {
+	var event string
+	var data []string
+	for {
+		line, err := r.reader.ReadString('\n')
+		if err != nil {
+			return serverEvent{}, err
+		}
+		line = strings.TrimSuffix(strings.TrimSuffix(line, "\n"), "\r")
+
+		if line == "" {
+			if data == nil {
+				event = ""
+				continue
+			}
+			if event == "" {
+				event = "message"
+			}
+			return serverEvent{Event: event, ID: r.lastID, Data: strings.Join(data, "\n")}, nil
+		}
+		if strings.HasPrefix(line, ":") {
+			continue // Comment, often used as a keep-alive
+		}
+
+		field, value, _ := strings.Cut(line, ":")
+		value = strings.TrimPrefix(value, " ")
+		switch field {
+		case "event":
+			event = value
+		case "data":
+			data = append(data, value)
+		case "id":
+			if !strings.Contains(value, "\x00") {
+				r.lastID = value
+			}
+		}
+	}
+}
+
+// EventsTimeout returns how long an events block reads a stream for, 10
+// seconds unless it sets a timeout
+func EventsTimeout(spec *schema.EventsSpec) time.Duration {
+	if spec != nil && spec.Timeout > 0 {
+		return time.Duration(spec.Timeout * float64(time.Second))
+	}
+	return 10 * time.Second
+}
+
+// readEvents reads a text/event-stream body until the stop condition of the
+// events block, and returns the part of the stream read
+func (v *RestValidator) readEvents(resp *http.Response) ([]byte, error) {
+	spec := v.spec.Events
+	if spec == nil {
+		spec = &schema.EventsSpec{}
+	}
+	stream := &eventStream{until: -1, timeout: EventsTimeout(spec)}
+	v.stream = stream
+
+	// The stream may never end, so closing the body is what stops a blocked read
+	var timedOut atomic.Bool
+	timer := time.AfterFunc(stream.timeout, func() {
+		timedOut.Store(true)
+		_ = resp.Body.Close()
+	})
+	defer timer.Stop()
+	defer func() { _ = resp.Body.Close() }()
+
+	var raw bytes.Buffer
+	reader := &eventReader{reader: bufio.NewReader(io.TeeReader(resp.Body, &raw))}
+	for spec.Count == 0 || len(stream.events) < spec.Count {
+		event, err := reader.next()
+		if err != nil {
+			if timedOut.Load() {
+				stream.timedOut = true
+			} else if !errors.Is(err, io.EOF) {
+				return raw.Bytes(), err
+			}
+			break
+		}
+		stream.events = append(stream.events, event)
+		v.logger.Debugf("Received event %d: %s %s", len(stream.events)-1, event.Event, event.Data)
+
+		if spec.Until != nil && len(v.matchEvent("events.until", *spec.Until, event, nil)) == 0 {
+			stream.until = len(stream.events) - 1
+			break
+		}
+	}
+	return raw.Bytes(), nil
+}
+
+// validateEvents checks the events read against the events block and saves their values
+func (v *RestValidator) validateEvents(saved map[string]interface{}) {
+	spec, stream := v.spec.Events, v.stream
+	if spec == nil || stream == nil {
+		return
+	}
+
+	stopped := "the stream ended"
+	if stream.timedOut {
+		stopped = fmt.Sprintf("%s passed", stream.timeout)
+	}
+
+	if spec.Until != nil {
+		if stream.until < 0 {
+			v.addPathFailure(util.MatchExists, "events.until", expectedEvent(*spec.Until), stream.events,
+				fmt.Sprintf("no event matching until received before %s (%d events read)", stopped, len(stream.events)))
+		} else {
+			v.failures = append(v.failures, v.matchEvent("events.until", *spec.Until, stream.events[stream.until], saved)...)
+		}
+	}
+
+	if spec.Count > 0 && len(stream.events) < spec.Count {
+		v.addFailure(util.BlockEvents, "", spec.Count, len(stream.events), util.MatchLength,
+			fmt.Sprintf("expected %d events, got %d before %s", spec.Count, len(stream.events), stopped))
+	}
+
+	for i, expected := range spec.Expect {
+		path := fmt.Sprintf("%s[%d]", util.BlockEvents, i)
+		if i >= len(stream.events) {
+			v.addPathFailure(util.MatchExists, path, expectedEvent(expected), nil,
+				fmt.Sprintf("event %d not received before %s", i, stopped))
+			continue
+		}
+		v.failures = append(v.failures, v.matchEvent(path, expected, stream.events[i], saved)...)
+	}
+}
+
+// matchEvent checks an event against an expected one and, when saved is not
+// nil, saves its values. It returns the failures instead of recording them,
+// so events can be tried against until.
+func (v *RestValidator) matchEvent(path string, expected schema.ExpectedEvent, event serverEvent, saved map[string]interface{}) []util.AssertionFailure {
+	check := newBlockValidator(v.name, v.config)
+
+	if expected.Event != "" && expected.Event != event.Event {
+		check.addPathFailure(util.MatchEquals, path+".event", expected.Event, event.Event,
+			fmt.Sprintf("%s.event: expected '%s', got '%s'", path, expected.Event, event.Event))
+	}
+
+	if expected.ID != nil {
+		id, err := util.FormatKeys(*expected.ID, v.config.Variables)
+		if err != nil {
+			check.addPathFailure(util.MatchFormat, path+".id", *expected.ID, nil,
+				fmt.Sprintf("failed to format expected id: %v", err))
+		} else if fmt.Sprintf("%v", id) != event.ID {
+			check.addPathFailure(util.MatchEquals, path+".id", id, event.ID,
+				fmt.Sprintf("%s.id: expected '%v', got '%s'", path, id, event.ID))
+		}
+	}
+
+	var data interface{}
+	_, isString := expected.Data.(string)
+	if (expected.Data != nil && !isString) || (expected.Save != nil && len(expected.Save.Data) > 0) {
+		if err := json.Unmarshal([]byte(event.Data), &data); err != nil {
+			check.addPathFailure(util.MatchType, path+".data", expected.Data, event.Data,
+				fmt.Sprintf("%s.data: event data is not JSON: %v", path, err))
+			return check.failures
+		}
+	}
+
+	switch expectedData := expected.Data.(type) {
+	case nil:
+	case string:
+		text, err := util.FormatKeys(expectedData, v.config.Variables)
+		if err != nil {
+			check.addPathFailure(util.MatchFormat, path+".data", expectedData, event.Data,
+				fmt.Sprintf("failed to format expected data: %v", err))
+		} else if fmt.Sprintf("%v", text) != event.Data {
+			check.addPathFailure(util.MatchEquals, path+".data", text, event.Data,
+				fmt.Sprintf("%s.data: expected '%v', got '%s'", path, text, event.Data))
+		}
+	case map[string]interface{}, []interface{}:
+		check.validateBlock(path+".data", data, expectedData)
+	default:
+		if !reflect.DeepEqual(normalizeJSON(expectedData), data) {
+			check.addPathFailure(util.MatchEquals, path+".data", expectedData, data,
+				fmt.Sprintf("%s.data: expected %v, got %v", path, expectedData, data))
+		}
+	}
+
+	if len(check.failures) == 0 && expected.Save != nil && saved != nil {
+		check.saveJSON("data", path, data, expected.Save.Data, saved)
+	}
+	return check.failures
+}
+
+// expectedEvent returns an expected event for failure reports
+func expectedEvent(expected schema.ExpectedEvent) interface{} {
+	event := map[string]interface{}{}
+	if expected.Event != "" {
+		event["event"] = expected.Event
+	}
+	if expected.ID != nil {
+		event["id"] = *expected.ID
+	}
+	if expected.Data != nil {
+		event["data"] = expected.Data
+	}
+	return event
+}
@@ -0,0 +1,149 @@
+package response
+
+import (
+	"bufio"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// completion is a streamed LLM completion
+const completion = `: connected
+
+event: token
+id: 1
+data: {"text": "Hel"}
+
+event: token
+id: 2
+data: {"text": "lo"}
+
+event: done
+data: {"finish_reason": "stop", "usage": {"tokens": 2}}
+
+`
+
+// streamResponse returns a text/event-stream response that sends stream and,
+// unless closed is set, stays open like a live endpoint
+func streamResponse(t *testing.T, stream string, closed bool) *http.Response {
+	t.Helper()
+	reader, writer := io.Pipe()
+	go func() {
+		_, _ = writer.Write([]byte(stream))
+		if closed {
+			_ = writer.Close()
+		}
+	}()
+	t.Cleanup(func() { _ = writer.Close() })
+
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"text/event-stream; charset=utf-8"}},
+		Body:       reader,
+	}
+}
+
+// TestEventReader tests parsing of event fields, multi-line data, comments and ids
+func TestEventReader(t *testing.T) {
+	stream := "data: first\r\n\r\nid: 7\nevent: update\ndata: line 1\ndata:line 2\n\n: keep-alive\n\ndata: same id\n\ndata: cut off"
+	reader := &eventReader{reader: bufio.NewReader(strings.NewReader(stream))}
+
+	var events []serverEvent
+	for {
+		event, err := reader.next()
+		if err != nil {
+			assert.ErrorIs(t, err, io.EOF)
+			break
+		}
+		events = append(events, event)
+	}
+
+	assert.Equal(t, []serverEvent{
+		{Event: "message", Data: "first"},
+		{Event: "update", ID: "7", Data: "line 1\nline 2"},
+		{Event: "message", ID: "7", Data: "same id"},
+	}, events)
+}
+
+// TestRestValidator_EventsUntil tests reading until a matching event on an open stream
+func TestRestValidator_EventsUntil(t *testing.T) {
+	id := "{first_id}"
+	spec := schema.ResponseSpec{
+		Events: &schema.EventsSpec{
+			Until: &schema.ExpectedEvent{
+				Event: "done",
+				Save:  &schema.EventSaveSpec{Data: map[string]string{"tokens": "usage.tokens"}},
+			},
+			Expect: []schema.ExpectedEvent{
+				{Event: "token", ID: &id, Data: map[string]interface{}{"text": "Hel"}},
+				{Event: "token", Data: map[string]interface{}{"text": "<<STR>>"}},
+			},
+		},
+	}
+	config := &Config{Variables: map[string]interface{}{"first_id": 1}}
+
+	start := time.Now()
+	saved, err := NewRestValidator("test", spec, config).Verify(streamResponse(t, completion, false))
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, map[string]interface{}{"tokens": float64(2)}, saved)
+}
+
+// TestRestValidator_EventsCount tests reading a number of events, and the timeout when too few arrive
+func TestRestValidator_EventsCount(t *testing.T) {
+	spec := schema.ResponseSpec{Events: &schema.EventsSpec{Count: 2}}
+	_, err := NewRestValidator("test", spec, nil).Verify(streamResponse(t, completion, false))
+	require.NoError(t, err)
+
+	spec.Events = &schema.EventsSpec{Count: 5, Timeout: 0.2}
+	validator := NewRestValidator("test", spec, nil)
+	_, err = validator.Verify(streamResponse(t, completion, false))
+	require.Error(t, err)
+	f := findFailure(validator.failures, util.BlockEvents, "")
+	require.NotNil(t, f)
+	assert.Equal(t, util.MatchLength, f.Matcher)
+	assert.Contains(t, f.Message, "expected 5 events, got 3 before 200ms passed")
+}
+
+// TestRestValidator_EventsFailures tests typed failures for events that don't match
+func TestRestValidator_EventsFailures(t *testing.T) {
+	spec := schema.ResponseSpec{
+		Events: &schema.EventsSpec{
+			Until: &schema.ExpectedEvent{Event: "error"},
+			Expect: []schema.ExpectedEvent{
+				{Event: "message"},
+				{Data: map[string]interface{}{"text": "world"}},
+				{},
+				{Event: "token"},
+			},
+		},
+	}
+	validator := NewRestValidator("test", spec, nil)
+	_, err := validator.Verify(streamResponse(t, completion, true))
+	require.Error(t, err)
+
+	failures := validator.failures
+	require.Len(t, failures, 4)
+	assert.Contains(t, findFailure(failures, util.BlockEvents, "until").Message, "before the stream ended (3 events read)")
+	assert.Equal(t, "token", findFailure(failures, util.BlockEvents, "[0].event").Actual)
+	assert.Equal(t, "lo", findFailure(failures, util.BlockEvents, "[1].data.text").Actual)
+	assert.Equal(t, util.MatchExists, findFailure(failures, util.BlockEvents, "[3]").Matcher)
+}
+
+// TestRestValidator_EventStreamWithoutEvents tests that an open stream without an events block doesn't hang
+func TestRestValidator_EventStreamWithoutEvents(t *testing.T) {
+	spec := schema.ResponseSpec{Events: &schema.EventsSpec{Timeout: 0.1}}
+	_, err := NewRestValidator("test", spec, nil).Verify(streamResponse(t, completion, false))
+	require.NoError(t, err)
+
+	_, err = NewRestValidator("test", schema.ResponseSpec{}, nil).Verify(streamResponse(t, completion, true))
+	require.NoError(t, err)
+}
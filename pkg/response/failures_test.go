@@ -0,0 +1,81 @@
+package response
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// findFailure returns the first failure in the given block and path
+func findFailure(failures []util.AssertionFailure, block, path string) *util.AssertionFailure {
+	for i := range failures {
+		if failures[i].Block == block && failures[i].Path == path {
+			return &failures[i]
+		}
+	}
+	return nil
+}
+
+// TestValidator_TypedFailures tests that validation errors carry structured failure details
+func TestValidator_TypedFailures(t *testing.T) {
+	spec := schema.ResponseSpec{
+		StatusCode: &schema.StatusCode{Single: 200},
+		Headers: map[string]interface{}{
+			"X-Request-Id": "abc",
+		},
+		Body: map[string]interface{}{
+			"user": map[string]interface{}{
+				"name": "alice",
+				"age":  "<<INT>>",
+			},
+			"email": "alice@example.com",
+		},
+	}
+
+	validator := NewRestValidator("test", spec, &Config{Variables: map[string]interface{}{}})
+
+	resp := createMockResponse(201, map[string]string{"X-Request-Id": "xyz"}, map[string]interface{}{
+		"user": map[string]interface{}{
+			"name": "bob",
+			"age":  "old",
+		},
+	})
+
+	_, err := validator.Verify(resp)
+	require.Error(t, err)
+
+	var failErr *util.TestFailError
+	require.True(t, errors.As(err, &failErr))
+	require.Len(t, failErr.Errors, len(failErr.Failures))
+
+	status := findFailure(failErr.Failures, util.BlockStatus, "")
+	require.NotNil(t, status)
+	assert.Equal(t, util.MatchEquals, status.Matcher)
+	assert.Equal(t, 201, status.Actual)
+
+	header := findFailure(failErr.Failures, util.BlockHeaders, "X-Request-Id")
+	require.NotNil(t, header)
+	assert.Equal(t, util.MatchEquals, header.Matcher)
+	assert.Equal(t, "abc", header.Expected)
+	assert.Equal(t, "xyz", header.Actual)
+
+	name := findFailure(failErr.Failures, util.BlockBody, "user.name")
+	require.NotNil(t, name)
+	assert.Equal(t, util.MatchEquals, name.Matcher)
+	assert.Equal(t, "alice", name.Expected)
+	assert.Equal(t, "bob", name.Actual)
+
+	age := findFailure(failErr.Failures, util.BlockBody, "user.age")
+	require.NotNil(t, age)
+	assert.Equal(t, util.MatchAnyInt, age.Matcher)
+	assert.Equal(t, "old", age.Actual)
+
+	email := findFailure(failErr.Failures, util.BlockBody, "email")
+	require.NotNil(t, email)
+	assert.Equal(t, util.MatchExists, email.Matcher)
+	assert.Nil(t, email.Actual)
+}
@@ -0,0 +1,106 @@
+package response
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// validateGraphQL checks a GraphQL result. A GraphQL server reports errors with
+// HTTP 200, so any error in the result fails the stage unless response.errors
+// expects it. Expected data is matched like body, under the data block.
+func (v *RestValidator) validateGraphQL(bodyData interface{}) {
+	if !v.config.GraphQL && v.spec.Data == nil && v.spec.Errors == nil {
+		return
+	}
+
+	result, ok := bodyData.(map[string]interface{})
+	if !ok {
+		v.addFailure(util.BlockBody, "", nil, bodyData, "",
+			"response is not a GraphQL result (expected a JSON object with data or errors)")
+		return
+	}
+
+	actualErrors, _ := result["errors"].([]interface{})
+	if v.spec.Errors == nil && len(actualErrors) > 0 {
+		messages := make([]string, 0, len(actualErrors))
+		for _, actual := range actualErrors {
+			message, _ := graphQLError(actual)
+			messages = append(messages, message)
+		}
+		v.addFailure(util.BlockErrors, "", nil, result["errors"], "",
+			fmt.Sprintf("GraphQL response has errors: %s", strings.Join(messages, "; ")))
+	}
+
+	for i, expected := range v.spec.Errors {
+		message, err := util.FormatKeys(expected.Message, v.config.Variables)
+		if err != nil {
+			v.addFailure(util.BlockErrors, fmt.Sprintf("[%d]", i), expected.Message, nil, "",
+				fmt.Sprintf("failed to format expected error: %v", err))
+			continue
+		}
+		code, err := util.FormatKeys(expected.Code, v.config.Variables)
+		if err != nil {
+			v.addFailure(util.BlockErrors, fmt.Sprintf("[%d]", i), expected.Code, nil, "",
+				fmt.Sprintf("failed to format expected error: %v", err))
+			continue
+		}
+
+		wantMessage, wantCode := fmt.Sprintf("%v", message), fmt.Sprintf("%v", code)
+		found := false
+		for _, actual := range actualErrors {
+			gotMessage, gotCode := graphQLError(actual)
+			if (wantMessage == "" || wantMessage == gotMessage) && (wantCode == "" || wantCode == gotCode) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			want := map[string]interface{}{}
+			if wantMessage != "" {
+				want["message"] = wantMessage
+			}
+			if wantCode != "" {
+				want["code"] = wantCode
+			}
+			v.addFailure(util.BlockErrors, fmt.Sprintf("[%d]", i), want, result["errors"], util.MatchContains,
+				"no GraphQL error matches the expected error")
+		}
+	}
+
+	if v.spec.Data != nil {
+		v.validateBlock(util.BlockData, result["data"], v.spec.Data)
+	}
+}
+
+// saveGraphQL saves values from paths relative to the data of a GraphQL result
+func (v *RestValidator) saveGraphQL(bodyData interface{}, paths map[string]string, saved map[string]interface{}) {
+	result, _ := bodyData.(map[string]interface{})
+	data, ok := result["data"]
+	if !ok || data == nil {
+		v.addFailure(util.BlockSave, util.BlockData, nil, nil, util.MatchExists,
+			"no data in GraphQL response for saving")
+		return
+	}
+
+	for saveName, path := range paths {
+		val, err := v.extractValue(data, path)
+		if err != nil {
+			v.addFailure(util.BlockSave, "data."+saveName, path, nil, util.MatchExists,
+				fmt.Sprintf("failed to save %s from data: %v", saveName, err))
+			continue
+		}
+		saved[saveName] = val
+	}
+}
+
+// graphQLError returns the message and extensions.code of a GraphQL error
+func graphQLError(err interface{}) (message, code string) {
+	fields, _ := err.(map[string]interface{})
+	message, _ = fields["message"].(string)
+	if extensions, ok := fields["extensions"].(map[string]interface{}); ok && extensions["code"] != nil {
+		code = fmt.Sprintf("%v", extensions["code"])
+	}
+	return message, code
+}
@@ -0,0 +1,105 @@
+package response
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// graphQLResult is a result with both data and an error, as returned for a partial failure
+var graphQLResult = map[string]interface{}{
+	"data": map[string]interface{}{
+		"user": map[string]interface{}{"id": "42", "name": "alice", "posts": []interface{}{}},
+	},
+	"errors": []interface{}{
+		map[string]interface{}{
+			"message":    "Not authorized to read posts",
+			"extensions": map[string]interface{}{"code": "FORBIDDEN"},
+		},
+	},
+}
+
+// TestValidator_GraphQLErrors tests that errors in a 200 response fail unless expected
+func TestValidator_GraphQLErrors(t *testing.T) {
+	t.Run("unexpected errors fail the stage", func(t *testing.T) {
+		validator := NewRestValidator("test", schema.ResponseSpec{}, &Config{GraphQL: true})
+		_, err := validator.Verify(createMockResponse(200, nil, graphQLResult))
+		require.Error(t, err)
+
+		f := findFailure(validator.failures, util.BlockErrors, "")
+		require.NotNil(t, f)
+		assert.Contains(t, f.Message, "Not authorized to read posts")
+	})
+
+	t.Run("errors are ignored for non-GraphQL stages", func(t *testing.T) {
+		validator := NewRestValidator("test", schema.ResponseSpec{}, &Config{})
+		_, err := validator.Verify(createMockResponse(200, nil, graphQLResult))
+		assert.NoError(t, err)
+	})
+
+	t.Run("expected errors match by code or message", func(t *testing.T) {
+		spec := schema.ResponseSpec{Errors: []schema.GraphQLErrorSpec{
+			{Code: "FORBIDDEN"},
+			{Message: "Not authorized to read {what}", Code: "FORBIDDEN"},
+		}}
+		config := &Config{GraphQL: true, Variables: map[string]interface{}{"what": "posts"}}
+		_, err := NewRestValidator("test", spec, config).Verify(createMockResponse(200, nil, graphQLResult))
+		assert.NoError(t, err)
+	})
+
+	t.Run("missing expected error", func(t *testing.T) {
+		spec := schema.ResponseSpec{Errors: []schema.GraphQLErrorSpec{
+			{Code: "FORBIDDEN"},
+			{Code: "NOT_FOUND"},
+		}}
+		validator := NewRestValidator("test", spec, &Config{GraphQL: true})
+		_, err := validator.Verify(createMockResponse(200, nil, graphQLResult))
+		require.Error(t, err)
+
+		require.Len(t, validator.failures, 1)
+		f := findFailure(validator.failures, util.BlockErrors, "[1]")
+		require.NotNil(t, f)
+		assert.Equal(t, util.MatchContains, f.Matcher)
+		assert.Equal(t, map[string]interface{}{"code": "NOT_FOUND"}, f.Expected)
+	})
+
+	t.Run("response that is not a result", func(t *testing.T) {
+		validator := NewRestValidator("test", schema.ResponseSpec{}, &Config{GraphQL: true})
+		_, err := validator.Verify(createMockResponse(200, nil, []interface{}{1, 2}))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not a GraphQL result")
+	})
+}
+
+// TestValidator_GraphQLData tests data assertions and saving from data
+func TestValidator_GraphQLData(t *testing.T) {
+	save, err := schema.NewSaveConfigFromInterface(map[string]interface{}{
+		"data": map[string]interface{}{"user_id": "user.id"},
+	})
+	require.NoError(t, err)
+
+	spec := schema.ResponseSpec{
+		Data: map[string]interface{}{
+			"user": map[string]interface{}{"name": "alice"},
+		},
+		Errors: []schema.GraphQLErrorSpec{{Code: "FORBIDDEN"}},
+		Save:   save,
+	}
+	saved, err := NewRestValidator("test", spec, &Config{GraphQL: true}).Verify(createMockResponse(200, nil, graphQLResult))
+	require.NoError(t, err)
+	assert.Equal(t, "42", saved["user_id"])
+
+	spec.Data = map[string]interface{}{
+		"user": map[string]interface{}{"name": "bob"},
+	}
+	validator := NewRestValidator("test", spec, &Config{GraphQL: true})
+	_, err = validator.Verify(createMockResponse(200, nil, graphQLResult))
+	require.Error(t, err)
+	f := findFailure(validator.failures, util.BlockData, "user.name")
+	require.NotNil(t, f)
+	assert.Equal(t, "bob", f.Expected)
+	assert.Equal(t, "alice", f.Actual)
+}
@@ -0,0 +1,182 @@
+package response
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/request"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+	"google.golang.org/grpc/codes"
+)
+
+// GRPCValidator validates gRPC responses
+type GRPCValidator struct {
+	blockValidator
+	spec schema.GRPCResponseSpec
+}
+
+// NewGRPCValidator creates a new gRPC response validator
+func NewGRPCValidator(name string, spec schema.GRPCResponseSpec, config *Config) *GRPCValidator {
+	if config == nil {
+		config = &Config{
+			Variables: make(map[string]interface{}),
+		}
+	}
+
+	return &GRPCValidator{
+		blockValidator: newBlockValidator(name, config),
+		spec:           spec,
+	}
+}
+
+// Verify validates the status, message and metadata of a gRPC response and
+// returns the saved variables
+func (v *GRPCValidator) Verify(response interface{}) (map[string]interface{}, error) {
+	resp, ok := response.(*request.GRPCResponse)
+	if !ok {
+		return nil, fmt.Errorf("expected *request.GRPCResponse, got %T", response)
+	}
+	saved := make(map[string]interface{})
+
+	// Status defaults to OK
+	expectedCode := codes.OK
+	if v.spec.Status != "" {
+		code, err := parseCode(v.spec.Status)
+		if err != nil {
+			v.addFailure(util.BlockStatus, "", v.spec.Status, nil, "", err.Error())
+			return nil, v.formatErrors()
+		}
+		expectedCode = code
+	}
+	if resp.Status.Code() != expectedCode {
+		expected, actual := codeName(expectedCode), codeName(resp.Status.Code())
+		msg := fmt.Sprintf("status: expected %s, got %s", expected, actual)
+		if resp.Status.Message() != "" {
+			msg += ": " + resp.Status.Message()
+		}
+		v.addFailure(util.BlockStatus, "", expected, actual, util.MatchEquals, msg)
+	}
+
+	if v.spec.Message != nil {
+		expected, err := util.FormatKeys(*v.spec.Message, v.config.Variables)
+		if err != nil {
+			v.addFailure(util.BlockStatus, "message", *v.spec.Message, nil, util.MatchFormat,
+				fmt.Sprintf("failed to format status message: %v", err))
+		} else if expected != resp.Status.Message() {
+			v.addFailure(util.BlockStatus, "message", expected, resp.Status.Message(), util.MatchEquals,
+				fmt.Sprintf("status message: expected '%v', got '%s'", expected, resp.Status.Message()))
+		}
+	}
+
+	if v.spec.Body != nil {
+		v.validateBlock(util.BlockBody, resp.Body, v.spec.Body)
+	}
+
+	if v.spec.Metadata != nil {
+		v.validateMetadata(resp, v.spec.Metadata)
+	}
+
+	if v.spec.Save != nil {
+		for saveName, path := range v.spec.Save.Body {
+			val, err := v.extractValue(resp.Body, path)
+			if err != nil {
+				v.addFailure(util.BlockSave, "body."+saveName, path, nil, util.MatchExists,
+					fmt.Sprintf("failed to save %s from body: %v", saveName, err))
+				continue
+			}
+			saved[saveName] = val
+		}
+		for saveName, key := range v.spec.Save.Metadata {
+			values := metadataValues(resp, key)
+			if len(values) == 0 {
+				v.addFailure(util.BlockSave, "metadata."+saveName, key, nil, util.MatchExists,
+					fmt.Sprintf("metadata %s not found for saving as %s", key, saveName))
+				continue
+			}
+			saved[saveName] = values[0]
+		}
+	}
+
+	if len(v.failures) > 0 {
+		return nil, v.formatErrors()
+	}
+	return saved, nil
+}
+
+// validateMetadata checks that each expected key is in the header or trailer
+// metadata, with a null value only checking that it is present
+func (v *GRPCValidator) validateMetadata(resp *request.GRPCResponse, expected map[string]interface{}) {
+	formattedExpected, err := util.FormatKeys(expected, v.config.Variables)
+	if err != nil {
+		v.addFailure(util.BlockMetadata, "", nil, nil, util.MatchFormat,
+			fmt.Sprintf("failed to format metadata: %v", err))
+		return
+	}
+	expectedMap, ok := formattedExpected.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	for key, expectedVal := range expectedMap {
+		values := metadataValues(resp, key)
+		if len(values) == 0 {
+			v.addFailure(util.BlockMetadata, key, expectedVal, nil, util.MatchExists,
+				fmt.Sprintf("metadata %s not found", key))
+			continue
+		}
+		if expectedVal == nil {
+			continue
+		}
+
+		expectedStr := fmt.Sprintf("%v", expectedVal)
+		found := false
+		for _, value := range values {
+			if value == expectedStr {
+				found = true
+				break
+			}
+		}
+		if !found {
+			var actual interface{} = values
+			if len(values) == 1 {
+				actual = values[0]
+			}
+			v.addFailure(util.BlockMetadata, key, expectedVal, actual, util.MatchEquals,
+				fmt.Sprintf("metadata %s: expected '%v', got %v", key, expectedVal, values))
+		}
+	}
+}
+
+// metadataValues returns the values of a key in the header and trailer metadata
+func metadataValues(resp *request.GRPCResponse, key string) []string {
+	return append(resp.Header.Get(key), resp.Trailer.Get(key)...)
+}
+
+// parseCode parses a status code name such as NOT_FOUND, or its number
+func parseCode(s string) (codes.Code, error) {
+	if n, err := strconv.Atoi(s); err == nil {
+		if n < 0 || n > int(codes.Unauthenticated) {
+			return 0, fmt.Errorf("invalid gRPC status code %d", n)
+		}
+		return codes.Code(n), nil
+	}
+	var code codes.Code
+	if err := code.UnmarshalJSON([]byte(strconv.Quote(strings.ToUpper(s)))); err != nil {
+		return 0, fmt.Errorf("invalid gRPC status code %q", s)
+	}
+	return code, nil
+}
+
+// codeName returns the canonical name of a status code, such as NOT_FOUND
+func codeName(code codes.Code) string {
+	var name strings.Builder
+	for i, r := range code.String() {
+		if i > 0 && r >= 'A' && r <= 'Z' && code.String()[i-1] >= 'a' {
+			name.WriteByte('_')
+		}
+		name.WriteRune(r)
+	}
+	return strings.ToUpper(name.String())
+}
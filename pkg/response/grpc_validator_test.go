@@ -0,0 +1,93 @@
+package response
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/request"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcReply is a successful response to a user lookup
+func grpcReply() *request.GRPCResponse {
+	return &request.GRPCResponse{
+		Status: status.New(codes.OK, ""),
+		Body: map[string]interface{}{
+			"user": map[string]interface{}{"id": "42", "name": "alice"},
+		},
+		Header:  metadata.Pairs("x-request-id", "req-1"),
+		Trailer: metadata.Pairs("x-cache", "miss"),
+	}
+}
+
+// TestGRPCValidator_Success tests body, metadata and saving on a successful call
+func TestGRPCValidator_Success(t *testing.T) {
+	spec := schema.GRPCResponseSpec{
+		Body: map[string]interface{}{
+			"user": map[string]interface{}{"name": "{name}", "id": "<<STR>>"},
+		},
+		Metadata: map[string]interface{}{"x-request-id": nil, "X-Cache": "miss"},
+		Save: &schema.GRPCSaveSpec{
+			Body:     map[string]string{"user_id": "user.id"},
+			Metadata: map[string]string{"request_id": "x-request-id"},
+		},
+	}
+	config := &Config{Variables: map[string]interface{}{"name": "alice"}}
+
+	saved, err := NewGRPCValidator("test", spec, config).Verify(grpcReply())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"user_id": "42", "request_id": "req-1"}, saved)
+}
+
+// TestGRPCValidator_Status tests expected status codes, by name or number, and messages
+func TestGRPCValidator_Status(t *testing.T) {
+	notFound := &request.GRPCResponse{Status: status.New(codes.NotFound, "user 7 not found")}
+	message := "user {id} not found"
+	config := &Config{Variables: map[string]interface{}{"id": 7}}
+
+	for _, code := range []string{"NOT_FOUND", "not_found", "5"} {
+		spec := schema.GRPCResponseSpec{Status: code, Message: &message}
+		_, err := NewGRPCValidator("test", spec, config).Verify(notFound)
+		assert.NoError(t, err, code)
+	}
+
+	validator := NewGRPCValidator("test", schema.GRPCResponseSpec{}, config)
+	_, err := validator.Verify(notFound)
+	require.Error(t, err)
+	f := findFailure(validator.failures, util.BlockStatus, "")
+	require.NotNil(t, f)
+	assert.Equal(t, "OK", f.Expected)
+	assert.Equal(t, "NOT_FOUND", f.Actual)
+	assert.Contains(t, f.Message, "user 7 not found")
+
+	_, err = NewGRPCValidator("test", schema.GRPCResponseSpec{Status: "MISSING"}, config).Verify(notFound)
+	assert.ErrorContains(t, err, `invalid gRPC status code "MISSING"`)
+}
+
+// TestGRPCValidator_Failures tests typed failures for body, metadata and saves
+func TestGRPCValidator_Failures(t *testing.T) {
+	spec := schema.GRPCResponseSpec{
+		Body: map[string]interface{}{
+			"user": map[string]interface{}{"name": "bob"},
+		},
+		Metadata: map[string]interface{}{"x-cache": "hit", "x-trace": nil},
+		Save: &schema.GRPCSaveSpec{
+			Metadata: map[string]string{"session": "x-session"},
+		},
+	}
+	validator := NewGRPCValidator("test", spec, nil)
+	_, err := validator.Verify(grpcReply())
+	require.Error(t, err)
+
+	failures := validator.failures
+	require.Len(t, failures, 4)
+	assert.Equal(t, "alice", findFailure(failures, util.BlockBody, "user.name").Actual)
+	assert.Equal(t, "miss", findFailure(failures, util.BlockMetadata, "x-cache").Actual)
+	assert.Equal(t, util.MatchExists, findFailure(failures, util.BlockMetadata, "x-trace").Matcher)
+	assert.NotNil(t, findFailure(failures, util.BlockSave, "metadata.session"))
+}
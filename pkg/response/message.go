@@ -0,0 +1,97 @@
+package response
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// validateMessage checks a received message (websocket frame, MQTT payload,
+// command output) against an expected exact text and/or JSON value, where a
+// mapping or list is checked like a body. The payload is decoded as JSON when
+// JSON is expected or decode is set, and returned; ok is false if it is not JSON.
+func (v *blockValidator) validateMessage(path string, data []byte, text *string, expectedJSON interface{}, decode bool) (payload interface{}, ok bool) {
+	if text != nil {
+		formatted, err := util.FormatKeys(*text, v.config.Variables)
+		if err != nil {
+			v.addPathFailure(util.MatchFormat, path, *text, nil,
+				fmt.Sprintf("failed to format expected text: %v", err))
+		} else if fmt.Sprintf("%v", formatted) != string(data) {
+			v.addPathFailure(util.MatchEquals, path, formatted, string(data),
+				fmt.Sprintf("%s: expected '%v', got '%s'", path, formatted, data))
+		}
+	}
+
+	if expectedJSON == nil && !decode {
+		return nil, true
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		v.addPathFailure(util.MatchType, path, expectedJSON, string(data),
+			fmt.Sprintf("%s is not JSON: %v", path, err))
+		return nil, false
+	}
+
+	switch expected := expectedJSON.(type) {
+	case nil:
+	case map[string]interface{}, []interface{}:
+		v.validateBlock(path, payload, expected)
+	default:
+		value, err := util.FormatKeys(expected, v.config.Variables)
+		if err != nil {
+			v.addPathFailure(util.MatchFormat, path, expected, payload,
+				fmt.Sprintf("failed to format expected json: %v", err))
+		} else if !reflect.DeepEqual(normalizeJSON(value), payload) {
+			v.addPathFailure(util.MatchEquals, path, value, payload,
+				fmt.Sprintf("%s: expected %v, got %v", path, value, payload))
+		}
+	}
+	return payload, true
+}
+
+// saveJSON saves the values at paths in a decoded message into saved. Nothing
+// is saved unless every path is found; failures are recorded as save.<block>.<name>.
+func (v *blockValidator) saveJSON(block, from string, payload interface{}, paths map[string]string, saved map[string]interface{}) {
+	values := make(map[string]interface{}, len(paths))
+	failed := false
+	for saveName, key := range paths {
+		val, err := v.extractValue(payload, key)
+		if err != nil {
+			v.addFailure(util.BlockSave, block+"."+saveName, key, nil, util.MatchExists,
+				fmt.Sprintf("failed to save %s from %s: %v", saveName, from, err))
+			failed = true
+			continue
+		}
+		values[saveName] = val
+	}
+	if failed {
+		return
+	}
+	for k, val := range values {
+		saved[k] = val
+	}
+}
+
+// decodePayload returns a received message as JSON if it parses, or as text,
+// for failure reports
+func decodePayload(data []byte) interface{} {
+	var payload interface{}
+	if err := json.Unmarshal(data, &payload); err == nil {
+		return payload
+	}
+	return string(data)
+}
+
+// normalizeJSON converts a YAML value to the types encoding/json decodes to
+func normalizeJSON(value interface{}) interface{} {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
@@ -0,0 +1,96 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/systemquest/tavern-go/pkg/request"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// topicReceiver is the receiving side of an MQTT connection
+type topicReceiver interface {
+	Receive(topic string, deadline time.Time) (request.MQTTMessage, error)
+}
+
+// MQTTValidator validates a message received on an MQTT topic
+type MQTTValidator struct {
+	blockValidator
+	spec schema.MQTTResponseSpec
+}
+
+// NewMQTTValidator creates a new MQTT response validator
+func NewMQTTValidator(name string, spec schema.MQTTResponseSpec, config *Config) *MQTTValidator {
+	if config == nil {
+		config = &Config{
+			Variables: make(map[string]interface{}),
+		}
+	}
+
+	return &MQTTValidator{
+		blockValidator: newBlockValidator(name, config),
+		spec:           spec,
+	}
+}
+
+// Verify receives messages on the topic from a *request.MQTTClient until one
+// matches or the timeout passes, and returns the saved variables. Messages
+// that don't match are skipped (aligned with tavern-py).
+func (v *MQTTValidator) Verify(response interface{}) (map[string]interface{}, error) {
+	receiver, ok := response.(topicReceiver)
+	if !ok {
+		return nil, fmt.Errorf("expected *request.MQTTClient, got %T", response)
+	}
+
+	timeout := time.Second
+	if v.spec.Timeout > 0 {
+		timeout = time.Duration(v.spec.Timeout * float64(time.Second))
+	}
+	deadline := time.Now().Add(timeout)
+	hasSaves := v.spec.Save != nil && len(v.spec.Save.JSON) > 0
+
+	var received []interface{}
+	var lastFailures []util.AssertionFailure
+	for {
+		msg, err := receiver.Receive(v.spec.Topic, deadline)
+		if err != nil {
+			reason := err.Error()
+			if errors.Is(err, request.ErrMQTTTimeout) {
+				reason = fmt.Sprintf("no matching message within %s", timeout)
+			}
+			v.addFailure(util.BlockPayload, "", expectedMQTTPayload(v.spec), received, util.MatchExists,
+				fmt.Sprintf("topic %s: %s (%d received)", v.spec.Topic, reason, len(received)))
+			// The closest miss explains what was wrong with the messages received
+			v.failures = append(v.failures, lastFailures...)
+			return nil, v.formatErrors()
+		}
+		v.logger.Debugf("Received MQTT message on %s: %s", msg.Topic, msg.Payload)
+
+		check := newBlockValidator(v.name, v.config)
+		payload, ok := check.validateMessage(util.BlockPayload, msg.Payload, v.spec.Payload, v.spec.JSON, hasSaves)
+		if ok && len(check.failures) == 0 {
+			saved := make(map[string]interface{})
+			if hasSaves {
+				check.saveJSON("json", "payload", payload, v.spec.Save.JSON, saved)
+			}
+			if len(check.failures) > 0 {
+				v.failures = append(v.failures, check.failures...)
+				return nil, v.formatErrors()
+			}
+			return saved, nil
+		}
+
+		received = append(received, decodePayload(msg.Payload))
+		lastFailures = check.failures
+	}
+}
+
+// expectedMQTTPayload returns the expected text or JSON payload for failure reports
+func expectedMQTTPayload(spec schema.MQTTResponseSpec) interface{} {
+	if spec.Payload != nil {
+		return *spec.Payload
+	}
+	return spec.JSON
+}
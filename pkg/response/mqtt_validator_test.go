@@ -0,0 +1,82 @@
+package response
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/request"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// fakeBroker returns queued messages for any topic, then times out
+type fakeBroker struct {
+	payloads []string
+}
+
+func (f *fakeBroker) Receive(topic string, _ time.Time) (request.MQTTMessage, error) {
+	if len(f.payloads) == 0 {
+		return request.MQTTMessage{}, request.ErrMQTTTimeout
+	}
+	payload := f.payloads[0]
+	f.payloads = f.payloads[1:]
+	return request.MQTTMessage{Topic: topic, Payload: []byte(payload)}, nil
+}
+
+// deviceStatus is a stream of status messages from a device
+func deviceStatus() *fakeBroker {
+	return &fakeBroker{payloads: []string{
+		`booting`,
+		`{"state": "starting"}`,
+		`{"state": "online", "firmware": {"version": "1.2.0"}, "battery": 87}`,
+	}}
+}
+
+// TestMQTTValidator_SkipsUntilMatch tests that messages are skipped until one matches, and saving from it
+func TestMQTTValidator_SkipsUntilMatch(t *testing.T) {
+	spec := schema.MQTTResponseSpec{
+		Topic: "devices/d1/status",
+		JSON:  map[string]interface{}{"state": "{state}", "battery": "<<INT>>"},
+		Save:  &schema.MQTTSaveSpec{JSON: map[string]string{"firmware": "firmware.version"}},
+	}
+	config := &Config{Variables: map[string]interface{}{"state": "online"}}
+
+	saved, err := NewMQTTValidator("test", spec, config).Verify(deviceStatus())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"firmware": "1.2.0"}, saved)
+
+	booting := "booting"
+	saved, err = NewMQTTValidator("test", schema.MQTTResponseSpec{Topic: "t", Payload: &booting}, nil).Verify(deviceStatus())
+	require.NoError(t, err)
+	assert.Empty(t, saved)
+}
+
+// TestMQTTValidator_Failures tests typed failures when no message matches
+func TestMQTTValidator_Failures(t *testing.T) {
+	spec := schema.MQTTResponseSpec{
+		Topic:   "devices/d1/status",
+		JSON:    map[string]interface{}{"state": "offline"},
+		Timeout: 0.5,
+	}
+	validator := NewMQTTValidator("test", spec, nil)
+	_, err := validator.Verify(deviceStatus())
+	require.Error(t, err)
+
+	f := findFailure(validator.failures, util.BlockPayload, "")
+	require.NotNil(t, f)
+	assert.Equal(t, util.MatchExists, f.Matcher)
+	assert.Contains(t, f.Message, "topic devices/d1/status: no matching message within 500ms (3 received)")
+	assert.Len(t, f.Actual, 3)
+	assert.Equal(t, "online", findFailure(validator.failures, util.BlockPayload, "state").Actual)
+
+	spec = schema.MQTTResponseSpec{
+		Topic: "devices/d1/status",
+		Save:  &schema.MQTTSaveSpec{JSON: map[string]string{"serial": "serial"}},
+	}
+	validator = NewMQTTValidator("test", spec, nil)
+	_, err = validator.Verify(&fakeBroker{payloads: []string{`{"state": "online"}`}})
+	require.Error(t, err)
+	assert.NotNil(t, findFailure(validator.failures, util.BlockSave, "json.serial"))
+}
@@ -0,0 +1,54 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// validateOpenAPI checks the response against the loaded OpenAPI contract.
+// With response.openapi unset the check runs whenever an operation matches the
+// request; true makes a missing contract or operation a failure; false skips it.
+func (v *RestValidator) validateOpenAPI(resp *http.Response, bodyBytes []byte) {
+	required := v.spec.OpenAPI != nil && *v.spec.OpenAPI
+	if v.spec.OpenAPI != nil && !required {
+		return
+	}
+
+	if v.config.OpenAPI == nil {
+		if required {
+			v.addFailure(util.BlockBody, "", nil, nil, util.MatchOpenAPI,
+				"response.openapi is set but no OpenAPI document was loaded (use --openapi)")
+		}
+		return
+	}
+	if resp.Request == nil || resp.Request.URL == nil {
+		if required {
+			v.addFailure(util.BlockStatus, "", nil, nil, util.MatchOpenAPI,
+				"cannot match the response to an OpenAPI operation without its request")
+		}
+		return
+	}
+
+	method, path := resp.Request.Method, resp.Request.URL.Path
+	op, violations, err := v.config.OpenAPI.ValidateResponse(method, path, resp.StatusCode, resp.Header, bodyBytes)
+	if err != nil {
+		v.addFailure(util.BlockBody, "", nil, nil, util.MatchOpenAPI, fmt.Sprintf("openapi: %v", err))
+		return
+	}
+	if op == nil {
+		if required {
+			v.addFailure(util.BlockStatus, "", nil, nil, util.MatchOpenAPI,
+				fmt.Sprintf("openapi: no operation matches %s %s", method, path))
+		} else {
+			v.logger.Debugf("No OpenAPI operation matches %s %s, skipping contract validation", method, path)
+		}
+		return
+	}
+
+	for _, violation := range violations {
+		v.addFailure(violation.Part, violation.Path, violation.Expected, violation.Actual, util.MatchOpenAPI,
+			fmt.Sprintf("openapi (%s %s): %s", op.Method, op.Path, violation.Message))
+	}
+}
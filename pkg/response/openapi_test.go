@@ -0,0 +1,88 @@
+package response
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/openapi"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+const openAPIDoc = `
+openapi: 3.0.3
+info: {title: Items, version: "1"}
+paths:
+  /items/{id}:
+    get:
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: object
+                required: [id, name]
+                properties:
+                  id: {type: integer}
+                  name: {type: string}
+`
+
+// openAPIResponse builds a mock response to GET path
+func openAPIResponse(t *testing.T, path string, body interface{}) *http.Response {
+	resp := createMockResponse(200, nil, body)
+	req, err := http.NewRequest("GET", "http://localhost"+path, nil)
+	require.NoError(t, err)
+	resp.Request = req
+	return resp
+}
+
+// TestValidator_OpenAPI tests that responses are checked against the loaded contract
+func TestValidator_OpenAPI(t *testing.T) {
+	spec, err := openapi.Parse([]byte(openAPIDoc))
+	require.NoError(t, err)
+	enabled, disabled := true, false
+
+	t.Run("body violation is reported as a typed failure", func(t *testing.T) {
+		validator := NewRestValidator("test", schema.ResponseSpec{}, &Config{OpenAPI: spec})
+		_, err := validator.Verify(openAPIResponse(t, "/items/1", map[string]interface{}{"id": "one"}))
+		require.Error(t, err)
+
+		failures := validator.failures
+		f := findFailure(failures, util.BlockBody, "id")
+		require.NotNil(t, f)
+		assert.Equal(t, util.MatchOpenAPI, f.Matcher)
+		assert.Contains(t, f.Message, "GET /items/{id}")
+		assert.NotNil(t, findFailure(failures, util.BlockBody, ""), "Missing required name")
+	})
+
+	t.Run("conforming response passes", func(t *testing.T) {
+		validator := NewRestValidator("test", schema.ResponseSpec{}, &Config{OpenAPI: spec})
+		_, err := validator.Verify(openAPIResponse(t, "/items/1", map[string]interface{}{"id": 1, "name": "x"}))
+		assert.NoError(t, err)
+	})
+
+	t.Run("openapi false skips validation", func(t *testing.T) {
+		validator := NewRestValidator("test", schema.ResponseSpec{OpenAPI: &disabled}, &Config{OpenAPI: spec})
+		_, err := validator.Verify(openAPIResponse(t, "/items/1", map[string]interface{}{"id": "one"}))
+		assert.NoError(t, err)
+	})
+
+	t.Run("unmatched operation is only an error when required", func(t *testing.T) {
+		validator := NewRestValidator("test", schema.ResponseSpec{}, &Config{OpenAPI: spec})
+		_, err := validator.Verify(openAPIResponse(t, "/other", nil))
+		assert.NoError(t, err)
+
+		validator = NewRestValidator("test", schema.ResponseSpec{OpenAPI: &enabled}, &Config{OpenAPI: spec})
+		_, err = validator.Verify(openAPIResponse(t, "/other", nil))
+		assert.ErrorContains(t, err, "no operation matches GET /other")
+	})
+
+	t.Run("openapi true without a document fails", func(t *testing.T) {
+		validator := NewRestValidator("test", schema.ResponseSpec{OpenAPI: &enabled}, &Config{})
+		_, err := validator.Verify(openAPIResponse(t, "/items/1", nil))
+		assert.ErrorContains(t, err, "--openapi")
+	})
+}
@@ -0,0 +1,93 @@
+package response
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// validateOutput validates text output (command stdout/stderr, a TCP reply).
+// The output is parsed as JSON when the spec has a json assertion or decode is
+// set, and returned; ok is false if it is not JSON.
+func (v *blockValidator) validateOutput(stream string, actual string, expected *schema.OutputSpec, decode bool) (payload interface{}, ok bool) {
+	if expected == nil {
+		expected = &schema.OutputSpec{}
+	}
+	if expected.Equals != nil {
+		// Check exact match, ignoring a trailing newline and indentation
+		if want, ok := v.formatOutput(stream, util.MatchEquals, *expected.Equals); ok && strings.TrimSpace(actual) != want {
+			v.addFailure(stream, "", want, actual, util.MatchEquals,
+				fmt.Sprintf("%s: expected '%s', got '%s'", stream, want, strings.TrimSpace(actual)))
+		}
+	}
+	if expected.Contains != nil {
+		if want, ok := v.formatOutput(stream, util.MatchContains, *expected.Contains); ok && !strings.Contains(actual, want) {
+			v.addFailure(stream, "", want, actual, util.MatchContains,
+				fmt.Sprintf("%s: expected to contain '%s'", stream, want))
+		}
+	}
+	if expected.NotContains != nil {
+		if want, ok := v.formatOutput(stream, util.MatchNotContains, *expected.NotContains); ok && strings.Contains(actual, want) {
+			v.addFailure(stream, "", want, actual, util.MatchNotContains,
+				fmt.Sprintf("%s: should not contain '%s'", stream, want))
+		}
+	}
+	if expected.Matches != nil {
+		// Patterns are not formatted, since {} is regex syntax
+		matched, err := regexp.MatchString(*expected.Matches, actual)
+		if err != nil {
+			v.addFailure(stream, "", *expected.Matches, actual, util.MatchRegex,
+				fmt.Sprintf("%s: invalid regex '%s': %v", stream, *expected.Matches, err))
+		} else if !matched {
+			v.addFailure(stream, "", *expected.Matches, actual, util.MatchRegex,
+				fmt.Sprintf("%s: expected to match regex '%s'", stream, *expected.Matches))
+		}
+	}
+
+	return v.validateMessage(stream, []byte(actual), nil, expected.JSON, decode)
+}
+
+// formatOutput formats an expected string with the test variables, recording a failure if it can't
+func (v *blockValidator) formatOutput(stream, matcher, expected string) (string, bool) {
+	formatted, err := util.FormatKeys(expected, v.config.Variables)
+	if err != nil {
+		v.addFailure(stream, "", expected, nil, util.MatchFormat,
+			fmt.Sprintf("%s: failed to format expected %s value: %v", stream, matcher, err))
+		return "", false
+	}
+	return fmt.Sprintf("%v", formatted), true
+}
+
+// saveOutput saves values from output with regular expressions
+func (v *blockValidator) saveOutput(stream, output string, patterns map[string]string, saved map[string]interface{}) {
+	for varName, pattern := range patterns {
+		value, err := extractFromOutput(output, pattern)
+		if err != nil {
+			v.addFailure(util.BlockSave, stream+"."+varName, pattern, output, util.MatchRegex,
+				fmt.Sprintf("failed to save %s from %s: %v", varName, stream, err))
+			continue
+		}
+		saved[varName] = value
+	}
+}
+
+// extractFromOutput extracts value from output using regex
+func extractFromOutput(output string, pattern string) (string, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid regex '%s': %w", pattern, err)
+	}
+
+	matches := re.FindStringSubmatch(output)
+	if len(matches) > 1 {
+		return matches[1], nil // Return first capture group
+	}
+	if len(matches) > 0 {
+		return matches[0], nil // Return full match
+	}
+
+	return "", fmt.Errorf("regex '%s' did not match", pattern)
+}
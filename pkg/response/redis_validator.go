@@ -0,0 +1,179 @@
+package response
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/systemquest/tavern-go/pkg/request"
+	"github.com/systemquest/tavern-go/pkg/resp"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// RedisValidator validates the reply of a Redis stage
+type RedisValidator struct {
+	blockValidator
+	spec schema.RedisSpec
+}
+
+// NewRedisValidator creates a new Redis reply validator
+func NewRedisValidator(name string, spec schema.RedisSpec, config *Config) *RedisValidator {
+	if config == nil {
+		config = &Config{
+			Variables: make(map[string]interface{}),
+		}
+	}
+
+	return &RedisValidator{
+		blockValidator: newBlockValidator(name, config),
+		spec:           spec,
+	}
+}
+
+// Verify validates a Redis reply and returns the saved variables
+func (v *RedisValidator) Verify(response interface{}) (map[string]interface{}, error) {
+	redisResp, ok := response.(*request.RedisResponse)
+	if !ok {
+		return nil, fmt.Errorf("expected *request.RedisResponse, got %T", response)
+	}
+
+	saved := make(map[string]interface{})
+	reply := redisResp.Reply
+
+	// An error reply only passes if an error is expected, and nothing else is checked
+	replyErr, isErr := reply.(resp.Error)
+	if v.spec.Error != nil {
+		if want, ok := v.formatOutput(util.BlockReply, util.MatchContains, *v.spec.Error); ok {
+			if !isErr {
+				v.addFailure(util.BlockReply, "", want, reply, util.MatchType,
+					fmt.Sprintf("reply: expected an error containing '%s', got '%v'", want, reply))
+			} else if !strings.Contains(string(replyErr), want) {
+				v.addFailure(util.BlockReply, "", want, string(replyErr), util.MatchContains,
+					fmt.Sprintf("reply: expected an error containing '%s', got '%s'", want, replyErr))
+			}
+		}
+	} else if isErr {
+		v.addFailure(util.BlockReply, "", v.spec.Reply, string(replyErr), util.MatchType,
+			fmt.Sprintf("reply: unexpected error reply '%s'", replyErr))
+	}
+	if isErr {
+		return saved, v.formatErrors()
+	}
+
+	if v.spec.Null && reply != nil {
+		v.addFailure(util.BlockReply, "", nil, reply, util.MatchEquals,
+			fmt.Sprintf("reply: expected null, got '%v'", reply))
+	}
+	if v.spec.Reply != nil {
+		v.validateReply(reply)
+	}
+
+	hasJSONSaves := v.spec.Save != nil && len(v.spec.Save.JSON) > 0
+	var payload interface{}
+	payloadOK := false
+	if v.spec.JSON != nil || hasJSONSaves {
+		if text, ok := reply.(string); ok {
+			payload, payloadOK = v.validateMessage(util.BlockReply, []byte(text), nil, v.spec.JSON, hasJSONSaves)
+		} else {
+			v.addFailure(util.BlockReply, "", v.spec.JSON, reply, util.MatchType,
+				fmt.Sprintf("reply: expected a string to parse as JSON, got %T", reply))
+		}
+	}
+
+	if v.spec.Save != nil {
+		v.saveReply(reply, v.spec.Save.Reply, saved)
+		if hasJSONSaves && payloadOK {
+			v.saveJSON("json", util.BlockReply, payload, v.spec.Save.JSON, saved)
+		}
+	}
+
+	if len(v.failures) > 0 {
+		return saved, v.formatErrors()
+	}
+
+	return saved, nil
+}
+
+// validateReply checks the reply against the expected value. A mapping is
+// compared with an array of field/value pairs, as HGETALL returns.
+func (v *RedisValidator) validateReply(reply interface{}) {
+	switch expected := v.spec.Reply.(type) {
+	case map[string]interface{}:
+		fields, ok := pairsToMap(reply)
+		if !ok {
+			v.addFailure(util.BlockReply, "", expected, reply, util.MatchType,
+				fmt.Sprintf("reply: expected field/value pairs, got '%v'", reply))
+			return
+		}
+		v.validateBlock(util.BlockReply, fields, expected)
+	default:
+		formatted, err := util.FormatKeys(expected, v.config.Variables)
+		if err != nil {
+			v.addFailure(util.BlockReply, "", expected, reply, util.MatchFormat,
+				fmt.Sprintf("reply: failed to format expected value: %v", err))
+			return
+		}
+		if list, ok := formatted.([]interface{}); ok {
+			v.validateList(util.BlockReply, reply, list)
+			return
+		}
+		v.validateScalar(reply, formatted)
+	}
+}
+
+// validateScalar compares a string, integer or null reply with an expected
+// value or type marker
+func (v *RedisValidator) validateScalar(reply, expected interface{}) {
+	switch expected {
+	case "<<ANYTHING>>":
+		return
+	case "<<STR>>":
+		if _, ok := reply.(string); !ok {
+			v.addFailure(util.BlockReply, "", expected, reply, util.MatchAnyStr,
+				fmt.Sprintf("reply: expected string type (from !anystr), got '%v' (type: %T)", reply, reply))
+		}
+		return
+	case "<<INT>>":
+		if _, ok := reply.(int64); !ok {
+			v.addFailure(util.BlockReply, "", expected, reply, util.MatchAnyInt,
+				fmt.Sprintf("reply: expected integer type (from !anyint), got '%v' (type: %T)", reply, reply))
+		}
+		return
+	}
+	if !compareValues(reply, expected) {
+		v.addFailure(util.BlockReply, "", expected, reply, util.MatchEquals,
+			fmt.Sprintf("reply: expected '%v' (type: %T), got '%v' (type: %T)", expected, expected, reply, reply))
+	}
+}
+
+// saveReply saves values at paths in the reply, or the whole reply for an empty path
+func (v *RedisValidator) saveReply(reply interface{}, paths map[string]string, saved map[string]interface{}) {
+	nested := make(map[string]string, len(paths))
+	for saveName, path := range paths {
+		if path == "" {
+			saved[saveName] = reply
+			continue
+		}
+		nested[saveName] = path
+	}
+	if len(nested) > 0 {
+		v.saveJSON(util.BlockReply, util.BlockReply, reply, nested, saved)
+	}
+}
+
+// pairsToMap converts an array of field/value pairs to a mapping
+func pairsToMap(reply interface{}) (map[string]interface{}, bool) {
+	items, ok := reply.([]interface{})
+	if !ok || len(items)%2 != 0 {
+		return nil, false
+	}
+	fields := make(map[string]interface{}, len(items)/2)
+	for i := 0; i < len(items); i += 2 {
+		field, ok := items[i].(string)
+		if !ok {
+			return nil, false
+		}
+		fields[field] = items[i+1]
+	}
+	return fields, true
+}
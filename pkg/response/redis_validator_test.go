@@ -0,0 +1,88 @@
+package response
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/request"
+	"github.com/systemquest/tavern-go/pkg/resp"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// TestRedisValidator_Replies tests strings, integers, arrays, hashes, null and error replies
+func TestRedisValidator_Replies(t *testing.T) {
+	config := &Config{Variables: map[string]interface{}{"user": "alice"}}
+	tests := map[string]struct {
+		spec  schema.RedisSpec
+		reply interface{}
+	}{
+		"string":  {schema.RedisSpec{Reply: "{user}"}, "alice"},
+		"integer": {schema.RedisSpec{Reply: 3}, int64(3)},
+		"marker":  {schema.RedisSpec{Reply: "<<INT>>"}, int64(3)},
+		"array":   {schema.RedisSpec{Reply: []interface{}{"{user}", "<<STR>>"}}, []interface{}{"alice", "bob"}},
+		"hash": {
+			schema.RedisSpec{Reply: map[string]interface{}{"name": "{user}"}},
+			[]interface{}{"name", "alice", "plan", "pro"},
+		},
+		"null":  {schema.RedisSpec{Null: true}, nil},
+		"error": {schema.RedisSpec{Error: strPtr("WRONGTYPE")}, resp.Error("WRONGTYPE Operation against a key holding the wrong kind of value")},
+		"json":  {schema.RedisSpec{JSON: map[string]interface{}{"user": "{user}"}}, `{"user": "alice", "cart": 2}`},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := NewRedisValidator("test", tt.spec, config).Verify(&request.RedisResponse{Reply: tt.reply})
+			assert.NoError(t, err)
+		})
+	}
+}
+
+// TestRedisValidator_Save tests saving the whole reply, an array item and a JSON path
+func TestRedisValidator_Save(t *testing.T) {
+	spec := schema.RedisSpec{
+		Save: &schema.RedisSaveSpec{
+			Reply: map[string]string{"session": "", "user": "0"},
+			JSON:  map[string]string{"cart": "cart"},
+		},
+	}
+	reply := []interface{}{`{"cart": 2}`, int64(7)}
+
+	saved, err := NewRedisValidator("test", spec, nil).Verify(&request.RedisResponse{Reply: reply})
+	require.Error(t, err) // An array is not JSON
+	assert.Equal(t, map[string]interface{}{"session": reply, "user": `{"cart": 2}`}, saved)
+
+	saved, err = NewRedisValidator("test", spec, nil).Verify(&request.RedisResponse{Reply: `{"cart": 2}`})
+	require.Error(t, err) // A string has no item 0
+	assert.Equal(t, float64(2), saved["cart"])
+}
+
+// TestRedisValidator_Failures tests typed failures on the reply block
+func TestRedisValidator_Failures(t *testing.T) {
+	validator := NewRedisValidator("test", schema.RedisSpec{Reply: "1"}, nil)
+	_, err := validator.Verify(&request.RedisResponse{Reply: int64(1)})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reply: expected '1' (type: string), got '1' (type: int64)")
+	assert.Equal(t, util.MatchEquals, findFailure(validator.failures, util.BlockReply, "").Matcher)
+
+	validator = NewRedisValidator("test", schema.RedisSpec{Reply: "OK"}, nil)
+	_, err = validator.Verify(&request.RedisResponse{Reply: resp.Error("ERR unknown command 'NOPE'")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reply: unexpected error reply 'ERR unknown command 'NOPE''")
+
+	validator = NewRedisValidator("test", schema.RedisSpec{Null: true, Reply: []interface{}{"a"}}, nil)
+	_, err = validator.Verify(&request.RedisResponse{Reply: []interface{}{"a", "b"}})
+	require.Error(t, err)
+	require.Len(t, validator.failures, 2)
+	assert.Equal(t, util.MatchLength, validator.failures[1].Matcher)
+
+	validator = NewRedisValidator("test", schema.RedisSpec{Reply: map[string]interface{}{"name": "alice"}}, nil)
+	_, err = validator.Verify(&request.RedisResponse{Reply: []interface{}{"name"}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reply: expected field/value pairs")
+
+	validator = NewRedisValidator("test", schema.RedisSpec{Error: strPtr("WRONGTYPE")}, nil)
+	_, err = validator.Verify(&request.RedisResponse{Reply: "OK"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reply: expected an error containing 'WRONGTYPE', got 'OK'")
+}
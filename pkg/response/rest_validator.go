@@ -5,14 +5,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"net/url"
-	"strconv"
 	"strings"
 
 	"github.com/sirupsen/logrus"
 	"github.com/systemquest/tavern-go/pkg/extension"
+	"github.com/systemquest/tavern-go/pkg/openapi"
 	"github.com/systemquest/tavern-go/pkg/regex"
 	"github.com/systemquest/tavern-go/pkg/schema"
 	"github.com/systemquest/tavern-go/pkg/util"
@@ -20,18 +19,18 @@ import (
 
 // RestValidator validates REST API responses
 type RestValidator struct {
-	name     string
+	blockValidator
 	spec     schema.ResponseSpec
-	config   *Config
 	response *http.Response
-	errors   []string
-	logger   *logrus.Logger
+	stream   *eventStream // Events read from a text/event-stream body
 }
 
 // Config holds validator configuration
 type Config struct {
 	Variables map[string]interface{}
 	Strict    *schema.Strict // Response key matching strictness (aligned with tavern-py commit 3838566)
+	OpenAPI   *openapi.Spec  // Contract to validate responses against, if loaded
+	GraphQL   bool           // The request was a GraphQL operation, so errors in the result fail the stage
 }
 
 // NewRestValidator creates a new REST API response validator
@@ -55,11 +54,8 @@ func NewRestValidator(name string, spec schema.ResponseSpec, config *Config) *Re
 	}
 
 	return &RestValidator{
-		name:   name,
-		spec:   spec,
-		config: config,
-		errors: make([]string, 0),
-		logger: logrus.StandardLogger(),
+		blockValidator: newBlockValidator(name, config),
+		spec:           spec,
 	}
 }
 
@@ -134,11 +130,13 @@ func (v *RestValidator) checkStatusCode(statusCode int, expected *schema.StatusC
 				bodyStr = fmt.Sprintf("%v", body)
 			}
 		}
-		v.addError(fmt.Sprintf("status code mismatch: expected %s, got %d:\n%s",
-			expected.String(), statusCode, bodyStr))
+		v.addFailure(util.BlockStatus, "", expected, statusCode, util.MatchEquals,
+			fmt.Sprintf("status code mismatch: expected %s, got %d:\n%s",
+				expected.String(), statusCode, bodyStr))
 	} else {
-		v.addError(fmt.Sprintf("status code mismatch: expected %s, got %d",
-			expected.String(), statusCode))
+		v.addFailure(util.BlockStatus, "", expected, statusCode, util.MatchEquals,
+			fmt.Sprintf("status code mismatch: expected %s, got %d",
+				expected.String(), statusCode))
 	}
 }
 
@@ -147,10 +145,18 @@ func (v *RestValidator) Verify(resp *http.Response) (map[string]interface{}, err
 	v.response = resp
 	saved := make(map[string]interface{})
 
-	// Read response body first for logging
-	bodyBytes, err := io.ReadAll(resp.Body)
+	// Read response body first for logging. An event stream may never end,
+	// so it is read event by event until the events block says to stop.
+	var bodyBytes []byte
+	var err error
+	if v.spec.Events != nil || isEventStream(resp.Header) {
+		bodyBytes, err = v.readEvents(resp)
+	} else {
+		bodyBytes, err = io.ReadAll(resp.Body)
+	}
 	if err != nil {
-		v.addError(fmt.Sprintf("failed to read response body: %v", err))
+		v.addFailure(util.BlockBody, "", nil, nil, "",
+			fmt.Sprintf("failed to read response body: %v", err))
 		return nil, v.formatErrors()
 	}
 	_ = resp.Body.Close()
@@ -186,11 +192,20 @@ func (v *RestValidator) Verify(resp *http.Response) (map[string]interface{}, err
 		v.validateBlock("body", bodyData, v.spec.Body)
 	}
 
+	// Verify the GraphQL result
+	v.validateGraphQL(bodyData)
+
+	// Verify server-sent events
+	v.validateEvents(saved)
+
 	// Verify headers
 	if v.spec.Headers != nil {
 		v.validateHeaders(resp.Header, v.spec.Headers)
 	}
 
+	// Verify the response against the OpenAPI contract
+	v.validateOpenAPI(resp, bodyBytes)
+
 	// Verify cookies (aligned with tavern-py)
 	if len(v.spec.Cookies) > 0 {
 		for _, cookieName := range v.spec.Cookies {
@@ -202,14 +217,15 @@ func (v *RestValidator) Verify(resp *http.Response) (map[string]interface{}, err
 				}
 			}
 			if !found {
-				v.addError(fmt.Sprintf("No cookie named '%s' in response", cookieName))
+				v.addFailure(util.BlockCookies, cookieName, cookieName, nil, util.MatchExists,
+					fmt.Sprintf("No cookie named '%s' in response", cookieName))
 			}
 		}
 	}
 
 	// Save values - SaveConfig can contain both extension and regular save
 	if v.spec.Save != nil {
-		// Handle regular save first (body, headers, redirect_query_params)
+		// Handle regular save first (body, headers, redirect_query_params, data)
 		if v.spec.Save.IsRegular() {
 			saveSpec := v.spec.Save.GetSpec()
 			if saveSpec != nil {
@@ -229,7 +245,8 @@ func (v *RestValidator) Verify(resp *http.Response) (map[string]interface{}, err
 						// Only unmarshal if we have string paths
 						err = json.Unmarshal(bodyBytes, &bodyData)
 						if err != nil {
-							v.addError(fmt.Sprintf("failed to parse body for saving: %v", err))
+							v.addFailure(util.BlockSave, "body", nil, nil, "",
+								fmt.Sprintf("failed to parse body for saving: %v", err))
 							bodyData = nil // Don't fail completely, just skip JSON extraction
 						}
 					}
@@ -241,7 +258,8 @@ func (v *RestValidator) Verify(resp *http.Response) (map[string]interface{}, err
 							if bodyData != nil {
 								val, err := v.extractValue(bodyData, jsonPath)
 								if err != nil {
-									v.addError(fmt.Sprintf("failed to save %s from body: %v", saveName, err))
+									v.addFailure(util.BlockSave, "body."+saveName, jsonPath, nil, util.MatchExists,
+										fmt.Sprintf("failed to save %s from body: %v", saveName, err))
 								} else {
 									saved[saveName] = val
 								}
@@ -270,7 +288,8 @@ func (v *RestValidator) Verify(resp *http.Response) (map[string]interface{}, err
 									// Execute the extension
 									extSaved, err := v.saveWithExtSpec(ext, tempResp)
 									if err != nil {
-										v.addError(fmt.Sprintf("failed to save %s with extension: %v", saveName, err))
+										v.addFailure(util.BlockSave, "body."+saveName, functionName, nil, util.MatchExtension,
+											fmt.Sprintf("failed to save %s with extension: %v", saveName, err))
 									} else {
 										// The extension returns {extensionName: {key: value, ...}}
 										// For validate_regex, it returns {"regex": {"token": "...", "callback_url": "..."}}
@@ -293,7 +312,8 @@ func (v *RestValidator) Verify(resp *http.Response) (map[string]interface{}, err
 					for saveName, headerName := range saveSpec.Headers {
 						val := resp.Header.Get(headerName)
 						if val == "" {
-							v.addError(fmt.Sprintf("header %s not found for saving as %s", headerName, saveName))
+							v.addFailure(util.BlockSave, "headers."+saveName, headerName, nil, util.MatchExists,
+								fmt.Sprintf("header %s not found for saving as %s", headerName, saveName))
 						} else {
 							saved[saveName] = val
 						}
@@ -304,17 +324,20 @@ func (v *RestValidator) Verify(resp *http.Response) (map[string]interface{}, err
 				if saveSpec.RedirectQueryParams != nil {
 					location := resp.Header.Get("Location")
 					if location == "" {
-						v.addError("no Location header for redirect_query_params")
+						v.addFailure(util.BlockSave, "redirect_query_params", "Location", nil, util.MatchExists,
+							"no Location header for redirect_query_params")
 					} else {
 						parsedURL, err := url.Parse(location)
 						if err != nil {
-							v.addError(fmt.Sprintf("failed to parse redirect URL: %v", err))
+							v.addFailure(util.BlockSave, "redirect_query_params", nil, location, "",
+								fmt.Sprintf("failed to parse redirect URL: %v", err))
 						} else {
 							queryParams := parsedURL.Query()
 							for saveName, paramName := range saveSpec.RedirectQueryParams {
 								val := queryParams.Get(paramName)
 								if val == "" {
-									v.addError(fmt.Sprintf("query param %s not found in redirect URL", paramName))
+									v.addFailure(util.BlockSave, "redirect_query_params."+saveName, paramName, nil, util.MatchExists,
+										fmt.Sprintf("query param %s not found in redirect URL", paramName))
 								} else {
 									saved[saveName] = val
 								}
@@ -322,6 +345,11 @@ func (v *RestValidator) Verify(resp *http.Response) (map[string]interface{}, err
 						}
 					}
 				}
+
+				// Save from GraphQL data
+				if saveSpec.Data != nil {
+					v.saveGraphQL(bodyData, saveSpec.Data, saved)
+				}
 			}
 		}
 
@@ -330,7 +358,8 @@ func (v *RestValidator) Verify(resp *http.Response) (map[string]interface{}, err
 			ext := v.spec.Save.GetExtension()
 			extSaved, err := v.saveWithExtSpec(ext, resp)
 			if err != nil {
-				v.addError(fmt.Sprintf("failed to save with extension: %v", err))
+				v.addFailure(util.BlockSave, "$ext", ext.Function, nil, util.MatchExtension,
+					fmt.Sprintf("failed to save with extension: %v", err))
 			} else {
 				// Top-level $ext returns {extensionName: {key: value, ...}}
 				// For validate_regex, it returns {"regex": {"token": "...", "url": "..."}}
@@ -343,7 +372,7 @@ func (v *RestValidator) Verify(resp *http.Response) (map[string]interface{}, err
 	}
 
 	// Check for errors
-	if len(v.errors) > 0 {
+	if len(v.failures) > 0 {
 		return nil, v.formatErrors()
 	}
 
@@ -356,359 +385,13 @@ func (v *RestValidator) saveWithExtSpec(ext *schema.ExtSpec, resp *http.Response
 	return executor.ExecuteSaver(ext, resp)
 }
 
-// validateBlock validates a block (body or headers)
-func (v *RestValidator) validateBlock(blockName string, actual interface{}, expected interface{}) {
-	// Check if expected is an array (support list validation like tavern-py)
-	if expectedList, ok := expected.([]interface{}); ok {
-		v.validateList(blockName, actual, expectedList)
-		return
-	}
-
-	expectedMap, ok := expected.(map[string]interface{})
-	if !ok {
-		return
-	}
-
-	// Determine block-level strictness (aligned with tavern-py commit 3838566)
-	// 'strict' could be a list, in which case we only want to enable strict
-	// key checking for that specific bit of the response
-	var blockStrictness bool
-	if v.config != nil && v.config.Strict != nil {
-		blockStrictness = v.config.Strict.ShouldCheckStrictly(blockName)
-		v.logger.Debugf("Strict key checking for %s: %v", blockName, blockStrictness)
-	}
-
-	// Handle $ext validation before processing other keys
-	if extSpec, hasExt := expectedMap["$ext"]; hasExt {
-		extMap, ok := extSpec.(map[string]interface{})
-		if ok {
-			functionName, _ := extMap["function"].(string)
-			extraKwargs, _ := extMap["extra_kwargs"].(map[string]interface{})
-
-			// For inline regex validation in validateBlock
-			if functionName == "tavern.testutils.helpers:validate_regex" {
-				expression, _ := extraKwargs["expression"].(string)
-				if expression != "" {
-					var dataStr string
-					switch actualData := actual.(type) {
-					case string:
-						dataStr = actualData
-					case []byte:
-						dataStr = string(actualData)
-					default:
-						// Convert to JSON string for matching
-						jsonBytes, jsonErr := json.Marshal(actualData)
-						if jsonErr == nil {
-							dataStr = string(jsonBytes)
-						}
-					}
-
-					// Use shared regex validator
-					_, err := regex.Validate(dataStr, expression)
-					if err != nil {
-						v.addError(fmt.Sprintf("%s: %v", blockName, err))
-					}
-				}
-			}
-		}
-	}
-
-	// Remove special keys
-	for key := range expectedMap {
-		if key == "$ext" {
-			delete(expectedMap, key)
-		}
-	}
-
-	if len(expectedMap) == 0 {
-		return
-	}
-
-	// Format expected values with variables
-	formattedExpected, err := util.FormatKeys(expectedMap, v.config.Variables)
-	if err != nil {
-		v.addError(fmt.Sprintf("failed to format %s: %v", blockName, err))
-		return
-	}
-
-	expectedMap, ok = formattedExpected.(map[string]interface{})
-	if !ok {
-		return
-	}
-
-	// Validate each key
-	for key, expectedVal := range expectedMap {
-		actualVal, err := v.extractValue(actual, key)
-		if err != nil {
-			v.addError(fmt.Sprintf("%s.%s: %v", blockName, key, err))
-			continue
-		}
-
-		// If expected value is nil, just check existence
-		if expectedVal == nil {
-			continue
-		}
-
-		// Check for !anything marker - accept any value
-		if expectedStr, ok := expectedVal.(string); ok && expectedStr == "<<ANYTHING>>" {
-			v.logger.Debugf("Key %s.%s: actual value = '%v' - matches !anything", blockName, key, actualVal)
-			continue
-		}
-
-		// Check for type matchers (aligned with tavern-py commit 3ff6b3c)
-		if expectedStr, ok := expectedVal.(string); ok {
-			// Check for !anybool matcher
-			if expectedStr == "<<BOOL>>" {
-				if _, ok := actualVal.(bool); !ok {
-					v.addError(fmt.Sprintf("%s.%s: expected boolean type (from !anybool), got '%v' (type: %T)",
-						blockName, key, actualVal, actualVal))
-				} else {
-					v.logger.Debugf("%s.%s: actual value = '%v' - matches !anybool", blockName, key, actualVal)
-				}
-				continue
-			}
-			// Check for !anyint matcher
-			if expectedStr == "<<INT>>" || strings.HasPrefix(expectedStr, "<<INT>>") {
-				switch val := actualVal.(type) {
-				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-					v.logger.Debugf("%s.%s: actual value = '%v' - matches !anyint", blockName, key, actualVal)
-				case float64:
-					if val == float64(int64(val)) {
-						v.logger.Debugf("%s.%s: actual value = '%v' - matches !anyint", blockName, key, actualVal)
-					} else {
-						v.addError(fmt.Sprintf("%s.%s: expected integer type (from !anyint), got '%v' (type: %T with decimal part)",
-							blockName, key, actualVal, actualVal))
-					}
-				default:
-					v.addError(fmt.Sprintf("%s.%s: expected integer type (from !anyint), got '%v' (type: %T)",
-						blockName, key, actualVal, actualVal))
-				}
-				continue
-			}
-			// Check for !anyfloat matcher
-			if expectedStr == "<<FLOAT>>" || strings.HasPrefix(expectedStr, "<<FLOAT>>") {
-				switch actualVal.(type) {
-				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
-					v.logger.Debugf("%s.%s: actual value = '%v' - matches !anyfloat", blockName, key, actualVal)
-				default:
-					v.addError(fmt.Sprintf("%s.%s: expected numeric type (from !anyfloat), got '%v' (type: %T)",
-						blockName, key, actualVal, actualVal))
-				}
-				continue
-			}
-			// Check for !anystr matcher
-			if expectedStr == "<<STR>>" || strings.HasPrefix(expectedStr, "<<STR>>") {
-				if _, ok := actualVal.(string); !ok {
-					v.addError(fmt.Sprintf("%s.%s: expected string type (from !anystr), got '%v' (type: %T)",
-						blockName, key, actualVal, actualVal))
-				} else {
-					v.logger.Debugf("%s.%s: actual value = '%v' - matches !anystr", blockName, key, actualVal)
-				}
-				continue
-			}
-			// Check for !approx matcher - approximate float comparison
-			// Aligned with tavern-py commit 53690cf: Feature/approx numbers (#101)
-			if strings.HasPrefix(expectedStr, "<<APPROX>>") {
-				expectedValueStr := strings.TrimPrefix(expectedStr, "<<APPROX>>")
-				expectedFloat, err := strconv.ParseFloat(expectedValueStr, 64)
-				if err != nil {
-					v.addError(fmt.Sprintf("%s.%s: invalid !approx value '%s': %v", blockName, key, expectedValueStr, err))
-					continue
-				}
-
-				// Convert actual value to float64
-				var actualFloat float64
-				switch val := actualVal.(type) {
-				case float64:
-					actualFloat = val
-				case float32:
-					actualFloat = float64(val)
-				case int:
-					actualFloat = float64(val)
-				case int64:
-					actualFloat = float64(val)
-				case int32:
-					actualFloat = float64(val)
-				default:
-					v.addError(fmt.Sprintf("%s.%s: expected numeric type for !approx, got '%v' (type: %T)",
-						blockName, key, actualVal, actualVal))
-					continue
-				}
-
-				// Use relative and absolute tolerance (similar to pytest.approx defaults)
-				// Default: rel_tol=1e-6, abs_tol=1e-12
-				relTol := 1e-6
-				absTol := 1e-12
-				tolerance := math.Max(relTol*math.Abs(expectedFloat), absTol)
-
-				if math.Abs(actualFloat-expectedFloat) <= tolerance {
-					v.logger.Debugf("%s.%s: actual value = '%v' approximately matches expected '%v' (tolerance: %e)",
-						blockName, key, actualFloat, expectedFloat, tolerance)
-				} else {
-					v.addError(fmt.Sprintf("%s.%s: expected approximately '%v', got '%v' (difference: %e, tolerance: %e)",
-						blockName, key, expectedFloat, actualFloat, math.Abs(actualFloat-expectedFloat), tolerance))
-				}
-				continue
-			}
-		}
-
-		// If expected is an array, use validateList for element-by-element comparison
-		if expectedList, ok := expectedVal.([]interface{}); ok {
-			v.validateList(fmt.Sprintf("%s.%s", blockName, key), actualVal, expectedList)
-			continue
-		}
-
-		// If expected is a map, recursively validate
-		if expectedMap, ok := expectedVal.(map[string]interface{}); ok {
-			v.validateBlock(fmt.Sprintf("%s.%s", blockName, key), actualVal, expectedMap)
-			continue
-		}
-
-		// Compare values with type conversion for numbers
-		if !compareValues(actualVal, expectedVal) {
-			v.addError(fmt.Sprintf("%s.%s: expected '%v' (type: %T), got '%v' (type: %T)",
-				blockName, key, expectedVal, expectedVal, actualVal, actualVal))
-		}
-	}
-
-	// Strict key checking (aligned with tavern-py commit 3838566)
-	// Check if there are extra keys in actual that are not in expected
-	actualMap, ok := actual.(map[string]interface{})
-	if ok {
-		expectedKeys := make(map[string]bool)
-		for key := range expectedMap {
-			expectedKeys[key] = true
-		}
-
-		var extraKeys []string
-		for key := range actualMap {
-			if !expectedKeys[key] {
-				extraKeys = append(extraKeys, key)
-			}
-		}
-
-		if len(extraKeys) > 0 {
-			if blockStrictness {
-				// In strict mode, extra keys are an error
-				v.addError(fmt.Sprintf("%s: extra keys in response (strict mode): %v", blockName, extraKeys))
-			} else if v.config != nil && v.config.Strict != nil && !v.config.Strict.IsLegacy {
-				// If strict is explicitly set to false (not legacy), log a warning
-				// This aligns with tavern-py's behavior in check_keys_match_recursive
-				v.logger.Warnf("%s: extra keys in response: %v", blockName, extraKeys)
-			}
-			// In legacy mode (default), extra keys at top level are silently ignored
-		}
-	}
-}
-
-// validateList validates array responses (aligned with tavern-py commit 95ae722)
-func (v *RestValidator) validateList(blockName string, actual interface{}, expected []interface{}) {
-	// Type check: actual must be an array
-	actualList, ok := actual.([]interface{})
-	if !ok {
-		v.addError(fmt.Sprintf("%s: expected array, got %T", blockName, actual))
-		return
-	}
-
-	// Strict length check (aligned with tavern-py commit 95ae722)
-	// tavern-py requires exact length match for lists
-	if len(expected) != len(actualList) {
-		v.addError(fmt.Sprintf(
-			"%s: length of returned list was different than expected - expected %d items, got %d",
-			blockName, len(expected), len(actualList)))
-		return
-	}
-
-	// Validate each expected element
-	for idx, expectedVal := range expected {
-
-		actualVal := actualList[idx]
-		indexName := fmt.Sprintf("%s[%d]", blockName, idx)
-
-		// Handle nested structures recursively
-		switch exp := expectedVal.(type) {
-		case map[string]interface{}:
-			// Nested object: use validateBlock
-			v.validateBlock(indexName, actualVal, exp)
-		case []interface{}:
-			// Nested array: recursive call
-			v.validateList(indexName, actualVal, exp)
-		case string:
-			// Check for type markers
-			if exp == "<<ANYTHING>>" {
-				v.logger.Debugf("%s: actual value = '%v' - matches !anything", indexName, actualVal)
-				continue
-			}
-			if exp == "<<STR>>" || strings.HasPrefix(exp, "<<STR>>") {
-				// Check if actual value is a string
-				if _, ok := actualVal.(string); !ok {
-					v.addError(fmt.Sprintf("%s: expected string type (from !anystr), got '%v' (type: %T)",
-						indexName, actualVal, actualVal))
-				} else {
-					v.logger.Debugf("%s: actual value = '%v' - matches !anystr", indexName, actualVal)
-				}
-				continue
-			}
-			if exp == "<<INT>>" || strings.HasPrefix(exp, "<<INT>>") {
-				// Check if actual value is an integer (in JSON it could be float64 without decimal part)
-				switch val := actualVal.(type) {
-				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
-					v.logger.Debugf("%s: actual value = '%v' - matches !anyint", indexName, actualVal)
-				case float64:
-					if val == float64(int64(val)) {
-						v.logger.Debugf("%s: actual value = '%v' - matches !anyint", indexName, actualVal)
-					} else {
-						v.addError(fmt.Sprintf("%s: expected integer type (from !anyint), got '%v' (type: %T with decimal part)",
-							indexName, actualVal, actualVal))
-					}
-				default:
-					v.addError(fmt.Sprintf("%s: expected integer type (from !anyint), got '%v' (type: %T)",
-						indexName, actualVal, actualVal))
-				}
-				continue
-			}
-			if exp == "<<FLOAT>>" || strings.HasPrefix(exp, "<<FLOAT>>") {
-				// Check if actual value is a numeric type (float or int)
-				switch actualVal.(type) {
-				case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
-					v.logger.Debugf("%s: actual value = '%v' - matches !anyfloat", indexName, actualVal)
-				default:
-					v.addError(fmt.Sprintf("%s: expected numeric type (from !anyfloat), got '%v' (type: %T)",
-						indexName, actualVal, actualVal))
-				}
-				continue
-			}
-			if exp == "<<BOOL>>" {
-				// Check if actual value is a boolean (aligned with tavern-py commit 3ff6b3c)
-				if _, ok := actualVal.(bool); !ok {
-					v.addError(fmt.Sprintf("%s: expected boolean type (from !anybool), got '%v' (type: %T)",
-						indexName, actualVal, actualVal))
-				} else {
-					v.logger.Debugf("%s: actual value = '%v' - matches !anybool", indexName, actualVal)
-				}
-				continue
-			}
-			// Primitive value: direct comparison
-			if !compareValues(actualVal, exp) {
-				v.addError(fmt.Sprintf("%s: expected '%v' (type: %T), got '%v' (type: %T)",
-					indexName, exp, exp, actualVal, actualVal))
-			}
-		default:
-			// Primitive value: direct comparison
-			if !compareValues(actualVal, exp) {
-				v.addError(fmt.Sprintf("%s: expected '%v' (type: %T), got '%v' (type: %T)",
-					indexName, exp, exp, actualVal, actualVal))
-			}
-		}
-	}
-}
-
 // validateHeaders validates HTTP headers
 func (v *RestValidator) validateHeaders(actual http.Header, expected map[string]interface{}) {
 	// Format expected values
 	formattedExpected, err := util.FormatKeys(expected, v.config.Variables)
 	if err != nil {
-		v.addError(fmt.Sprintf("failed to format headers: %v", err))
+		v.addFailure(util.BlockHeaders, "", nil, nil, util.MatchFormat,
+			fmt.Sprintf("failed to format headers: %v", err))
 		return
 	}
 
@@ -733,12 +416,14 @@ func (v *RestValidator) validateHeaders(actual http.Header, expected map[string]
 					// Get the actual header value
 					actualVal := actual.Get(headerName)
 					if actualVal == "" {
-						v.addError(fmt.Sprintf("header %s not found for regex validation", headerName))
+						v.addFailure(util.BlockHeaders, headerName, expression, nil, util.MatchExists,
+							fmt.Sprintf("header %s not found for regex validation", headerName))
 					} else {
 						// Use shared regex validator
 						_, err := regex.Validate(actualVal, expression)
 						if err != nil {
-							v.addError(fmt.Sprintf("header %s regex validation failed: %v", headerName, err))
+							v.addFailure(util.BlockHeaders, headerName, expression, actualVal, util.MatchRegex,
+								fmt.Sprintf("header %s regex validation failed: %v", headerName, err))
 						}
 					}
 				}
@@ -760,40 +445,21 @@ func (v *RestValidator) validateHeaders(actual http.Header, expected map[string]
 		if expectedVal == nil {
 			// Just check existence
 			if actualVal == "" {
-				v.addError(fmt.Sprintf("header %s not found", key))
+				v.addFailure(util.BlockHeaders, key, nil, nil, util.MatchExists,
+					fmt.Sprintf("header %s not found", key))
 			}
 			continue
 		}
 
 		expectedStr := fmt.Sprintf("%v", expectedVal)
 		if actualVal != expectedStr {
-			v.addError(fmt.Sprintf("header %s: expected '%v' (type: %T), got '%v' (type: %T)",
-				key, expectedVal, expectedVal, actualVal, actualVal))
+			v.addFailure(util.BlockHeaders, key, expectedVal, actualVal, util.MatchEquals,
+				fmt.Sprintf("header %s: expected '%v' (type: %T), got '%v' (type: %T)",
+					key, expectedVal, expectedVal, actualVal, actualVal))
 		}
 	}
 }
 
-// extractValue extracts a value from data using dot notation
-func (v *RestValidator) extractValue(data interface{}, key string) (interface{}, error) {
-	// Always use manual traversal for consistent behavior
-	return util.RecurseAccessKey(data, key)
-} // addError adds an error message
-func (v *RestValidator) addError(msg string) {
-	v.errors = append(v.errors, msg)
-}
-
-// formatErrors formats all errors into a single error
-func (v *RestValidator) formatErrors() error {
-	if len(v.errors) == 0 {
-		return nil
-	}
-
-	return util.NewTestFailError(
-		fmt.Sprintf("test '%s' failed", v.name),
-		v.errors,
-	)
-}
-
 // GetResponse returns the validated response
 func (v *RestValidator) GetResponse() *http.Response {
 	return v.response
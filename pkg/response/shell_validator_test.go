@@ -0,0 +1,145 @@
+package response
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/request"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// strPtr returns a pointer to s
+func strPtr(s string) *string {
+	return &s
+}
+
+// TestShellValidator_Output tests the default exit code, output matchers and saves
+func TestShellValidator_Output(t *testing.T) {
+	resp := &request.ShellResponse{
+		Stdout: "created item id=1234 at 2024-01-02\n",
+		Stderr: "warning: config not found\n",
+	}
+	spec := schema.CommandResponseSpec{
+		Stdout: &schema.OutputSpec{
+			Equals:      strPtr("created item id={id} at 2024-01-02"),
+			Contains:    strPtr("id={id}"),
+			NotContains: strPtr("error"),
+			Matches:     strPtr(`id=\d{4}`),
+		},
+		Stderr: &schema.OutputSpec{Contains: strPtr("warning")},
+		Save: &schema.CommandSaveSpec{
+			Stdout: map[string]string{"item_id": `id=(\d+)`, "line": `created.*`},
+			Stderr: map[string]string{"level": `^(\w+):`},
+		},
+	}
+	config := &Config{Variables: map[string]interface{}{"id": 1234}}
+
+	saved, err := NewShellValidator("test", spec, config).Verify(resp)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"item_id": "1234",
+		"line":    "created item id=1234 at 2024-01-02",
+		"level":   "warning",
+	}, saved)
+
+	resp.ExitCode = 2
+	_, err = NewShellValidator("test", schema.CommandResponseSpec{}, nil).Verify(resp)
+	assert.ErrorContains(t, err, "exit code mismatch: expected 0, got 2: warning: config not found")
+
+	spec = schema.CommandResponseSpec{ExitCode: &schema.StatusCode{Multiple: []int{1, 2}}}
+	_, err = NewShellValidator("test", spec, nil).Verify(resp)
+	require.NoError(t, err)
+}
+
+// TestShellValidator_Failures tests typed failures for exit codes, output and saves
+func TestShellValidator_Failures(t *testing.T) {
+	resp := &request.ShellResponse{ExitCode: 1, Stdout: "error: not found\n"}
+	spec := schema.CommandResponseSpec{
+		ExitCode: &schema.StatusCode{Single: 0},
+		Stdout: &schema.OutputSpec{
+			Equals:      strPtr("ok"),
+			NotContains: strPtr("error"),
+			Matches:     strPtr("("),
+		},
+		Stderr: &schema.OutputSpec{Contains: strPtr("{missing}")},
+		Save:   &schema.CommandSaveSpec{Stdout: map[string]string{"item_id": `id=(\d+)`}},
+	}
+	validator := NewShellValidator("test", spec, nil)
+	saved, err := validator.Verify(resp)
+	require.Error(t, err)
+	assert.Empty(t, saved)
+
+	failures := validator.failures
+	require.Len(t, failures, 6)
+	assert.Equal(t, 1, findFailure(failures, util.BlockExitCode, "").Actual)
+	assert.Equal(t, util.MatchEquals, failures[1].Matcher)
+	assert.Equal(t, util.MatchNotContains, failures[2].Matcher)
+	assert.Contains(t, failures[3].Message, "stdout: invalid regex '('")
+	assert.Equal(t, util.MatchFormat, findFailure(failures, util.BlockStderr, "").Matcher)
+	assert.Contains(t, findFailure(failures, util.BlockSave, "stdout.item_id").Message, `regex 'id=(\d+)' did not match`)
+}
+
+// TestShellValidator_JSON tests json assertions with type matchers and !approx, and saving by path
+func TestShellValidator_JSON(t *testing.T) {
+	resp := &request.ShellResponse{
+		Stdout: `{"name": "api", "replicas": 3, "load": 0.4999999, "status": {"ready": true, "pods": ["api-1", "api-2"]}}` + "\n",
+	}
+	spec := schema.CommandResponseSpec{
+		Stdout: &schema.OutputSpec{
+			Contains: strPtr(`"name": "{name}"`),
+			JSON: map[string]interface{}{
+				"name":     "{name}",
+				"replicas": "<<INT>>",
+				"load":     "<<APPROX>>0.5",
+				"status":   map[string]interface{}{"ready": true},
+			},
+		},
+		Save: &schema.CommandSaveSpec{JSON: map[string]string{
+			"first_pod": "status.pods.0",
+			"replicas":  "replicas",
+		}},
+	}
+	config := &Config{Variables: map[string]interface{}{"name": "api"}}
+
+	saved, err := NewShellValidator("test", spec, config).Verify(resp)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"first_pod": "api-1", "replicas": float64(3)}, saved)
+
+	// Saving by path needs no json assertion; scalar output is compared as JSON
+	spec = schema.CommandResponseSpec{Save: &schema.CommandSaveSpec{JSON: map[string]string{"name": "name"}}}
+	saved, err = NewShellValidator("test", spec, nil).Verify(resp)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"name": "api"}, saved)
+
+	spec = schema.CommandResponseSpec{Stdout: &schema.OutputSpec{JSON: 42}}
+	_, err = NewShellValidator("test", spec, nil).Verify(&request.ShellResponse{Stdout: "42\n"})
+	require.NoError(t, err)
+}
+
+// TestShellValidator_JSONFailures tests strict mode, mismatches and output that is not JSON
+func TestShellValidator_JSONFailures(t *testing.T) {
+	resp := &request.ShellResponse{Stdout: `{"name": "api", "replicas": 2}`}
+	spec := schema.CommandResponseSpec{
+		Stdout: &schema.OutputSpec{JSON: map[string]interface{}{"replicas": 3}},
+	}
+	config := &Config{Strict: schema.NewStrictFromList([]string{"stdout"})}
+	validator := NewShellValidator("test", spec, config)
+	_, err := validator.Verify(resp)
+	require.Error(t, err)
+	assert.Equal(t, float64(2), findFailure(validator.failures, util.BlockStdout, "replicas").Actual)
+	assert.Equal(t, util.MatchStrict, findFailure(validator.failures, util.BlockStdout, "").Matcher)
+
+	spec = schema.CommandResponseSpec{
+		Stdout: &schema.OutputSpec{JSON: map[string]interface{}{"name": "api"}},
+		Save:   &schema.CommandSaveSpec{JSON: map[string]string{"name": "name"}},
+	}
+	validator = NewShellValidator("test", spec, nil)
+	saved, err := validator.Verify(&request.ShellResponse{Stdout: "name: api\n"})
+	require.Error(t, err)
+	assert.Empty(t, saved)
+	require.Len(t, validator.failures, 1)
+	assert.Equal(t, util.MatchType, validator.failures[0].Matcher)
+	assert.Contains(t, validator.failures[0].Message, "stdout is not JSON")
+}
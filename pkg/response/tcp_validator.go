@@ -0,0 +1,54 @@
+package response
+
+import (
+	"fmt"
+
+	"github.com/systemquest/tavern-go/pkg/request"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// TCPValidator validates the reply of a TCP stage
+type TCPValidator struct {
+	blockValidator
+	spec schema.TCPSpec
+}
+
+// NewTCPValidator creates a new TCP reply validator
+func NewTCPValidator(name string, spec schema.TCPSpec, config *Config) *TCPValidator {
+	if config == nil {
+		config = &Config{
+			Variables: make(map[string]interface{}),
+		}
+	}
+
+	return &TCPValidator{
+		blockValidator: newBlockValidator(name, config),
+		spec:           spec,
+	}
+}
+
+// Verify validates a TCP reply and returns the saved variables
+func (v *TCPValidator) Verify(response interface{}) (map[string]interface{}, error) {
+	resp, ok := response.(*request.TCPResponse)
+	if !ok {
+		return nil, fmt.Errorf("expected *request.TCPResponse, got %T", response)
+	}
+
+	saved := make(map[string]interface{})
+	hasJSONSaves := v.spec.Save != nil && len(v.spec.Save.JSON) > 0
+	reply, replyIsJSON := v.validateOutput(util.BlockReply, resp.Reply, v.spec.Reply, hasJSONSaves)
+
+	if v.spec.Save != nil {
+		v.saveOutput(util.BlockReply, resp.Reply, v.spec.Save.Reply, saved)
+		if hasJSONSaves && replyIsJSON {
+			v.saveJSON("json", util.BlockReply, reply, v.spec.Save.JSON, saved)
+		}
+	}
+
+	if len(v.failures) > 0 {
+		return saved, v.formatErrors()
+	}
+
+	return saved, nil
+}
@@ -0,0 +1,51 @@
+package response
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/request"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// TestTCPValidator_Reply tests reply matchers, and saving with a regex and a JSON path
+func TestTCPValidator_Reply(t *testing.T) {
+	spec := schema.TCPSpec{
+		Reply: &schema.OutputSpec{
+			Contains: strPtr(`"user": "{user}"`),
+			Matches:  strPtr(`^\{"session"`),
+			JSON:     map[string]interface{}{"user": "{user}", "ttl": "<<INT>>"},
+		},
+		Save: &schema.TCPSaveSpec{
+			Reply: map[string]string{"session": `"session": "(\w+)"`},
+			JSON:  map[string]string{"ttl": "ttl"},
+		},
+	}
+	config := &Config{Variables: map[string]interface{}{"user": "alice"}}
+	resp := &request.TCPResponse{Reply: `{"session": "s42", "user": "alice", "ttl": 300}`}
+
+	saved, err := NewTCPValidator("test", spec, config).Verify(resp)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"session": "s42", "ttl": float64(300)}, saved)
+
+	_, err = NewTCPValidator("test", schema.TCPSpec{}, nil).Verify(&request.TCPResponse{Reply: "anything"})
+	require.NoError(t, err)
+}
+
+// TestTCPValidator_Failures tests typed failures on the reply block
+func TestTCPValidator_Failures(t *testing.T) {
+	spec := schema.TCPSpec{
+		Reply: &schema.OutputSpec{Equals: strPtr("+OK")},
+		Save:  &schema.TCPSaveSpec{Reply: map[string]string{"id": `id=(\d+)`}},
+	}
+	validator := NewTCPValidator("test", spec, nil)
+	_, err := validator.Verify(&request.TCPResponse{Reply: "-ERR unknown command\r\n"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "reply: expected '+OK', got '-ERR unknown command'")
+
+	require.Len(t, validator.failures, 2)
+	assert.Equal(t, util.MatchEquals, findFailure(validator.failures, util.BlockReply, "").Matcher)
+	assert.NotNil(t, findFailure(validator.failures, util.BlockSave, "reply.id"))
+}
@@ -0,0 +1,150 @@
+package response
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/systemquest/tavern-go/pkg/request"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// Websocket match modes
+const (
+	matchOrdered    = "ordered"    // The next messages must match the expected ones in order
+	matchEventually = "eventually" // Each expected message must arrive before the timeout, in any order
+)
+
+// messageReceiver is the receiving side of a websocket connection
+type messageReceiver interface {
+	Receive(deadline time.Time) (request.WebSocketMessage, error)
+}
+
+// WebSocketValidator validates messages received on a websocket connection
+type WebSocketValidator struct {
+	blockValidator
+	spec schema.WebSocketSpec
+}
+
+// NewWebSocketValidator creates a new websocket message validator
+func NewWebSocketValidator(name string, spec schema.WebSocketSpec, config *Config) *WebSocketValidator {
+	if config == nil {
+		config = &Config{
+			Variables: make(map[string]interface{}),
+		}
+	}
+
+	return &WebSocketValidator{
+		blockValidator: newBlockValidator(name, config),
+		spec:           spec,
+	}
+}
+
+// Verify receives messages from a *request.WebSocketClient until the expected
+// messages have arrived or the timeout expires, and returns the saved variables
+func (v *WebSocketValidator) Verify(response interface{}) (map[string]interface{}, error) {
+	receiver, ok := response.(messageReceiver)
+	if !ok {
+		return nil, fmt.Errorf("expected *request.WebSocketClient, got %T", response)
+	}
+
+	timeout := 5 * time.Second
+	if v.spec.Timeout > 0 {
+		timeout = time.Duration(v.spec.Timeout * float64(time.Second))
+	}
+	deadline := time.Now().Add(timeout)
+	saved := make(map[string]interface{})
+
+	switch v.spec.Match {
+	case "", matchOrdered:
+		v.receiveOrdered(receiver, deadline, timeout, saved)
+	case matchEventually:
+		v.receiveEventually(receiver, deadline, timeout, saved)
+	default:
+		return nil, fmt.Errorf("invalid websocket match %q (expected %s or %s)", v.spec.Match, matchOrdered, matchEventually)
+	}
+
+	if len(v.failures) > 0 {
+		return nil, v.formatErrors()
+	}
+	return saved, nil
+}
+
+// receiveOrdered checks that the next messages match the expected ones in order,
+// stopping at the first mismatch
+func (v *WebSocketValidator) receiveOrdered(receiver messageReceiver, deadline time.Time, timeout time.Duration, saved map[string]interface{}) {
+	for i, expected := range v.spec.Expect {
+		path := fmt.Sprintf("%s[%d]", util.BlockMessages, i)
+		msg, err := receiver.Receive(deadline)
+		if err != nil {
+			v.addPathFailure(util.MatchExists, path, expectedPayload(expected), nil, receiveError(err, i, timeout))
+			return
+		}
+
+		failures := v.match(path, expected, msg, saved)
+		if len(failures) > 0 {
+			v.failures = append(v.failures, failures...)
+			return
+		}
+	}
+}
+
+// receiveEventually receives messages until every expected message has been
+// matched, skipping messages that match none of them
+func (v *WebSocketValidator) receiveEventually(receiver messageReceiver, deadline time.Time, timeout time.Duration, saved map[string]interface{}) {
+	pending := make([]int, len(v.spec.Expect))
+	for i := range pending {
+		pending[i] = i
+	}
+	var received []interface{}
+
+	for len(pending) > 0 {
+		msg, err := receiver.Receive(deadline)
+		if err != nil {
+			for _, i := range pending {
+				path := fmt.Sprintf("%s[%d]", util.BlockMessages, i)
+				v.addPathFailure(util.MatchExists, path, expectedPayload(v.spec.Expect[i]), received, receiveError(err, i, timeout))
+			}
+			return
+		}
+		received = append(received, decodePayload(msg.Data))
+
+		for n, i := range pending {
+			path := fmt.Sprintf("%s[%d]", util.BlockMessages, i)
+			if failures := v.match(path, v.spec.Expect[i], msg, saved); len(failures) == 0 {
+				pending = append(pending[:n], pending[n+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// match checks a received message against an expected one and saves its values.
+// It returns the failures instead of recording them, so unmatched messages can be skipped.
+func (v *WebSocketValidator) match(path string, expected schema.WebSocketExpectedMessage, msg request.WebSocketMessage, saved map[string]interface{}) []util.AssertionFailure {
+	check := newBlockValidator(v.name, v.config)
+	hasSaves := expected.Save != nil && len(expected.Save.JSON) > 0
+
+	payload, ok := check.validateMessage(path, msg.Data, expected.Text, expected.JSON, hasSaves)
+	if ok && len(check.failures) == 0 && hasSaves {
+		check.saveJSON("json", path, payload, expected.Save.JSON, saved)
+	}
+	return check.failures
+}
+
+// receiveError describes why expected message i was not received
+func receiveError(err error, i int, timeout time.Duration) string {
+	if errors.Is(err, request.ErrWebSocketTimeout) {
+		return fmt.Sprintf("expected message %d not received within %s", i, timeout)
+	}
+	return fmt.Sprintf("expected message %d not received: %v", i, err)
+}
+
+// expectedPayload returns the expected text or JSON of a message for failure reports
+func expectedPayload(expected schema.WebSocketExpectedMessage) interface{} {
+	if expected.Text != nil {
+		return *expected.Text
+	}
+	return expected.JSON
+}
@@ -0,0 +1,130 @@
+package response
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/request"
+	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
+)
+
+// fakeReceiver returns queued messages, then times out
+type fakeReceiver struct {
+	messages []string
+}
+
+func (f *fakeReceiver) Receive(time.Time) (request.WebSocketMessage, error) {
+	if len(f.messages) == 0 {
+		return request.WebSocketMessage{}, request.ErrWebSocketTimeout
+	}
+	msg := f.messages[0]
+	f.messages = f.messages[1:]
+	return request.WebSocketMessage{Data: []byte(msg)}, nil
+}
+
+// notifications is a stream of messages from a notifications API
+func notifications() *fakeReceiver {
+	return &fakeReceiver{messages: []string{
+		`{"type": "subscribed", "channel": "orders"}`,
+		`{"type": "heartbeat"}`,
+		`{"type": "order_created", "order": {"id": "o-1", "total": 12.5}}`,
+		`pong`,
+	}}
+}
+
+// TestWebSocketValidator_Ordered tests that the next messages must match in order
+func TestWebSocketValidator_Ordered(t *testing.T) {
+	pong := "pong"
+	spec := schema.WebSocketSpec{Expect: []schema.WebSocketExpectedMessage{
+		{JSON: map[string]interface{}{"type": "subscribed", "channel": "{channel}"}},
+		{JSON: map[string]interface{}{"type": "heartbeat"}},
+		{
+			JSON: map[string]interface{}{"type": "order_created"},
+			Save: &schema.WebSocketSaveSpec{JSON: map[string]string{"order_id": "order.id"}},
+		},
+		{Text: &pong},
+	}}
+	config := &Config{Variables: map[string]interface{}{"channel": "orders"}}
+
+	saved, err := NewWebSocketValidator("test", spec, config).Verify(notifications())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"order_id": "o-1"}, saved)
+
+	// Skipping the heartbeat breaks the order
+	spec.Expect = append(spec.Expect[:1], spec.Expect[2:]...)
+	validator := NewWebSocketValidator("test", spec, config)
+	_, err = validator.Verify(notifications())
+	require.Error(t, err)
+	require.Len(t, validator.failures, 1)
+	f := findFailure(validator.failures, util.BlockMessages, "[1].type")
+	require.NotNil(t, f)
+	assert.Equal(t, "heartbeat", f.Actual)
+}
+
+// TestWebSocketValidator_Eventually tests matching in any order while skipping other messages
+func TestWebSocketValidator_Eventually(t *testing.T) {
+	pong := "pong"
+	spec := schema.WebSocketSpec{
+		Match: "eventually",
+		Expect: []schema.WebSocketExpectedMessage{
+			{Text: &pong},
+			{
+				JSON: map[string]interface{}{"type": "order_created", "order": map[string]interface{}{"total": 12.5}},
+				Save: &schema.WebSocketSaveSpec{JSON: map[string]string{"order_id": "order.id"}},
+			},
+		},
+	}
+
+	saved, err := NewWebSocketValidator("test", spec, nil).Verify(notifications())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"order_id": "o-1"}, saved)
+
+	spec.Expect = append(spec.Expect, schema.WebSocketExpectedMessage{
+		JSON: map[string]interface{}{"type": "order_cancelled"},
+	})
+	spec.Timeout = 0.5
+	validator := NewWebSocketValidator("test", spec, nil)
+	_, err = validator.Verify(notifications())
+	require.Error(t, err)
+	require.Len(t, validator.failures, 1)
+	f := findFailure(validator.failures, util.BlockMessages, "[2]")
+	require.NotNil(t, f)
+	assert.Equal(t, util.MatchExists, f.Matcher)
+	assert.Contains(t, f.Message, "not received within 500ms")
+	assert.Len(t, f.Actual, 4)
+}
+
+// TestWebSocketValidator_Failures tests typed failures for text, JSON and saves
+func TestWebSocketValidator_Failures(t *testing.T) {
+	ping := "ping"
+	tests := map[string]struct {
+		expected schema.WebSocketExpectedMessage
+		block    string
+		path     string
+	}{
+		"text":     {schema.WebSocketExpectedMessage{Text: &ping}, util.BlockMessages, "[0]"},
+		"not json": {schema.WebSocketExpectedMessage{JSON: map[string]interface{}{"type": "x"}}, util.BlockMessages, "[0]"},
+		"save": {schema.WebSocketExpectedMessage{
+			Save: &schema.WebSocketSaveSpec{JSON: map[string]string{"id": "missing"}},
+		}, util.BlockSave, "json.id"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			receiver := &fakeReceiver{messages: []string{`pong`}}
+			if name == "save" {
+				receiver.messages = []string{`{"type": "pong"}`}
+			}
+			spec := schema.WebSocketSpec{Expect: []schema.WebSocketExpectedMessage{tt.expected}}
+			validator := NewWebSocketValidator("test", spec, nil)
+			_, err := validator.Verify(receiver)
+			require.Error(t, err)
+			assert.NotNil(t, findFailure(validator.failures, tt.block, tt.path), validator.failures)
+		})
+	}
+
+	_, err := NewWebSocketValidator("test", schema.WebSocketSpec{Match: "sometimes"}, nil).Verify(&fakeReceiver{})
+	assert.ErrorContains(t, err, `invalid websocket match "sometimes"`)
+}
@@ -79,10 +79,10 @@ func (sc *SaveConfig) UnmarshalYAML(node *yaml.Node) error {
 		delete(mapData, "$ext")
 	}
 
-	// Check if there are any regular save fields (body, headers, redirect_query_params)
+	// Check if there are any regular save fields (body, headers, redirect_query_params, data)
 	hasRegularFields := false
 	for key := range mapData {
-		if key == "body" || key == "headers" || key == "redirect_query_params" {
+		if key == "body" || key == "headers" || key == "redirect_query_params" || key == "data" {
 			hasRegularFields = true
 			break
 		}
@@ -133,6 +133,9 @@ func (sc *SaveConfig) MarshalYAML() (interface{}, error) {
 		if spec.RedirectQueryParams != nil {
 			result["redirect_query_params"] = spec.RedirectQueryParams
 		}
+		if spec.Data != nil {
+			result["data"] = spec.Data
+		}
 	}
 
 	// Return nil if both are empty
@@ -215,6 +218,11 @@ func NewSaveConfigFromInterface(data interface{}) (*SaveConfig, error) {
 		hasRegularFields = true
 	}
 
+	if dataPaths, ok := mapData["data"]; ok {
+		spec.Data = convertToStringMap(dataPaths)
+		hasRegularFields = true
+	}
+
 	if hasRegularFields {
 		config.spec = spec
 	}
@@ -57,11 +57,14 @@ func (s *Strict) Validate() error {
 			"body":                  true,
 			"headers":               true,
 			"redirect_query_params": true,
+			"data":                  true,
+			"stdout":                true,
+			"stderr":                true,
 		}
 
 		for _, part := range s.AsList {
 			if !validParts[part] {
-				return fmt.Errorf("invalid strict value: %s (must be one of: body, headers, redirect_query_params)", part)
+				return fmt.Errorf("invalid strict value: %s (must be one of: body, headers, redirect_query_params, data, stdout, stderr)", part)
 			}
 		}
 		return nil
@@ -95,7 +98,7 @@ func (s *Strict) MarshalYAML() (interface{}, error) {
 }
 
 // ShouldCheckStrictly returns whether strict checking should be applied for a given response part
-// blockName is one of: "body", "headers", "redirect_query_params"
+// blockName is one of: "body", "headers", "redirect_query_params", "data", "stdout", "stderr"
 func (s *Strict) ShouldCheckStrictly(blockName string) bool {
 	if s == nil || !s.IsSet || s.IsLegacy {
 		// Legacy behavior: strict for nested keys, lenient for top-level keys
@@ -10,14 +10,15 @@ import (
 // TestSpec represents a complete test specification
 type TestSpec struct {
 	TestName string    `yaml:"test_name" json:"test_name"`
+	Marks    []string  `yaml:"marks,omitempty" json:"marks,omitempty"` // Labels for selecting tests, e.g. "smoke"
 	Includes []Include `yaml:"includes,omitempty" json:"includes,omitempty"`
 	Stages   []Stage   `yaml:"stages" json:"stages"`
 	Strict   *Strict   `yaml:"strict,omitempty" json:"strict,omitempty"` // Response key matching strictness
 	Xfail    string    `yaml:"_xfail,omitempty" json:"_xfail,omitempty"` // Expected failure mode: "verify" or "run"
 
-	// Future: Protocol-specific configurations at test level
+	// Protocol-specific configurations at test level
 	// Following tavern-py's approach: if "mqtt" in test_spec, initialize MQTT client
-	// MQTT map[string]interface{} `yaml:"mqtt,omitempty" json:"mqtt,omitempty"`
+	MQTT *MQTTSpec `yaml:"mqtt,omitempty" json:"mqtt,omitempty"`
 }
 
 // Include represents an include block with variables
@@ -45,13 +46,26 @@ type Stage struct {
 	Request  *RequestSpec  `yaml:"request,omitempty" json:"request,omitempty"`
 	Response *ResponseSpec `yaml:"response,omitempty" json:"response,omitempty"`
 
-	// Future: MQTT protocol fields
-	// MQTTPublish  *MQTTPublishSpec  `yaml:"mqtt_publish,omitempty" json:"mqtt_publish,omitempty"`
-	// MQTTResponse *MQTTResponseSpec `yaml:"mqtt_response,omitempty" json:"mqtt_response,omitempty"`
+	// gRPC protocol fields
+	GRPCRequest  *GRPCRequestSpec  `yaml:"grpc_request,omitempty" json:"grpc_request,omitempty"`
+	GRPCResponse *GRPCResponseSpec `yaml:"grpc_response,omitempty" json:"grpc_response,omitempty"`
 
-	// Future: Shell/CLI protocol fields
-	// Command        *ShellCommandSpec  `yaml:"command,omitempty" json:"command,omitempty"`
-	// CommandResponse *ShellResponseSpec `yaml:"command_response,omitempty" json:"command_response,omitempty"`
+	// WebSocket protocol fields
+	WebSocket *WebSocketSpec `yaml:"websocket,omitempty" json:"websocket,omitempty"`
+
+	// MQTT protocol fields. mqtt_response can also follow a REST request.
+	MQTTPublish  *MQTTPublishSpec  `yaml:"mqtt_publish,omitempty" json:"mqtt_publish,omitempty"`
+	MQTTResponse *MQTTResponseSpec `yaml:"mqtt_response,omitempty" json:"mqtt_response,omitempty"`
+
+	// Shell/CLI protocol fields
+	Command         *CommandSpec         `yaml:"command,omitempty" json:"command,omitempty"`
+	CommandResponse *CommandResponseSpec `yaml:"command_response,omitempty" json:"command_response,omitempty"`
+
+	// Raw TCP protocol fields
+	TCP *TCPSpec `yaml:"tcp,omitempty" json:"tcp,omitempty"`
+
+	// Redis (RESP) protocol fields
+	Redis *RedisSpec `yaml:"redis,omitempty" json:"redis,omitempty"`
 }
 
 // RequestSpec represents an HTTP request specification
@@ -65,8 +79,17 @@ type RequestSpec struct {
 	Auth    *AuthSpec         `yaml:"auth,omitempty" json:"auth,omitempty"`
 	Files   map[string]string `yaml:"files,omitempty" json:"files,omitempty"`
 	Cookies map[string]string `yaml:"cookies,omitempty" json:"cookies,omitempty"`
-	Verify  *bool             `yaml:"verify,omitempty" json:"verify,omitempty"` // SSL certificate verification, defaults to true
-	Meta    []string          `yaml:"meta,omitempty" json:"meta,omitempty"`     // Meta operations like "clear_session_cookies"
+	Verify  *bool             `yaml:"verify,omitempty" json:"verify,omitempty"`   // SSL certificate verification, defaults to true
+	Meta    []string          `yaml:"meta,omitempty" json:"meta,omitempty"`       // Meta operations like "clear_session_cookies"
+	GraphQL *GraphQLSpec      `yaml:"graphql,omitempty" json:"graphql,omitempty"` // GraphQL operation, sent instead of json/data
+}
+
+// GraphQLSpec is a GraphQL operation. It is sent as a JSON body, or as query
+// parameters when the method is GET.
+type GraphQLSpec struct {
+	Query         string                 `yaml:"query" json:"query"` // Not formatted, since GraphQL uses braces
+	Variables     map[string]interface{} `yaml:"variables,omitempty" json:"variables,omitempty"`
+	OperationName string                 `yaml:"operationName,omitempty" json:"operationName,omitempty"`
 }
 
 // AuthSpec represents authentication configuration
@@ -77,6 +100,14 @@ type AuthSpec struct {
 	Token    string `yaml:"token,omitempty" json:"token,omitempty"`
 }
 
+// TLSSpec represents TLS settings from a global config or environment
+type TLSSpec struct {
+	Verify     *bool  `yaml:"verify,omitempty" json:"verify,omitempty"`           // Verify server certificates, defaults to true
+	CACert     string `yaml:"ca_cert,omitempty" json:"ca_cert,omitempty"`         // PEM file with additional trusted CAs
+	ClientCert string `yaml:"client_cert,omitempty" json:"client_cert,omitempty"` // PEM client certificate for mutual TLS
+	ClientKey  string `yaml:"client_key,omitempty" json:"client_key,omitempty"`   // PEM client key for mutual TLS
+}
+
 // StatusCode represents expected HTTP status code(s) - can be a single int or a list of ints
 // Aligned with tavern-py commit af74465
 type StatusCode struct {
@@ -180,6 +211,43 @@ type ResponseSpec struct {
 	Cookies    []string               `yaml:"cookies,omitempty" json:"cookies,omitempty"` // Expected cookie names
 	Save       *SaveConfig            `yaml:"save,omitempty" json:"save,omitempty"`       // Union type: SaveSpec or ExtSpec
 	Strict     *Strict                `yaml:"strict,omitempty" json:"strict,omitempty"`   // Response key matching strictness for this stage
+	OpenAPI    *bool                  `yaml:"openapi,omitempty" json:"openapi,omitempty"` // Validate against the loaded OpenAPI document (nil: when an operation matches)
+
+	// GraphQL result. Any errors in the response fail the stage unless Errors expects them.
+	Data   interface{}        `yaml:"data,omitempty" json:"data,omitempty"`     // Expected values in data, like body
+	Errors []GraphQLErrorSpec `yaml:"errors,omitempty" json:"errors,omitempty"` // Expected errors, each matching at least one actual error
+
+	// Server-Sent Events, read from a text/event-stream body
+	Events *EventsSpec `yaml:"events,omitempty" json:"events,omitempty"`
+}
+
+// EventsSpec reads a text/event-stream response. Reading stops after count
+// events, at the first event matching until, or when the timeout passes.
+type EventsSpec struct {
+	Count   int             `yaml:"count,omitempty" json:"count,omitempty"`     // Stop after this many events, failing if fewer arrive
+	Until   *ExpectedEvent  `yaml:"until,omitempty" json:"until,omitempty"`     // Stop at the first matching event, failing if none arrives
+	Timeout float64         `yaml:"timeout,omitempty" json:"timeout,omitempty"` // Seconds to read for, defaults to 10
+	Expect  []ExpectedEvent `yaml:"expect,omitempty" json:"expect,omitempty"`   // Expected events, in order from the first one read
+}
+
+// ExpectedEvent matches a server-sent event. A string data must equal the
+// event data; a mapping or list is checked against it as JSON, like a body.
+type ExpectedEvent struct {
+	Event string         `yaml:"event,omitempty" json:"event,omitempty"`
+	ID    *string        `yaml:"id,omitempty" json:"id,omitempty"`
+	Data  interface{}    `yaml:"data,omitempty" json:"data,omitempty"`
+	Save  *EventSaveSpec `yaml:"save,omitempty" json:"save,omitempty"`
+}
+
+// EventSaveSpec saves values from the JSON data of a matched event
+type EventSaveSpec struct {
+	Data map[string]string `yaml:"data,omitempty" json:"data,omitempty"`
+}
+
+// GraphQLErrorSpec is an expected GraphQL error, matched by message and/or extensions.code
+type GraphQLErrorSpec struct {
+	Message string `yaml:"message,omitempty" json:"message,omitempty"`
+	Code    string `yaml:"code,omitempty" json:"code,omitempty"`
 }
 
 // SaveSpec specifies what to save from the response
@@ -187,6 +255,203 @@ type SaveSpec struct {
 	Body                map[string]interface{} `yaml:"body,omitempty" json:"body,omitempty"`
 	Headers             map[string]string      `yaml:"headers,omitempty" json:"headers,omitempty"`
 	RedirectQueryParams map[string]string      `yaml:"redirect_query_params,omitempty" json:"redirect_query_params,omitempty"`
+	Data                map[string]string      `yaml:"data,omitempty" json:"data,omitempty"` // Paths into a GraphQL result's data
+}
+
+// GRPCRequestSpec represents a unary gRPC call. Message types are resolved
+// through server reflection unless descriptor_set or proto_files is given.
+type GRPCRequestSpec struct {
+	Host          string                 `yaml:"host" json:"host"`                                         // host:port
+	Service       string                 `yaml:"service" json:"service"`                                   // Full method name, package.Service/Method
+	Body          map[string]interface{} `yaml:"body,omitempty" json:"body,omitempty"`                     // Request message in protobuf JSON form
+	Metadata      map[string]string      `yaml:"metadata,omitempty" json:"metadata,omitempty"`             // Request metadata
+	Timeout       float64                `yaml:"timeout,omitempty" json:"timeout,omitempty"`               // Deadline in seconds, defaults to 30
+	TLS           *TLSSpec               `yaml:"tls,omitempty" json:"tls,omitempty"`                       // Connect with TLS instead of plaintext
+	DescriptorSet string                 `yaml:"descriptor_set,omitempty" json:"descriptor_set,omitempty"` // FileDescriptorSet file (protoc --include_imports --descriptor_set_out)
+	ProtoFiles    []string               `yaml:"proto_files,omitempty" json:"proto_files,omitempty"`       // .proto files to compile
+	ImportPaths   []string               `yaml:"import_paths,omitempty" json:"import_paths,omitempty"`     // Import paths for proto_files
+}
+
+// GRPCResponseSpec represents the expected outcome of a gRPC call
+type GRPCResponseSpec struct {
+	Status   string                 `yaml:"status,omitempty" json:"status,omitempty"`     // Status code name (OK, NOT_FOUND, ...) or number, defaults to OK
+	Message  *string                `yaml:"message,omitempty" json:"message,omitempty"`   // Expected status message
+	Body     interface{}            `yaml:"body,omitempty" json:"body,omitempty"`         // Expected response message fields
+	Metadata map[string]interface{} `yaml:"metadata,omitempty" json:"metadata,omitempty"` // Expected header or trailer metadata (null checks presence)
+	Strict   *Strict                `yaml:"strict,omitempty" json:"strict,omitempty"`
+	Save     *GRPCSaveSpec          `yaml:"save,omitempty" json:"save,omitempty"`
+}
+
+// GRPCSaveSpec saves values from a gRPC response message and its metadata
+type GRPCSaveSpec struct {
+	Body     map[string]string `yaml:"body,omitempty" json:"body,omitempty"`
+	Metadata map[string]string `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+}
+
+// WebSocketSpec represents a stage on a WebSocket connection. A connection opened
+// with connect stays open for the following stages of the test until close is set.
+type WebSocketSpec struct {
+	Connect *WebSocketConnectSpec      `yaml:"connect,omitempty" json:"connect,omitempty"` // Open a new connection, closing any previous one
+	Send    []WebSocketMessageSpec     `yaml:"send,omitempty" json:"send,omitempty"`       // Frames to send, in order
+	Expect  []WebSocketExpectedMessage `yaml:"expect,omitempty" json:"expect,omitempty"`   // Messages to receive
+	Match   string                     `yaml:"match,omitempty" json:"match,omitempty"`     // ordered (default) or eventually
+	Timeout float64                    `yaml:"timeout,omitempty" json:"timeout,omitempty"` // Seconds to wait for expected messages, defaults to 5
+	Close   bool                       `yaml:"close,omitempty" json:"close,omitempty"`     // Close the connection after this stage
+	Strict  *Strict                    `yaml:"strict,omitempty" json:"strict,omitempty"`
+}
+
+// WebSocketConnectSpec represents the handshake of a WebSocket connection
+type WebSocketConnectSpec struct {
+	URL          string            `yaml:"url" json:"url"`                                       // ws:// or wss:// URL
+	Headers      map[string]string `yaml:"headers,omitempty" json:"headers,omitempty"`           // Handshake headers, sent with the test's cookies
+	Subprotocols []string          `yaml:"subprotocols,omitempty" json:"subprotocols,omitempty"` // Requested subprotocols
+	Timeout      float64           `yaml:"timeout,omitempty" json:"timeout,omitempty"`           // Handshake timeout in seconds, defaults to 30
+}
+
+// WebSocketMessageSpec represents a frame to send, either text or JSON
+type WebSocketMessageSpec struct {
+	Text *string     `yaml:"text,omitempty" json:"text,omitempty"`
+	JSON interface{} `yaml:"json,omitempty" json:"json,omitempty"`
+}
+
+// WebSocketExpectedMessage represents a message to receive. Text must match
+// exactly; JSON is checked like a response body.
+type WebSocketExpectedMessage struct {
+	Text *string            `yaml:"text,omitempty" json:"text,omitempty"`
+	JSON interface{}        `yaml:"json,omitempty" json:"json,omitempty"`
+	Save *WebSocketSaveSpec `yaml:"save,omitempty" json:"save,omitempty"`
+}
+
+// WebSocketSaveSpec saves values from a received JSON message
+type WebSocketSaveSpec struct {
+	JSON map[string]string `yaml:"json,omitempty" json:"json,omitempty"`
+}
+
+// MQTTSpec represents the broker connection of a test, shared by its stages
+type MQTTSpec struct {
+	Broker   string        `yaml:"broker" json:"broker"`                           // tcp://, ssl:// or ws:// URL
+	ClientID string        `yaml:"client_id,omitempty" json:"client_id,omitempty"` // Defaults to a random tavern-... id
+	Auth     *MQTTAuthSpec `yaml:"auth,omitempty" json:"auth,omitempty"`
+	TLS      *TLSSpec      `yaml:"tls,omitempty" json:"tls,omitempty"`
+	Timeout  float64       `yaml:"timeout,omitempty" json:"timeout,omitempty"` // Connect, subscribe and publish timeout in seconds, defaults to 5
+}
+
+// MQTTAuthSpec represents MQTT username/password authentication
+type MQTTAuthSpec struct {
+	Username string `yaml:"username" json:"username"`
+	Password string `yaml:"password,omitempty" json:"password,omitempty"`
+}
+
+// MQTTPublishSpec represents a message to publish, with a text payload or JSON
+type MQTTPublishSpec struct {
+	Topic   string      `yaml:"topic" json:"topic"`
+	Payload *string     `yaml:"payload,omitempty" json:"payload,omitempty"`
+	JSON    interface{} `yaml:"json,omitempty" json:"json,omitempty"`
+	QoS     byte        `yaml:"qos,omitempty" json:"qos,omitempty"`
+	Retain  bool        `yaml:"retain,omitempty" json:"retain,omitempty"`
+}
+
+// MQTTResponseSpec represents a message expected on a topic. The topic is
+// subscribed before the stage publishes or sends its request, and messages
+// that don't match are skipped until the timeout passes.
+type MQTTResponseSpec struct {
+	Topic   string        `yaml:"topic" json:"topic"`                         // Topic filter, wildcards allowed
+	Payload *string       `yaml:"payload,omitempty" json:"payload,omitempty"` // Exact text payload
+	JSON    interface{}   `yaml:"json,omitempty" json:"json,omitempty"`       // Expected JSON payload, checked like a response body
+	Timeout float64       `yaml:"timeout,omitempty" json:"timeout,omitempty"` // Seconds to wait, defaults to 1 (aligned with tavern-py)
+	QoS     byte          `yaml:"qos,omitempty" json:"qos,omitempty"`         // Subscription QoS
+	Strict  *Strict       `yaml:"strict,omitempty" json:"strict,omitempty"`
+	Save    *MQTTSaveSpec `yaml:"save,omitempty" json:"save,omitempty"`
+}
+
+// MQTTSaveSpec saves values from a received JSON payload
+type MQTTSaveSpec struct {
+	JSON map[string]string `yaml:"json,omitempty" json:"json,omitempty"`
+}
+
+// CommandSpec represents a program to run. Argv is executed directly, not
+// through a shell.
+type CommandSpec struct {
+	Argv       []string          `yaml:"argv" json:"argv"`                                   // Program and arguments
+	Stdin      *string           `yaml:"stdin,omitempty" json:"stdin,omitempty"`             // Text written to standard input
+	Dir        string            `yaml:"dir,omitempty" json:"dir,omitempty"`                 // Working directory, defaults to tavern's
+	Env        map[string]string `yaml:"env,omitempty" json:"env,omitempty"`                 // Environment variables to set
+	InheritEnv *bool             `yaml:"inherit_env,omitempty" json:"inherit_env,omitempty"` // Start from tavern's environment, defaults to true
+	Timeout    float64           `yaml:"timeout,omitempty" json:"timeout,omitempty"`         // Seconds before the command is killed, defaults to 30
+}
+
+// CommandResponseSpec represents the expected result of a command
+type CommandResponseSpec struct {
+	ExitCode *StatusCode      `yaml:"exit_code,omitempty" json:"exit_code,omitempty"` // Single code or list, defaults to 0
+	Stdout   *OutputSpec      `yaml:"stdout,omitempty" json:"stdout,omitempty"`
+	Stderr   *OutputSpec      `yaml:"stderr,omitempty" json:"stderr,omitempty"`
+	Strict   *Strict          `yaml:"strict,omitempty" json:"strict,omitempty"`
+	Save     *CommandSaveSpec `yaml:"save,omitempty" json:"save,omitempty"`
+}
+
+// OutputSpec represents assertions on text output: a command's stdout or
+// stderr, or a TCP reply
+type OutputSpec struct {
+	Equals      *string     `yaml:"equals,omitempty" json:"equals,omitempty"`             // Whole output, ignoring surrounding whitespace
+	Contains    *string     `yaml:"contains,omitempty" json:"contains,omitempty"`         // Substring that must be present
+	NotContains *string     `yaml:"not_contains,omitempty" json:"not_contains,omitempty"` // Substring that must be absent
+	Matches     *string     `yaml:"matches,omitempty" json:"matches,omitempty"`           // Regular expression that must match
+	JSON        interface{} `yaml:"json,omitempty" json:"json,omitempty"`                 // Output parsed as JSON, checked like a response body
+}
+
+// CommandSaveSpec saves values from a command's output. Stdout and Stderr
+// take regular expressions, saving the first capture group or the whole match;
+// JSON takes paths into stdout parsed as JSON.
+type CommandSaveSpec struct {
+	Stdout map[string]string `yaml:"stdout,omitempty" json:"stdout,omitempty"`
+	Stderr map[string]string `yaml:"stderr,omitempty" json:"stderr,omitempty"`
+	JSON   map[string]string `yaml:"json,omitempty" json:"json,omitempty"`
+}
+
+// TCPSpec represents an exchange on a new TCP connection: send, then read a
+// reply until a delimiter, a number of bytes, or the timeout. Send, until and
+// the reply use the encoding.
+type TCPSpec struct {
+	Host     string       `yaml:"host" json:"host"`                             // host:port
+	Send     *string      `yaml:"send,omitempty" json:"send,omitempty"`         // Data to send after connecting
+	Encoding string       `yaml:"encoding,omitempty" json:"encoding,omitempty"` // text (default), hex or base64
+	Until    *string      `yaml:"until,omitempty" json:"until,omitempty"`       // Read until this delimiter, which is not part of the reply
+	Bytes    int          `yaml:"bytes,omitempty" json:"bytes,omitempty"`       // Read exactly this many bytes
+	Timeout  float64      `yaml:"timeout,omitempty" json:"timeout,omitempty"`   // Connect and read timeout in seconds, defaults to 5
+	Reply    *OutputSpec  `yaml:"reply,omitempty" json:"reply,omitempty"`
+	Save     *TCPSaveSpec `yaml:"save,omitempty" json:"save,omitempty"`
+}
+
+// TCPSaveSpec saves values from a TCP reply, with regular expressions like
+// CommandSaveSpec.Stdout or paths into the reply parsed as JSON
+type TCPSaveSpec struct {
+	Reply map[string]string `yaml:"reply,omitempty" json:"reply,omitempty"`
+	JSON  map[string]string `yaml:"json,omitempty" json:"json,omitempty"`
+}
+
+// RedisSpec represents a command sent to a Redis-compatible server and the
+// expected reply. Bulk and simple string replies are strings, integer replies
+// are integers and array replies are lists.
+type RedisSpec struct {
+	Host     string         `yaml:"host" json:"host"`                             // host:port
+	Username string         `yaml:"username,omitempty" json:"username,omitempty"` // ACL user, sent with the password
+	Password string         `yaml:"password,omitempty" json:"password,omitempty"` // Sent with AUTH before the command
+	DB       int            `yaml:"db,omitempty" json:"db,omitempty"`             // Database number, sent with SELECT
+	Command  []interface{}  `yaml:"command" json:"command"`                       // Command and arguments, e.g. [GET, "user:{id}"]
+	Timeout  float64        `yaml:"timeout,omitempty" json:"timeout,omitempty"`   // Connect and reply timeout in seconds, defaults to 5
+	Reply    interface{}    `yaml:"reply,omitempty" json:"reply,omitempty"`       // Expected reply; a mapping checks a field/value array such as HGETALL's
+	Null     bool           `yaml:"null,omitempty" json:"null,omitempty"`         // Expect a null reply, e.g. GET of a missing key
+	Error    *string        `yaml:"error,omitempty" json:"error,omitempty"`       // Expect an error reply containing this text
+	JSON     interface{}    `yaml:"json,omitempty" json:"json,omitempty"`         // String reply parsed as JSON, checked like a response body
+	Save     *RedisSaveSpec `yaml:"save,omitempty" json:"save,omitempty"`
+}
+
+// RedisSaveSpec saves values from a Redis reply. Reply takes paths into the
+// reply, where array items are numbered and an empty path saves the whole
+// reply; JSON takes paths into a string reply parsed as JSON.
+type RedisSaveSpec struct {
+	Reply map[string]string `yaml:"reply,omitempty" json:"reply,omitempty"`
+	JSON  map[string]string `yaml:"json,omitempty" json:"json,omitempty"`
 }
 
 // ExtSpec represents an extension function specification
@@ -37,6 +37,14 @@ func (v *Validator) Validate(test *TestSpec) error {
 		}
 	}
 
+	// Only request and mqtt_publish stages wait for MQTT messages. The schema
+	// requires this too, checked first here for a clearer message.
+	for i, stage := range test.Stages {
+		if stage.MQTTResponse != nil && stage.Request == nil && stage.MQTTPublish == nil {
+			return fmt.Errorf("validation failed:\n  - stages[%d].mqtt_response: mqtt_response can only be used with request or mqtt_publish", i)
+		}
+	}
+
 	// Validate strict field at stage level
 	for i, stage := range test.Stages {
 		if stage.Response != nil && stage.Response.Strict != nil {
@@ -44,6 +52,26 @@ func (v *Validator) Validate(test *TestSpec) error {
 				return fmt.Errorf("validation failed:\n  - stages[%d].response.strict: %s", i, err)
 			}
 		}
+		if stage.GRPCResponse != nil && stage.GRPCResponse.Strict != nil {
+			if err := stage.GRPCResponse.Strict.Validate(); err != nil {
+				return fmt.Errorf("validation failed:\n  - stages[%d].grpc_response.strict: %s", i, err)
+			}
+		}
+		if stage.MQTTResponse != nil && stage.MQTTResponse.Strict != nil {
+			if err := stage.MQTTResponse.Strict.Validate(); err != nil {
+				return fmt.Errorf("validation failed:\n  - stages[%d].mqtt_response.strict: %s", i, err)
+			}
+		}
+		if stage.CommandResponse != nil && stage.CommandResponse.Strict != nil {
+			if err := stage.CommandResponse.Strict.Validate(); err != nil {
+				return fmt.Errorf("validation failed:\n  - stages[%d].command_response.strict: %s", i, err)
+			}
+		}
+		if stage.WebSocket != nil && stage.WebSocket.Strict != nil {
+			if err := stage.WebSocket.Strict.Validate(); err != nil {
+				return fmt.Errorf("validation failed:\n  - stages[%d].websocket.strict: %s", i, err)
+			}
+		}
 	}
 
 	// Convert test to JSON for validation
@@ -83,6 +111,22 @@ func (v *Validator) Validate(test *TestSpec) error {
 				return err
 			}
 		}
+		if stage.GRPCRequest != nil && hasApprox(stage.GRPCRequest.Body) {
+			return fmt.Errorf("validation failed:\n  - stages[%d].grpc_request.body: Cannot use '!approx' in request data. !approx is only valid in response.body or mqtt_response.json", i)
+		}
+		if stage.MQTTPublish != nil && hasApprox(stage.MQTTPublish.JSON) {
+			return fmt.Errorf("validation failed:\n  - stages[%d].mqtt_publish.json: Cannot use '!approx' in request data. !approx is only valid in response.body or mqtt_response.json", i)
+		}
+		if stage.Redis != nil && hasApprox(stage.Redis.Command) {
+			return fmt.Errorf("validation failed:\n  - stages[%d].redis.command: Cannot use '!approx' in request data. !approx is only valid in response.body or mqtt_response.json", i)
+		}
+		if stage.WebSocket != nil {
+			for j, msg := range stage.WebSocket.Send {
+				if hasApprox(msg.JSON) {
+					return fmt.Errorf("validation failed:\n  - stages[%d].websocket.send[%d].json: Cannot use '!approx' in request data. !approx is only valid in response.body or mqtt_response.json", i, j)
+				}
+			}
+		}
 	}
 
 	return nil
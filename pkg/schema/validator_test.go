@@ -0,0 +1,65 @@
+package schema
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidator_MQTTResponseStages tests that mqtt_response is rejected on
+// stages that don't wait for MQTT messages
+func TestValidator_MQTTResponseStages(t *testing.T) {
+	validator, err := NewValidator()
+	require.NoError(t, err)
+
+	mqttResponse := &MQTTResponseSpec{Topic: "devices/1/status"}
+	stages := map[string]Stage{
+		"command":   {Command: &CommandSpec{Argv: []string{"true"}}},
+		"tcp":       {TCP: &TCPSpec{Host: "localhost:7"}},
+		"redis":     {Redis: &RedisSpec{Command: []interface{}{"PING"}}},
+		"websocket": {WebSocket: &WebSocketSpec{Close: true}},
+	}
+	for name, stage := range stages {
+		t.Run(name, func(t *testing.T) {
+			stage.Name = name
+			stage.MQTTResponse = mqttResponse
+			err := validator.Validate(&TestSpec{TestName: name, Stages: []Stage{stage}})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "stages[0].mqtt_response: mqtt_response can only be used with request or mqtt_publish")
+		})
+	}
+
+	err = validator.Validate(&TestSpec{TestName: "rest", Stages: []Stage{{
+		Name:         "rest",
+		Request:      &RequestSpec{URL: "http://example.com"},
+		Response:     &ResponseSpec{StatusCode: &StatusCode{Single: 200}},
+		MQTTResponse: mqttResponse,
+	}}})
+	assert.NoError(t, err)
+}
+
+// TestValidator_ResponseDependencies tests that each protocol response needs
+// its request
+func TestValidator_ResponseDependencies(t *testing.T) {
+	validator, err := NewValidator()
+	require.NoError(t, err)
+
+	tcp := &TCPSpec{Host: "localhost:7"}
+	tests := map[string]struct {
+		stage    Stage
+		requires string
+	}{
+		"response":         {Stage{TCP: tcp, Response: &ResponseSpec{StatusCode: &StatusCode{Single: 200}}}, "request"},
+		"grpc_response":    {Stage{TCP: tcp, GRPCResponse: &GRPCResponseSpec{}}, "grpc_request"},
+		"command_response": {Stage{TCP: tcp, CommandResponse: &CommandResponseSpec{}}, "command"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.stage.Name = name
+			err := validator.Validate(&TestSpec{TestName: name, Stages: []Stage{tt.stage}})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "stages.0: Has a dependency on "+tt.requires)
+		})
+	}
+}
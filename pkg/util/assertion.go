@@ -0,0 +1,82 @@
+package util
+
+// Response blocks an assertion can belong to
+const (
+	BlockStatus   = "status"
+	BlockBody     = "body"
+	BlockHeaders  = "headers"
+	BlockCookies  = "cookies"
+	BlockSave     = "save"
+	BlockData     = "data"      // GraphQL result data
+	BlockErrors   = "errors"    // GraphQL result errors
+	BlockMetadata = "metadata"  // gRPC header and trailer metadata
+	BlockMessages = "messages"  // Received websocket messages
+	BlockEvents   = "events"    // Server-sent events
+	BlockPayload  = "payload"   // MQTT message payload
+	BlockExitCode = "exit_code" // Command exit code
+	BlockStdout   = "stdout"    // Command standard output
+	BlockStderr   = "stderr"    // Command standard error
+	BlockReply    = "reply"     // TCP reply
+)
+
+// Matchers used to compare expected and actual values
+const (
+	MatchEquals      = "equals"       // Plain equality
+	MatchExists      = "exists"       // Key/header/cookie must be present
+	MatchAnyInt      = "anyint"       // !anyint type matcher
+	MatchAnyFloat    = "anyfloat"     // !anyfloat type matcher
+	MatchAnyStr      = "anystr"       // !anystr type matcher
+	MatchAnyBool     = "anybool"      // !anybool type matcher
+	MatchApprox      = "approx"       // !approx numeric matcher
+	MatchType        = "type"         // Value has the wrong JSON type (e.g. object instead of list)
+	MatchLength      = "length"       // List lengths differ
+	MatchStrict      = "strict"       // Extra keys present in strict mode
+	MatchRegex       = "regex"        // Regular expression match
+	MatchContains    = "contains"     // Substring must be present
+	MatchNotContains = "not_contains" // Substring must be absent
+	MatchExtension   = "extension"    // $ext function
+	MatchFormat      = "format"       // Expected value could not be formatted with variables
+	MatchOpenAPI     = "openapi"      // Response breaks its OpenAPI contract
+)
+
+// AssertionFailure describes a single failed assertion on a response
+// Reporters can use the structured fields to group failures or build diffs
+type AssertionFailure struct {
+	Block    string      `json:"block"`              // Response part: status, body, headers, cookies, save, ...
+	Path     string      `json:"path,omitempty"`     // JSON path, header or cookie name within the block
+	Expected interface{} `json:"expected,omitempty"` // Expected value (may be a matcher marker such as <<INT>>)
+	Actual   interface{} `json:"actual,omitempty"`   // Actual value received, nil if missing
+	Matcher  string      `json:"matcher,omitempty"`  // Matcher that failed (see Match* constants)
+	Message  string      `json:"message"`            // Human readable description
+}
+
+// String returns the human readable failure message
+func (f AssertionFailure) String() string {
+	return f.Message
+}
+
+// FullPath returns the block-qualified path, e.g. "body.user.name"
+func (f AssertionFailure) FullPath() string {
+	switch {
+	case f.Path == "":
+		return f.Block
+	case f.Path[0] == '[':
+		return f.Block + f.Path
+	default:
+		return f.Block + "." + f.Path
+	}
+}
+
+// SplitBlockPath splits a block-qualified path such as "body.items[0].id" into
+// its block ("body") and the path within the block ("items[0].id")
+func SplitBlockPath(fullPath string) (block string, path string) {
+	for i, c := range fullPath {
+		switch c {
+		case '.':
+			return fullPath[:i], fullPath[i+1:]
+		case '[':
+			return fullPath[:i], fullPath[i:]
+		}
+	}
+	return fullPath, ""
+}
@@ -0,0 +1,48 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSplitBlockPath tests splitting block-qualified paths
+func TestSplitBlockPath(t *testing.T) {
+	tests := []struct {
+		fullPath string
+		block    string
+		path     string
+	}{
+		{"body.user.name", "body", "user.name"},
+		{"body[0].id", "body", "[0].id"},
+		{"body", "body", ""},
+		{"headers.Content-Type", "headers", "Content-Type"},
+	}
+
+	for _, tt := range tests {
+		block, path := SplitBlockPath(tt.fullPath)
+		assert.Equal(t, tt.block, block, tt.fullPath)
+		assert.Equal(t, tt.path, path, tt.fullPath)
+	}
+}
+
+// TestAssertionFailure_FullPath tests that FullPath reverses SplitBlockPath
+func TestAssertionFailure_FullPath(t *testing.T) {
+	assert.Equal(t, "body.user.name", AssertionFailure{Block: BlockBody, Path: "user.name"}.FullPath())
+	assert.Equal(t, "body[0].id", AssertionFailure{Block: BlockBody, Path: "[0].id"}.FullPath())
+	assert.Equal(t, "status", AssertionFailure{Block: BlockStatus}.FullPath())
+}
+
+// TestNewAssertionFailError tests that error messages are derived from failures
+func TestNewAssertionFailError(t *testing.T) {
+	failures := []AssertionFailure{
+		{Block: BlockStatus, Expected: 200, Actual: 404, Matcher: MatchEquals, Message: "Status code was 404, expected 200"},
+		{Block: BlockHeaders, Path: "X-Id", Matcher: MatchExists, Message: "Header 'X-Id' missing"},
+	}
+
+	err := NewAssertionFailError("test failed", failures)
+
+	assert.Equal(t, failures, err.Failures)
+	assert.Equal(t, []string{"Status code was 404, expected 200", "Header 'X-Id' missing"}, err.Errors)
+	assert.Contains(t, err.Error(), "Status code was 404, expected 200")
+}
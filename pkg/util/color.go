@@ -0,0 +1,58 @@
+package util
+
+import (
+	"os"
+	"regexp"
+	"sync"
+)
+
+// ANSI color codes used for terminal output
+const (
+	ColorRed    = "\033[31m"
+	ColorGreen  = "\033[32m"
+	ColorYellow = "\033[33m"
+	ColorBold   = "\033[1m"
+	colorReset  = "\033[0m"
+)
+
+var (
+	colorOnce    sync.Once
+	colorEnabled bool
+	colorForced  *bool
+	ansiPattern  = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+// SetColor forces colored output on or off (e.g. from a --no-color flag)
+func SetColor(enabled bool) {
+	colorForced = &enabled
+}
+
+// ColorEnabled reports whether output should be colored
+// Colors are used when stderr is a terminal and NO_COLOR (https://no-color.org) is not set
+func ColorEnabled() bool {
+	if colorForced != nil {
+		return *colorForced
+	}
+
+	colorOnce.Do(func() {
+		if _, ok := os.LookupEnv("NO_COLOR"); ok {
+			return
+		}
+		info, err := os.Stderr.Stat()
+		colorEnabled = err == nil && info.Mode()&os.ModeCharDevice != 0
+	})
+	return colorEnabled
+}
+
+// Colorize wraps s in the given color code if colors are enabled
+func Colorize(color, s string) string {
+	if !ColorEnabled() || s == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// StripANSI removes ANSI color codes from s
+func StripANSI(s string) string {
+	return ansiPattern.ReplaceAllString(s, "")
+}
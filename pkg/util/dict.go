@@ -11,6 +11,10 @@ import (
 func FormatKeys(val interface{}, variables map[string]interface{}) (interface{}, error) {
 	switch v := val.(type) {
 	case string:
+		// External values (!file, !env, !cmd) are resolved as-is, without formatting
+		if IsExternalValue(v) {
+			return resolveExternalValue(v)
+		}
 		// Check for type conversion markers
 		formatted, err := formatString(v, variables)
 		if err != nil {
@@ -106,6 +110,13 @@ func getNestedValue(variables map[string]interface{}, path string) (interface{},
 
 // applyTypeConversion applies type conversion if the string has a type marker
 func applyTypeConversion(s string) (interface{}, error) {
+	// Check for !secret marker: register the value for masking and use it as-is
+	if strings.HasPrefix(s, SecretMarker) {
+		value := strings.TrimPrefix(s, SecretMarker)
+		AddSecret(value)
+		return value, nil
+	}
+
 	// Check for !int or !anyint marker
 	if strings.HasPrefix(s, "<<INT>>") {
 		value := strings.TrimPrefix(s, "<<INT>>")
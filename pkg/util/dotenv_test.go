@@ -0,0 +1,65 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseDotEnv tests parsing of .env content
+func TestParseDotEnv(t *testing.T) {
+	content := `
+# comment
+HOST=localhost
+export PORT=8080
+EMPTY=
+SINGLE='literal \n #value'
+DOUBLE="line1\nline2 \"quoted\""
+INLINE=value # trailing comment
+URL=http://example.com/#anchor
+`
+	vars, err := ParseDotEnv(strings.NewReader(content))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"HOST":   "localhost",
+		"PORT":   "8080",
+		"EMPTY":  "",
+		"SINGLE": `literal \n #value`,
+		"DOUBLE": "line1\nline2 \"quoted\"",
+		"INLINE": "value",
+		"URL":    "http://example.com/#anchor",
+	}, vars)
+}
+
+// TestParseDotEnv_Invalid tests that malformed lines report their line number
+func TestParseDotEnv_Invalid(t *testing.T) {
+	_, err := ParseDotEnv(strings.NewReader("A=1\nnot a pair\n"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "line 2")
+
+	_, err = ParseDotEnv(strings.NewReader(`A="unterminated`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unterminated")
+}
+
+// TestParseKeyValue tests parsing of --var assignments
+func TestParseKeyValue(t *testing.T) {
+	key, value, err := ParseKeyValue("host=http://localhost:8080/?a=b")
+	require.NoError(t, err)
+	assert.Equal(t, "host", key)
+	assert.Equal(t, "http://localhost:8080/?a=b", value)
+
+	key, value, err = ParseKeyValue("empty=")
+	require.NoError(t, err)
+	assert.Equal(t, "empty", key)
+	assert.Equal(t, "", value)
+
+	_, _, err = ParseKeyValue("novalue")
+	assert.Error(t, err)
+
+	_, _, err = ParseKeyValue("=value")
+	assert.Error(t, err)
+}
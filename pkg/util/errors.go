@@ -44,7 +44,9 @@ func NewBadSchemaError(message string, cause error) *BadSchemaError {
 // TestFailError represents a test failure
 type TestFailError struct {
 	TavernError
-	Errors []string
+	Errors   []string           // Failure messages, one per failed assertion
+	Failures []AssertionFailure // Structured failures, when the verifier provides them
+	Diff     string             // Optional expected vs actual diff of the mismatching paths
 }
 
 func NewTestFailError(message string, errors []string) *TestFailError {
@@ -56,11 +58,32 @@ func NewTestFailError(message string, errors []string) *TestFailError {
 	}
 }
 
+// NewAssertionFailError creates a TestFailError from structured assertion failures
+func NewAssertionFailError(message string, failures []AssertionFailure) *TestFailError {
+	errors := make([]string, len(failures))
+	for i, f := range failures {
+		errors[i] = f.Message
+	}
+
+	return &TestFailError{
+		TavernError: TavernError{
+			Message: message,
+		},
+		Errors:   errors,
+		Failures: failures,
+	}
+}
+
 func (e *TestFailError) Error() string {
 	if len(e.Errors) == 0 {
-		return e.Message
+		return MaskSecrets(e.Message)
+	}
+	msg := fmt.Sprintf("%s:\n- %s", e.Message, joinErrors(e.Errors))
+	if e.Diff != "" {
+		msg += "\n" + e.Diff
 	}
-	return fmt.Sprintf("%s:\n- %s", e.Message, joinErrors(e.Errors))
+	// Response values in assertion messages may contain secrets
+	return MaskSecrets(msg)
 }
 
 func joinErrors(errors []string) string {
@@ -0,0 +1,198 @@
+package util
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// Markers for values read from external sources (!file, !env and !cmd tags)
+// The payload after the marker is URL query encoded so it never contains {variables}
+const (
+	FileMarker = "<<FILE>>"
+	EnvMarker  = "<<ENV>>"
+	CmdMarker  = "<<CMD>>"
+)
+
+// File formats supported by !file
+const (
+	FileFormatText   = "text"   // Contents as a string (default)
+	FileFormatBase64 = "base64" // Contents base64 encoded
+	FileFormatJSON   = "json"   // Contents parsed as JSON
+	FileFormatYAML   = "yaml"   // Contents parsed as YAML
+)
+
+// CommandTimeout bounds how long a !cmd command may run
+var CommandTimeout = 30 * time.Second
+
+// FileValue returns the marker for a !file value
+func FileValue(path, format string) string {
+	params := url.Values{"path": {path}}
+	if format != "" {
+		params.Set("format", format)
+	}
+	return FileMarker + params.Encode()
+}
+
+// EnvValue returns the marker for an !env value, with an optional default
+func EnvValue(name string, defaultValue *string) string {
+	params := url.Values{"name": {name}}
+	if defaultValue != nil {
+		params.Set("default", *defaultValue)
+	}
+	return EnvMarker + params.Encode()
+}
+
+// CmdValue returns the marker for a !cmd value run in dir
+func CmdValue(command, dir string) string {
+	params := url.Values{"command": {command}}
+	if dir != "" {
+		params.Set("dir", dir)
+	}
+	return CmdMarker + params.Encode()
+}
+
+// IsExternalValue reports whether s is a !file, !env or !cmd marker
+func IsExternalValue(s string) bool {
+	return strings.HasPrefix(s, FileMarker) || strings.HasPrefix(s, EnvMarker) || strings.HasPrefix(s, CmdMarker)
+}
+
+// ResolveExternal recursively resolves !file, !env and !cmd markers without formatting variables
+func ResolveExternal(val interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case string:
+		if !IsExternalValue(v) {
+			return v, nil
+		}
+		return resolveExternalValue(v)
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			resolved, err := ResolveExternal(item)
+			if err != nil {
+				return nil, err
+			}
+			result[k] = resolved
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := ResolveExternal(item)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = resolved
+		}
+		return result, nil
+	default:
+		return val, nil
+	}
+}
+
+// resolveExternalValue reads the value a marker refers to
+func resolveExternalValue(s string) (interface{}, error) {
+	var marker string
+	for _, m := range []string{FileMarker, EnvMarker, CmdMarker} {
+		if strings.HasPrefix(s, m) {
+			marker = m
+			break
+		}
+	}
+
+	params, err := url.ParseQuery(strings.TrimPrefix(s, marker))
+	if err != nil {
+		return nil, fmt.Errorf("invalid external value %q: %w", s, err)
+	}
+
+	switch marker {
+	case FileMarker:
+		return readFileValue(params.Get("path"), params.Get("format"))
+	case EnvMarker:
+		return readEnvValue(params.Get("name"), params)
+	default:
+		return runCommandValue(params.Get("command"), params.Get("dir"))
+	}
+}
+
+// readFileValue reads a file in the given format
+func readFileValue(path, format string) (interface{}, error) {
+	if path == "" {
+		return nil, fmt.Errorf("!file requires a path")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("!file: %w", err)
+	}
+
+	switch format {
+	case "", FileFormatText:
+		return string(data), nil
+	case FileFormatBase64:
+		return base64.StdEncoding.EncodeToString(data), nil
+	case FileFormatJSON:
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("!file %s: invalid JSON: %w", path, err)
+		}
+		return value, nil
+	case FileFormatYAML:
+		var value interface{}
+		if err := goyaml.Unmarshal(data, &value); err != nil {
+			return nil, fmt.Errorf("!file %s: invalid YAML: %w", path, err)
+		}
+		return value, nil
+	default:
+		return nil, fmt.Errorf("!file: unknown format '%s' (expected text, base64, json or yaml)", format)
+	}
+}
+
+// readEnvValue reads an environment variable, falling back to the default if one was given
+func readEnvValue(name string, params url.Values) (interface{}, error) {
+	if name == "" {
+		return nil, fmt.Errorf("!env requires a variable name")
+	}
+
+	if value, ok := os.LookupEnv(name); ok {
+		return value, nil
+	}
+	if params.Has("default") {
+		return params.Get("default"), nil
+	}
+	return nil, fmt.Errorf("!env: environment variable '%s' is not set and has no default", name)
+}
+
+// runCommandValue runs a shell command and returns its stdout without the trailing newline
+func runCommandValue(command, dir string) (interface{}, error) {
+	if command == "" {
+		return nil, fmt.Errorf("!cmd requires a command")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), CommandTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("!cmd %q timed out after %s", command, CommandTimeout)
+		}
+		return nil, fmt.Errorf("!cmd %q failed: %w: %s", command, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
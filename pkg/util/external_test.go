@@ -0,0 +1,80 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestResolveExternal_File tests reading files in each format
+func TestResolveExternal_File(t *testing.T) {
+	tmpDir := t.TempDir()
+	jsonPath := filepath.Join(tmpDir, "data.json")
+	require.NoError(t, os.WriteFile(jsonPath, []byte(`{"id": 1, "tags": ["a"]}`), 0644))
+	yamlPath := filepath.Join(tmpDir, "data.yaml")
+	require.NoError(t, os.WriteFile(yamlPath, []byte("id: 1\nname: {braces}\n"), 0644))
+
+	value, err := ResolveExternal(FileValue(jsonPath, ""))
+	require.NoError(t, err)
+	assert.Equal(t, `{"id": 1, "tags": ["a"]}`, value)
+
+	value, err = ResolveExternal(FileValue(jsonPath, FileFormatJSON))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"id": float64(1), "tags": []interface{}{"a"}}, value)
+
+	value, err = ResolveExternal(FileValue(yamlPath, FileFormatYAML))
+	require.NoError(t, err)
+	assert.Equal(t, 1, value.(map[string]interface{})["id"])
+
+	value, err = ResolveExternal(FileValue(jsonPath, FileFormatBase64))
+	require.NoError(t, err)
+	assert.Equal(t, "eyJpZCI6IDEsICJ0YWdzIjogWyJhIl19", value)
+
+	_, err = ResolveExternal(FileValue(jsonPath, "xml"))
+	assert.ErrorContains(t, err, "unknown format 'xml'")
+
+	_, err = ResolveExternal(FileValue(filepath.Join(tmpDir, "missing"), ""))
+	assert.Error(t, err)
+}
+
+// TestResolveExternal_Env tests environment lookups with and without defaults
+func TestResolveExternal_Env(t *testing.T) {
+	t.Setenv("TAVERN_EXTERNAL_TEST", "set")
+	empty := ""
+
+	value, err := ResolveExternal(EnvValue("TAVERN_EXTERNAL_TEST", nil))
+	require.NoError(t, err)
+	assert.Equal(t, "set", value)
+
+	value, err = ResolveExternal(EnvValue("TAVERN_EXTERNAL_TEST_UNSET", &empty))
+	require.NoError(t, err)
+	assert.Equal(t, "", value)
+
+	_, err = ResolveExternal(EnvValue("TAVERN_EXTERNAL_TEST_UNSET", nil))
+	assert.ErrorContains(t, err, "is not set")
+}
+
+// TestResolveExternal_Cmd tests command output and failures
+func TestResolveExternal_Cmd(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "value.txt"), []byte("in-dir\n"), 0644))
+
+	value, err := ResolveExternal(CmdValue("cat value.txt", dir))
+	require.NoError(t, err)
+	assert.Equal(t, "in-dir", value)
+
+	_, err = ResolveExternal(CmdValue("echo oops >&2; exit 3", dir))
+	assert.ErrorContains(t, err, "oops")
+}
+
+// TestFormatKeys_ExternalValueNotFormatted tests that external values are not formatted with variables
+func TestFormatKeys_ExternalValueNotFormatted(t *testing.T) {
+	value, err := FormatKeys(map[string]interface{}{
+		"cmd": CmdValue("echo '{not_a_variable}'", ""),
+	}, map[string]interface{}{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"cmd": "{not_a_variable}"}, value)
+}
@@ -0,0 +1,36 @@
+package util
+
+import "strings"
+
+// SnakeCase turns a name such as "getUser" or "GET /users/{id}" into a
+// file-name friendly identifier ("get_user", "get_users_id"); acronyms stay
+// together ("Shop API" becomes "shop_api")
+func SnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	prevUnderscore := true
+	for i, r := range runes {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			// camelCase -> camel_case, HTTPServer -> http_server, but API -> api
+			if i > 0 && !prevUnderscore {
+				prev := runes[i-1]
+				nextLower := i+1 < len(runes) && runes[i+1] >= 'a' && runes[i+1] <= 'z'
+				if (prev >= 'a' && prev <= 'z') || (prev >= '0' && prev <= '9') || nextLower {
+					b.WriteRune('_')
+				}
+			}
+			b.WriteRune(r + ('a' - 'A'))
+			prevUnderscore = false
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+			prevUnderscore = false
+		default:
+			if !prevUnderscore {
+				b.WriteRune('_')
+				prevUnderscore = true
+			}
+		}
+	}
+	return strings.Trim(b.String(), "_")
+}
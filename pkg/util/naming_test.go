@@ -0,0 +1,23 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestSnakeCase tests conversion of operation and folder names to identifiers
+func TestSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"getUser":          "get_user",
+		"GET /users/{id}":  "get_users_id",
+		"Shop API":         "shop_api",
+		"HTTPServer":       "http_server",
+		"Orders / Refunds": "orders_refunds",
+		"listV2Items":      "list_v2_items",
+		"  ":               "",
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, SnakeCase(input), input)
+	}
+}
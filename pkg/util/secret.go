@@ -0,0 +1,126 @@
+package util
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SecretMarker prefixes values tagged with !secret in YAML
+const SecretMarker = "<<SECRET>>"
+
+// SecretMask replaces secret values in logs, errors and reports
+const SecretMask = "****"
+
+// SecretEnvSuffix marks environment variables whose values are secret
+const SecretEnvSuffix = "_SECRET"
+
+// minSecretLength avoids masking very short values (e.g. "1") that would mangle all output
+const minSecretLength = 3
+
+// Masker replaces registered secret values with SecretMask
+type Masker struct {
+	mu      sync.RWMutex
+	secrets map[string]struct{}
+	ordered []string // Longest first, so secrets containing other secrets are fully masked
+}
+
+// NewMasker creates an empty Masker
+func NewMasker() *Masker {
+	return &Masker{secrets: make(map[string]struct{})}
+}
+
+// Add registers a secret value
+func (m *Masker) Add(value string) {
+	if len(value) < minSecretLength {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.secrets[value]; ok {
+		return
+	}
+	m.secrets[value] = struct{}{}
+	m.ordered = append(m.ordered, value)
+	sort.SliceStable(m.ordered, func(i, j int) bool {
+		return len(m.ordered[i]) > len(m.ordered[j])
+	})
+}
+
+// Mask replaces every registered secret in s
+func (m *Masker) Mask(s string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, secret := range m.ordered {
+		s = strings.ReplaceAll(s, secret, SecretMask)
+	}
+	return s
+}
+
+// MaskValue masks secrets in all strings of a nested value
+func (m *Masker) MaskValue(val interface{}) interface{} {
+	switch v := val.(type) {
+	case string:
+		return m.Mask(v)
+	case map[string]interface{}:
+		masked := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			masked[k] = m.MaskValue(item)
+		}
+		return masked
+	case []interface{}:
+		masked := make([]interface{}, len(v))
+		for i, item := range v {
+			masked[i] = m.MaskValue(item)
+		}
+		return masked
+	default:
+		return val
+	}
+}
+
+// defaultMasker is shared by the runner, validators and the CLI
+var defaultMasker = NewMasker()
+
+// AddSecret registers a secret value with the default masker
+func AddSecret(value string) {
+	defaultMasker.Add(value)
+}
+
+// MaskSecrets replaces registered secrets in s with SecretMask
+func MaskSecrets(s string) string {
+	return defaultMasker.Mask(s)
+}
+
+// MaskSecretsValue masks secrets in all strings of a nested value
+func MaskSecretsValue(val interface{}) interface{} {
+	return defaultMasker.MaskValue(val)
+}
+
+// MaskingFormatter wraps a logrus formatter and masks secrets in its output
+type MaskingFormatter struct {
+	logrus.Formatter
+}
+
+// Format formats the entry with the wrapped formatter and masks secrets
+func (f *MaskingFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	data, err := f.Formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+	return []byte(MaskSecrets(string(data))), nil
+}
+
+// InstallSecretMasking wraps the logger's formatter so secrets are masked
+// Calling it more than once on the same logger is a no-op
+func InstallSecretMasking(logger *logrus.Logger) {
+	if _, ok := logger.Formatter.(*MaskingFormatter); ok {
+		return
+	}
+	logger.SetFormatter(&MaskingFormatter{Formatter: logger.Formatter})
+}
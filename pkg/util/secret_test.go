@@ -0,0 +1,61 @@
+package util
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMasker_Mask tests that registered secrets are replaced, longest first
+func TestMasker_Mask(t *testing.T) {
+	m := NewMasker()
+	m.Add("token")
+	m.Add("token-extended")
+	m.Add("ab") // Too short to mask
+
+	assert.Equal(t, "Bearer ****", m.Mask("Bearer token-extended"))
+	assert.Equal(t, "**** and ****", m.Mask("token and token-extended"))
+	assert.Equal(t, "ab", m.Mask("ab"))
+}
+
+// TestMasker_MaskValue tests masking of nested values
+func TestMasker_MaskValue(t *testing.T) {
+	m := NewMasker()
+	m.Add("s3cr3t")
+
+	masked := m.MaskValue(map[string]interface{}{
+		"token": "s3cr3t",
+		"list":  []interface{}{"x-s3cr3t", 1},
+	})
+
+	assert.Equal(t, map[string]interface{}{
+		"token": "****",
+		"list":  []interface{}{"x-****", 1},
+	}, masked)
+}
+
+// TestMaskingFormatter tests that log output is masked
+func TestMaskingFormatter(t *testing.T) {
+	AddSecret("logged-secret-value")
+
+	var buf bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&buf)
+	InstallSecretMasking(logger)
+	InstallSecretMasking(logger) // Idempotent
+
+	logger.Warnf("Authorization: Bearer %s", "logged-secret-value")
+
+	assert.Contains(t, buf.String(), "Bearer ****")
+	assert.NotContains(t, buf.String(), "logged-secret-value")
+}
+
+// TestApplyTypeConversion_Secret tests that !secret values are unwrapped and registered
+func TestApplyTypeConversion_Secret(t *testing.T) {
+	result, err := FormatKeys(SecretMarker+"{token}", map[string]interface{}{"token": "formatted-secret"})
+	assert.NoError(t, err)
+	assert.Equal(t, "formatted-secret", result)
+	assert.Equal(t, "****", MaskSecrets("formatted-secret"))
+}
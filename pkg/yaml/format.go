@@ -0,0 +1,388 @@
+package yaml
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// Canonical key orders. Keys not listed keep their relative order after the listed ones.
+var (
+	testKeyOrder     = []string{"test_name", "marks", "_xfail", "strict", "mqtt", "includes", "stages"}
+	includeKeyOrder  = []string{"name", "description", "variables"}
+	stageKeyOrder    = []string{"name", "skip", "only", "delay_before", "delay_after", "request", "response", "grpc_request", "grpc_response", "websocket", "mqtt_publish", "mqtt_response", "command", "command_response", "tcp", "redis"}
+	requestKeyOrder  = []string{"url", "method", "params", "headers", "cookies", "auth", "json", "data", "graphql", "files", "verify", "meta"}
+	responseKeyOrder = []string{"status_code", "headers", "cookies", "body", "data", "errors", "events", "strict", "openapi", "save"}
+
+	eventsKeyOrder        = []string{"count", "until", "timeout", "expect"}
+	expectedEventKeyOrder = []string{"event", "id", "data", "save"}
+
+	grpcRequestKeyOrder  = []string{"host", "service", "metadata", "body", "timeout", "tls", "descriptor_set", "proto_files", "import_paths"}
+	grpcResponseKeyOrder = []string{"status", "message", "metadata", "body", "strict", "save"}
+
+	mqttKeyOrder         = []string{"broker", "client_id", "auth", "tls", "timeout"}
+	mqttPublishKeyOrder  = []string{"topic", "payload", "json", "qos", "retain"}
+	mqttResponseKeyOrder = []string{"topic", "payload", "json", "timeout", "qos", "strict", "save"}
+
+	commandKeyOrder         = []string{"argv", "stdin", "dir", "env", "inherit_env", "timeout"}
+	commandResponseKeyOrder = []string{"exit_code", "stdout", "stderr", "strict", "save"}
+	outputKeyOrder          = []string{"equals", "contains", "not_contains", "matches", "json"}
+
+	tcpKeyOrder = []string{"host", "send", "encoding", "until", "bytes", "timeout", "reply", "save"}
+
+	redisKeyOrder = []string{"host", "username", "password", "db", "command", "timeout", "reply", "null", "error", "json", "save"}
+
+	websocketKeyOrder        = []string{"connect", "send", "match", "timeout", "expect", "strict", "close"}
+	websocketConnectKeyOrder = []string{"url", "headers", "subprotocols", "timeout"}
+	websocketExpectKeyOrder  = []string{"text", "json", "save"}
+)
+
+// Format rewrites test file content into the canonical layout: keys of tests,
+// includes, stages, requests and responses in a fixed order, two-space
+// indentation, and strings quoted (with double quotes) only when needed.
+// It works on the YAML node tree, so comments, anchors and custom tags such as
+// !include are kept. Request and response data (headers, body, ...) is not reordered.
+func Format(data []byte) ([]byte, error) {
+	data, err := quoteEmptyTags(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	decoder := goyaml.NewDecoder(bytes.NewReader(data))
+	for first := true; ; first = false {
+		var node goyaml.Node
+		err := decoder.Decode(&node)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode YAML: %w", err)
+		}
+
+		root := &node
+		if node.Kind == goyaml.DocumentNode && len(node.Content) > 0 {
+			root = node.Content[0]
+		}
+		if MappingKey(root, "test_name") != nil || MappingKey(root, "stages") != nil {
+			formatTest(root)
+		}
+		normalizeQuoting(&node)
+
+		var buf bytes.Buffer
+		encoder := goyaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(&node); err != nil {
+			return nil, fmt.Errorf("failed to encode YAML: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+
+		// The document start marker is required between documents, and kept on
+		// the first one if it had it
+		if !first {
+			out.WriteString("\n---\n")
+		} else if startsWithMarker(data) {
+			out.WriteString("---\n")
+		}
+		out.Write(buf.Bytes())
+	}
+	return separateBlocks(out.Bytes())
+}
+
+// startsWithMarker reports whether the first document has an explicit --- marker
+func startsWithMarker(data []byte) bool {
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		return line == "---" || strings.HasPrefix(line, "--- ")
+	}
+	return false
+}
+
+// quoteEmptyTags writes "" after custom tags that have no value, such as a bare
+// !anything. The YAML parser attaches the comment following such a tag to the
+// next node, which would move comments around.
+func quoteEmptyTags(data []byte) ([]byte, error) {
+	type position struct{ line, column int }
+	var positions []position
+
+	decoder := goyaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node goyaml.Node
+		err := decoder.Decode(&node)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode YAML: %w", err)
+		}
+		walkNodes(&node, func(n *goyaml.Node) {
+			if n.Kind == goyaml.ScalarNode && isCustomTag(n.Tag) && n.Value == "" && n.Style&^goyaml.TaggedStyle == 0 {
+				positions = append(positions, position{n.Line, n.Column})
+			}
+		})
+	}
+	if len(positions) == 0 {
+		return data, nil
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for _, pos := range positions {
+		if pos.line < 1 || pos.line > len(lines) {
+			continue
+		}
+		line := lines[pos.line-1]
+		start := len(line)
+		if runes := []rune(line); pos.column-1 <= len(runes) {
+			start = len(string(runes[:pos.column-1]))
+		}
+		end := start
+		for end < len(line) && line[end] != ' ' && line[end] != '\t' {
+			end++
+		}
+		if start < end && line[start] == '!' {
+			lines[pos.line-1] = line[:end] + ` ""` + line[end:]
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// isCustomTag reports whether tag is a local tag such as !include, rather than
+// a core tag such as !!str
+func isCustomTag(tag string) bool {
+	return strings.HasPrefix(tag, "!") && !strings.HasPrefix(tag, "!!")
+}
+
+// separateBlocks inserts a blank line before each top-level key holding a
+// block (except the first key) and between stages, above any head comments
+func separateBlocks(data []byte) ([]byte, error) {
+	blankBefore := map[int]bool{}
+	decoder := goyaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var node goyaml.Node
+		err := decoder.Decode(&node)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode formatted YAML: %w", err)
+		}
+		if len(node.Content) == 0 || node.Content[0].Kind != goyaml.MappingNode {
+			continue
+		}
+		root := node.Content[0]
+		for i := 2; i+1 < len(root.Content); i += 2 {
+			if kind := root.Content[i+1].Kind; kind == goyaml.MappingNode || kind == goyaml.SequenceNode {
+				blankBefore[root.Content[i].Line] = true
+			}
+		}
+		for i, stage := range sequenceItems(MappingValue(root, "stages")) {
+			if i > 0 {
+				blankBefore[stage.Line] = true
+			}
+		}
+	}
+
+	lines := strings.Split(string(data), "\n")
+	var out []string
+	for i, line := range lines {
+		out = append(out, line)
+		// Look past head comments, so the blank line goes above them
+		next := i + 1
+		for next < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[next]), "#") {
+			next++
+		}
+		trimmed := strings.TrimSpace(line)
+		if next < len(lines) && blankBefore[next+1] && trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			out = append(out, "")
+		}
+	}
+	return []byte(strings.Join(out, "\n")), nil
+}
+
+// formatTest orders the keys of a test document and its nested blocks
+func formatTest(test *goyaml.Node) {
+	orderKeys(test, testKeyOrder)
+	orderKeys(MappingValue(test, "mqtt"), mqttKeyOrder)
+	for _, include := range sequenceItems(MappingValue(test, "includes")) {
+		orderKeys(include, includeKeyOrder)
+	}
+	for _, stage := range sequenceItems(MappingValue(test, "stages")) {
+		orderKeys(stage, stageKeyOrder)
+		orderKeys(MappingValue(stage, "request"), requestKeyOrder)
+		orderKeys(MappingValue(stage, "response"), responseKeyOrder)
+
+		events := MappingValue(MappingValue(stage, "response"), "events")
+		orderKeys(events, eventsKeyOrder)
+		orderKeys(MappingValue(events, "until"), expectedEventKeyOrder)
+		for _, event := range sequenceItems(MappingValue(events, "expect")) {
+			orderKeys(event, expectedEventKeyOrder)
+		}
+
+		orderKeys(MappingValue(stage, "grpc_request"), grpcRequestKeyOrder)
+		orderKeys(MappingValue(stage, "grpc_response"), grpcResponseKeyOrder)
+
+		orderKeys(MappingValue(stage, "mqtt_publish"), mqttPublishKeyOrder)
+		orderKeys(MappingValue(stage, "mqtt_response"), mqttResponseKeyOrder)
+
+		orderKeys(MappingValue(stage, "command"), commandKeyOrder)
+		commandResp := MappingValue(stage, "command_response")
+		orderKeys(commandResp, commandResponseKeyOrder)
+		orderKeys(MappingValue(commandResp, "stdout"), outputKeyOrder)
+		orderKeys(MappingValue(commandResp, "stderr"), outputKeyOrder)
+
+		tcp := MappingValue(stage, "tcp")
+		orderKeys(tcp, tcpKeyOrder)
+		orderKeys(MappingValue(tcp, "reply"), outputKeyOrder)
+
+		orderKeys(MappingValue(stage, "redis"), redisKeyOrder)
+
+		websocket := MappingValue(stage, "websocket")
+		orderKeys(websocket, websocketKeyOrder)
+		orderKeys(MappingValue(websocket, "connect"), websocketConnectKeyOrder)
+		for _, message := range sequenceItems(MappingValue(websocket, "expect")) {
+			orderKeys(message, websocketExpectKeyOrder)
+		}
+	}
+}
+
+// sequenceItems returns the items of a sequence node, or nil for other nodes
+func sequenceItems(node *goyaml.Node) []*goyaml.Node {
+	if node == nil || node.Kind != goyaml.SequenceNode {
+		return nil
+	}
+	return node.Content
+}
+
+// orderKeys reorders the pairs of a mapping node: merge keys (<<) first, then
+// the keys in order, then the rest. The mapping is left alone if the new order
+// would put an alias before the anchor it refers to.
+func orderKeys(node *goyaml.Node, order []string) {
+	if node == nil || node.Kind != goyaml.MappingNode {
+		return
+	}
+
+	rank := func(key string) int {
+		if key == "<<" {
+			return -1
+		}
+		for i, k := range order {
+			if k == key {
+				return i
+			}
+		}
+		return len(order)
+	}
+
+	type pair struct{ key, value *goyaml.Node }
+	pairs := make([]pair, 0, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		pairs = append(pairs, pair{node.Content[i], node.Content[i+1]})
+	}
+
+	sorted := make([]pair, len(pairs))
+	copy(sorted, pairs)
+	// Stable insertion sort keeps unknown and duplicate keys in their original order
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && rank(sorted[j].key.Value) < rank(sorted[j-1].key.Value); j-- {
+			sorted[j], sorted[j-1] = sorted[j-1], sorted[j]
+		}
+	}
+
+	local := map[string]bool{}
+	for _, p := range pairs {
+		for _, anchor := range anchorsIn(p.value) {
+			local[anchor] = true
+		}
+	}
+	defined := map[string]bool{}
+	for _, p := range sorted {
+		own := map[string]bool{}
+		for _, anchor := range anchorsIn(p.value) {
+			own[anchor] = true
+		}
+		for _, alias := range aliasesIn(p.value) {
+			if local[alias] && !defined[alias] && !own[alias] {
+				return
+			}
+		}
+		for anchor := range own {
+			defined[anchor] = true
+		}
+	}
+
+	content := make([]*goyaml.Node, 0, len(node.Content))
+	for _, p := range sorted {
+		content = append(content, p.key, p.value)
+	}
+	node.Content = content
+}
+
+// anchorsIn returns the anchors defined in a subtree
+func anchorsIn(node *goyaml.Node) []string {
+	var anchors []string
+	walkNodes(node, func(n *goyaml.Node) {
+		if n.Anchor != "" {
+			anchors = append(anchors, n.Anchor)
+		}
+	})
+	return anchors
+}
+
+// aliasesIn returns the anchors referred to by aliases in a subtree
+func aliasesIn(node *goyaml.Node) []string {
+	var aliases []string
+	walkNodes(node, func(n *goyaml.Node) {
+		if n.Kind == goyaml.AliasNode {
+			aliases = append(aliases, n.Value)
+		}
+	})
+	return aliases
+}
+
+// walkNodes calls fn for every node of a subtree, without following aliases
+func walkNodes(node *goyaml.Node, fn func(*goyaml.Node)) {
+	if node == nil {
+		return
+	}
+	fn(node)
+	for _, child := range node.Content {
+		walkNodes(child, fn)
+	}
+}
+
+// normalizeQuoting makes quoted strings plain where YAML allows it, and double
+// quoted otherwise. Literal and folded blocks are kept.
+func normalizeQuoting(node *goyaml.Node) {
+	walkNodes(node, func(n *goyaml.Node) {
+		if n.Kind != goyaml.ScalarNode || n.Style&(goyaml.SingleQuotedStyle|goyaml.DoubleQuotedStyle) == 0 {
+			return
+		}
+		// Only strings and custom tags, whose values are read as-is by the loader
+		if n.Tag != "!!str" && !isCustomTag(n.Tag) {
+			return
+		}
+		n.Style &^= goyaml.SingleQuotedStyle | goyaml.DoubleQuotedStyle
+		if needsQuoting(n) && !(isCustomTag(n.Tag) && n.Value == "") {
+			n.Style |= goyaml.DoubleQuotedStyle
+		}
+	})
+}
+
+// needsQuoting reports whether a string scalar can't be written plain
+func needsQuoting(n *goyaml.Node) bool {
+	plain := &goyaml.Node{Kind: goyaml.ScalarNode, Tag: "!!str", Value: n.Value}
+	out, err := goyaml.Marshal(plain)
+	if err != nil {
+		return true
+	}
+	trimmed := strings.TrimSpace(string(out))
+	return strings.HasPrefix(trimmed, `"`) || strings.HasPrefix(trimmed, `'`) || strings.Contains(n.Value, "\n")
+}
@@ -0,0 +1,151 @@
+package yaml
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestFormat_Canonical tests key order, quoting, indentation and blank lines
+func TestFormat_Canonical(t *testing.T) {
+	input := `---
+# Login flow
+test_name: Format
+
+stages:
+    - response:
+          status_code: 200
+          body:
+              id: !anyint        # Generated by the server
+              name: 'alice'
+              zip: "01234"
+      request:
+          method: POST
+          url: '{base_url}/users'   # Base URL from the global config
+          json:
+              name: "alice"
+              tags: ['a', "b"]
+      name: create user
+    - name: get user
+      request:
+          url: "{base_url}/users/1"
+`
+	expected := `---
+# Login flow
+test_name: Format
+
+stages:
+  - name: create user
+    request:
+      url: "{base_url}/users" # Base URL from the global config
+      method: POST
+      json:
+        name: alice
+        tags: [a, b]
+    response:
+      status_code: 200
+      body:
+        id: !anyint # Generated by the server
+        name: alice
+        zip: "01234"
+
+  - name: get user
+    request:
+      url: "{base_url}/users/1"
+`
+	formatted, err := Format([]byte(input))
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(formatted))
+
+	again, err := Format(formatted)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(again), "formatting is idempotent")
+}
+
+// TestFormat_PreservesTagsAnchorsAndDocuments tests that custom tags, anchors and
+// multiple documents survive formatting and load to the same tests
+func TestFormat_PreservesTagsAnchorsAndDocuments(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common.yaml"), []byte("name: common\ndescription: Common\nvariables:\n  host: http://localhost\n"), 0644))
+
+	input := `test_name: First
+includes:
+  - !include common.yaml
+stages:
+  - &login
+    response:
+      status_code: 200
+      save:
+        body:
+          token: token
+    request:
+      url: "{host}/login"
+      json:
+        id: !int "{user_id}"
+        score: !approx 0.5
+---
+test_name: Second
+includes:
+  - !include common.yaml
+stages:
+  - *login
+  - name: check
+    request:
+      url: "{host}/check"
+    response:
+      body:
+        anything: !anything
+        text: !anystr
+`
+	formatted, err := Format([]byte(input))
+	require.NoError(t, err)
+	out := string(formatted)
+
+	assert.Contains(t, out, "  - !include common.yaml\n")
+	assert.Contains(t, out, "  - &login\n    request:\n")
+	assert.Contains(t, out, "  - *login\n\n  - name: check\n")
+	assert.Contains(t, out, `id: !int "{user_id}"`)
+	assert.Contains(t, out, "score: !approx 0.5\n")
+	assert.Contains(t, out, "anything: !anything\n")
+	assert.Contains(t, out, "\n---\ntest_name: Second\n")
+	assert.NotContains(t, out, "---\ntest_name: First", "no start marker added to the first document")
+
+	original := filepath.Join(dir, "original.tavern.yaml")
+	reformatted := filepath.Join(dir, "formatted.tavern.yaml")
+	require.NoError(t, os.WriteFile(original, []byte(input), 0644))
+	require.NoError(t, os.WriteFile(reformatted, formatted, 0644))
+
+	want, err := NewLoader(dir).Load(original)
+	require.NoError(t, err)
+	got, err := NewLoader(dir).Load(reformatted)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+// TestFormat_KeepsAliasOrder tests that keys are not reordered when that would
+// put an alias before its anchor
+func TestFormat_KeepsAliasOrder(t *testing.T) {
+	input := `test_name: Alias order
+
+stages:
+  - name: echo
+    response:
+      body: &payload
+        id: 1
+    request:
+      url: http://localhost/echo
+      json: *payload
+`
+	formatted, err := Format([]byte(input))
+	require.NoError(t, err)
+	assert.Equal(t, input, string(formatted))
+}
+
+// TestFormat_InvalidYAML tests that syntax errors are reported
+func TestFormat_InvalidYAML(t *testing.T) {
+	_, err := Format([]byte("test_name: [unclosed\n"))
+	assert.Error(t, err)
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/systemquest/tavern-go/pkg/schema"
+	"github.com/systemquest/tavern-go/pkg/util"
 	goyaml "gopkg.in/yaml.v3"
 )
 
@@ -85,7 +86,11 @@ func (l *Loader) processCustomTags(node *goyaml.Node) {
 		// The included file should have a document node at the root
 		if includedNode.Kind == goyaml.DocumentNode && len(includedNode.Content) > 0 {
 			// Copy the first content node (the actual data)
+			// Included nodes report the position of the !include tag, so
+			// diagnostics point into the file being loaded
+			line, column := node.Line, node.Column
 			*node = *includedNode.Content[0]
+			setPosition(node, line, column)
 		}
 		return
 	}
@@ -148,12 +153,171 @@ func (l *Loader) processCustomTags(node *goyaml.Node) {
 		return
 	}
 
+	// Check for !file, !env and !cmd tags - values from external sources,
+	// resolved lazily when the stage runs
+	if node.Tag == "!file" || node.Tag == "!env" || node.Tag == "!cmd" {
+		l.processExternalTag(node)
+		return
+	}
+
+	// Check for !secret tag - the value is masked in logs, errors and reports
+	if node.Tag == "!secret" {
+		// !secret "{tavern.env_vars.TOKEN}" -> <<SECRET>>{tavern.env_vars.TOKEN}
+		node.Tag = "!!str"
+		node.Value = util.SecretMarker + node.Value
+		node.Kind = goyaml.ScalarNode
+		return
+	}
+
 	// Recursively process child nodes
 	for _, child := range node.Content {
 		l.processCustomTags(child)
 	}
 }
 
+// processExternalTag replaces a !file, !env or !cmd node with a marker string
+// !file and !env accept a scalar or a mapping:
+//
+//	!file fixtures/body.json
+//	!file {path: fixtures/image.png, format: base64}
+//	!env API_HOST
+//	!env {name: API_HOST, default: localhost}
+//	!cmd "vault read -field=token secret/api"
+//
+// File paths are made absolute relative to the file being loaded, and commands
+// run in that file's directory
+func (l *Loader) processExternalTag(node *goyaml.Node) {
+	fields := map[string]string{}
+	hasDefault := false
+	if node.Kind == goyaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i].Value
+			fields[key] = node.Content[i+1].Value
+			if key == "default" {
+				hasDefault = true
+			}
+		}
+	}
+
+	var value string
+	switch node.Tag {
+	case "!file":
+		path := node.Value
+		if node.Kind == goyaml.MappingNode {
+			path = fields["path"]
+		}
+		if path != "" {
+			path = l.resolvePath(path)
+		}
+		value = util.FileValue(path, fields["format"])
+	case "!env":
+		name := node.Value
+		if node.Kind == goyaml.MappingNode {
+			name = fields["name"]
+		}
+		var defaultValue *string
+		if hasDefault {
+			d := fields["default"]
+			defaultValue = &d
+		}
+		value = util.EnvValue(name, defaultValue)
+	case "!cmd":
+		value = util.CmdValue(node.Value, l.resolvePath("."))
+	}
+
+	node.Tag = "!!str"
+	node.Kind = goyaml.ScalarNode
+	node.Value = value
+	node.Content = nil
+	node.Style = 0
+}
+
+// resolvePath makes a path absolute relative to the loader's base directory
+func (l *Loader) resolvePath(path string) string {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(l.baseDir, path)
+	}
+	if absPath, err := filepath.Abs(path); err == nil {
+		return absPath
+	}
+	return path
+}
+
+// setPosition recursively sets the line and column of a node tree
+func setPosition(node *goyaml.Node, line, column int) {
+	node.Line, node.Column = line, column
+	for _, child := range node.Content {
+		setPosition(child, line, column)
+	}
+}
+
+// Document is a single YAML document of a test file, with its decoded test spec
+type Document struct {
+	Filename string
+	Node     *goyaml.Node // Root mapping of the document, after custom tag processing
+	Test     *schema.TestSpec
+	Err      error // Decode error if the document doesn't match the test spec (Test is nil)
+}
+
+// LoadDocuments loads a test file like Load, but also returns the processed YAML
+// nodes so callers (such as the linter) can report line and column positions
+func (l *Loader) LoadDocuments(filename string) ([]*Document, error) {
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+
+	data, err := os.ReadFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return l.ParseDocuments(filename, data)
+}
+
+// ParseDocuments is LoadDocuments for content that may not be saved yet, such
+// as an editor buffer. Includes are resolved relative to filename.
+func (l *Loader) ParseDocuments(filename string, data []byte) ([]*Document, error) {
+	absPath, err := filepath.Abs(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	l.baseDir = filepath.Dir(absPath)
+
+	var documents []*Document
+	decoder := goyaml.NewDecoder(strings.NewReader(string(data)))
+	for {
+		var node goyaml.Node
+		err := decoder.Decode(&node)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode YAML: %w", err)
+		}
+
+		l.processCustomTags(&node)
+
+		root := &node
+		if node.Kind == goyaml.DocumentNode && len(node.Content) > 0 {
+			root = node.Content[0]
+		}
+		doc := &Document{Filename: filename, Node: root}
+
+		var test schema.TestSpec
+		if err := node.Decode(&test); err != nil {
+			doc.Err = fmt.Errorf("failed to decode test spec: %w", err)
+		} else if test.TestName == "" {
+			continue
+		} else {
+			doc.Test = &test
+		}
+		documents = append(documents, doc)
+	}
+
+	return documents, nil
+}
+
 // parseYAML parses YAML content into test specifications
 func (l *Loader) parseYAML(data string, filename string) ([]*schema.TestSpec, error) {
 	var tests []*schema.TestSpec
@@ -208,9 +372,22 @@ func (l *Loader) LoadGlobalConfig(filename string) (map[string]interface{}, erro
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	var node goyaml.Node
+	if err := goyaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	// Custom tags such as !secret and !file are supported in global configs too,
+	// with relative paths resolved against the config file's directory
+	prevBaseDir := l.baseDir
+	if absPath, err := filepath.Abs(filename); err == nil {
+		l.baseDir = filepath.Dir(absPath)
+	}
+	l.processCustomTags(&node)
+	l.baseDir = prevBaseDir
+
 	var config map[string]interface{}
-	err = goyaml.Unmarshal(data, &config)
-	if err != nil {
+	if err := node.Decode(&config); err != nil {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
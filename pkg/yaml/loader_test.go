@@ -3,10 +3,12 @@ package yaml
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/systemquest/tavern-go/pkg/util"
 )
 
 func TestLoader_AnythingTag(t *testing.T) {
@@ -355,3 +357,87 @@ stages:
 	require.True(t, hasZero, "zero should exist")
 	assert.Equal(t, "<<BOOL>>0", zero, "!bool \"0\" should be converted to <<BOOL>>0 marker")
 }
+
+// TestLoader_ExternalValueTags tests that !file, !env and !cmd become lazily resolved markers
+func TestLoader_ExternalValueTags(t *testing.T) {
+	tmpDir := t.TempDir()
+	testFile := filepath.Join(tmpDir, "test_external.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "fixtures"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "fixtures", "token.txt"), []byte("file-token"), 0644))
+
+	content := `---
+test_name: Test external value tags
+stages:
+  - name: Test stage
+    request:
+      url: http://example.com
+      headers:
+        X-File: !file fixtures/token.txt
+        X-Base64: !file {path: fixtures/token.txt, format: base64}
+        X-Env: !env {name: TAVERN_LOADER_TEST_UNSET, default: fallback}
+        X-Cmd: !cmd "echo from-cmd"
+    response:
+      status_code: 200
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(content), 0644))
+
+	loader := NewLoader(tmpDir)
+	tests, err := loader.Load(testFile)
+	require.NoError(t, err)
+	require.Len(t, tests, 1)
+
+	headers := tests[0].Stages[0].Request.Headers
+	assert.True(t, strings.HasPrefix(headers["X-File"], util.FileMarker))
+	assert.True(t, strings.HasPrefix(headers["X-Env"], util.EnvMarker))
+	assert.True(t, strings.HasPrefix(headers["X-Cmd"], util.CmdMarker))
+
+	// Values are only read when resolved
+	resolved := map[string]interface{}{}
+	for k, v := range headers {
+		value, err := util.ResolveExternal(v)
+		require.NoError(t, err)
+		resolved[k] = value
+	}
+	assert.Equal(t, "file-token", resolved["X-File"])
+	assert.Equal(t, "ZmlsZS10b2tlbg==", resolved["X-Base64"])
+	assert.Equal(t, "fallback", resolved["X-Env"])
+	assert.Equal(t, "from-cmd", resolved["X-Cmd"])
+}
+
+// TestLoader_LoadDocuments tests that documents keep their nodes and included content
+// reports the position of the !include tag
+func TestLoader_LoadDocuments(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "common.yaml"), []byte("name: common\ndescription: Common\nvariables:\n  host: http://example.com\n"), 0644))
+
+	testFile := filepath.Join(tmpDir, "test_docs.yaml")
+	content := `---
+test_name: First
+includes:
+  - !include common.yaml
+stages:
+  - name: s
+    request:
+      url: "{host}"
+    response:
+      status_code: 200
+---
+test_name: Second
+stages: []
+`
+	require.NoError(t, os.WriteFile(testFile, []byte(content), 0644))
+
+	loader := NewLoader(tmpDir)
+	docs, err := loader.LoadDocuments(testFile)
+	require.NoError(t, err)
+	require.Len(t, docs, 2)
+
+	assert.Equal(t, "First", docs[0].Test.TestName)
+	assert.Equal(t, "http://example.com", docs[0].Test.Includes[0].Variables["host"])
+	assert.Equal(t, "Second", docs[1].Test.TestName)
+
+	// The included mapping is positioned at the !include tag
+	includes := docs[0].Node.Content[3]
+	assert.Equal(t, 4, includes.Content[0].Line)
+	assert.Equal(t, 4, includes.Content[0].Content[1].Line)
+}
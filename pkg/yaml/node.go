@@ -0,0 +1,33 @@
+package yaml
+
+import (
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// MappingKey returns the key node for key in a mapping node, or nil if the
+// node is not a mapping or has no such key
+func MappingKey(node *goyaml.Node, key string) *goyaml.Node {
+	if node == nil || node.Kind != goyaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i]
+		}
+	}
+	return nil
+}
+
+// MappingValue returns the value node for key in a mapping node, or nil if
+// the node is not a mapping or has no such key
+func MappingValue(node *goyaml.Node, key string) *goyaml.Node {
+	if node == nil || node.Kind != goyaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package yaml
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	goyaml "gopkg.in/yaml.v3"
+)
+
+// TestMappingValue tests key and value lookup on mapping and non-mapping nodes
+func TestMappingValue(t *testing.T) {
+	var doc goyaml.Node
+	require.NoError(t, goyaml.Unmarshal([]byte("name: alice\ntags: [a]\n"), &doc))
+	root := doc.Content[0]
+
+	assert.Equal(t, "alice", MappingValue(root, "name").Value)
+	assert.Equal(t, 2, MappingKey(root, "tags").Line)
+	assert.Nil(t, MappingValue(root, "missing"))
+	assert.Nil(t, MappingKey(MappingValue(root, "tags"), "a"))
+	assert.Nil(t, MappingValue(nil, "name"))
+}